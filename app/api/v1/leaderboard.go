@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+const (
+	DefaultLeaderboardNeighbours = 5
+)
+
+// LeaderboardService exposes all available use cases of leaderboard.
+type LeaderboardService interface {
+	ListLeaderboard(c echo.Context) error
+	GetUserLeaderboard(c echo.Context) error
+}
+
+// UserRank represent for the position of a user on the leaderboard.
+type UserRank = entity.UserRank
+
+// ListLeaderboardRequest represents for request of list the top users of leaderboard.
+type ListLeaderboardRequest struct {
+	Pagination
+}
+
+// ListLeaderboardResponse represents for response list the top users of leaderboard.
+type ListLeaderboardResponse struct {
+	Page  int64       `json:"page"`
+	Size  int64       `json:"size"`
+	Items []*UserRank `json:"ranks"`
+}
+
+// GetUserLeaderboardRequest represents for request of get the leaderboard around a user.
+type GetUserLeaderboardRequest struct {
+	UserID     string `param:"user_id" json:"-" validate:"required"`
+	Neighbours int64  `query:"neighbours" validate:"omitempty,min=1,max=50"`
+}
+
+// GetNeighbours retrieve the requested number of neighbours or the default one.
+func (r *GetUserLeaderboardRequest) GetNeighbours() int64 {
+	if r.Neighbours == 0 {
+		return DefaultLeaderboardNeighbours
+	}
+
+	return r.Neighbours
+}
+
+// GetUserLeaderboardResponse represents for response get the leaderboard around a user.
+type GetUserLeaderboardResponse struct {
+	User  *UserRank   `json:"user"`
+	Items []*UserRank `json:"ranks"`
+}
@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+const (
+	DefaultCandidatesSize = 20
+)
+
+// MatchmakingService exposes all available use cases of matchmaking.
+type MatchmakingService interface {
+	JoinQueue(c echo.Context) error
+	LeaveQueue(c echo.Context) error
+	ListCandidates(c echo.Context) error
+}
+
+// QueuedUser represent for a user waiting in the matchmaking queue.
+type QueuedUser = entity.QueuedUser
+
+// JoinQueueRequest represents for request of join the matchmaking queue.
+type JoinQueueRequest struct {
+	UserID string `json:"userID" validate:"required,max=64"`
+}
+
+// JoinQueueResponse represents for response join queue.
+type JoinQueueResponse = QueuedUser
+
+// LeaveQueueRequest represents for request of leave the matchmaking queue.
+type LeaveQueueRequest struct {
+	UserID string `param:"user_id" json:"-" validate:"required"`
+}
+
+// ListCandidatesRequest represents for request of list the queued opponents of a queued user.
+//
+// Window replaces the configured rating window of the user, it widens over the wait time of the user as well.
+type ListCandidatesRequest struct {
+	UserID string `query:"user_id" validate:"required"`
+	Window int    `query:"window" validate:"omitempty,min=1,max=5000"`
+	Size   int    `query:"size" validate:"omitempty,min=1,max=100"`
+}
+
+// GetSize retrieve the requested number of candidates or the default one.
+func (r *ListCandidatesRequest) GetSize() int {
+	if r.Size == 0 {
+		return DefaultCandidatesSize
+	}
+
+	return r.Size
+}
+
+// ListCandidatesResponse represents for response list the queued opponents of a queued user.
+type ListCandidatesResponse struct {
+	User *QueuedUser `json:"user"`
+	// Window is the rating window of the user after widening over the wait time.
+	Window int           `json:"window"`
+	Items  []*QueuedUser `json:"candidates"`
+}
@@ -0,0 +1,34 @@
+package v1
+
+const (
+	DefaultPageSize = 20
+)
+
+// Pagination represents for the page query of a list request, the page is bounded so its offset never overflows.
+type Pagination struct {
+	Page int64 `query:"page" validate:"omitempty,min=1,max=1000000"`
+	Size int64 `query:"size" validate:"omitempty,min=1,max=100"`
+}
+
+// GetPage retrieve the requested page or the first one.
+func (p *Pagination) GetPage() int64 {
+	if p.Page == 0 {
+		return 1
+	}
+
+	return p.Page
+}
+
+// GetSize retrieve the requested page size or the default one.
+func (p *Pagination) GetSize() int64 {
+	if p.Size == 0 {
+		return DefaultPageSize
+	}
+
+	return p.Size
+}
+
+// GetOffset retrieve the 0-based offset of the requested page.
+func (p *Pagination) GetOffset() int64 {
+	return (p.GetPage() - 1) * p.GetSize()
+}
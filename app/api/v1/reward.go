@@ -1,23 +1,25 @@
 package v1
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+
 	"github.com/labstack/echo/v4"
 
 	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/enum"
 )
 
 // RewardService exposes all available use cases of reward.
 type RewardService interface {
 	CreateReward(c echo.Context) error
+	DeleteReward(c echo.Context) error
+	PreviewBattle(c echo.Context) error
 }
 
 // Reward represent for the user reward.
-type Reward struct {
-	UserID    string `json:"userID"`
-	OldElo    int    `json:"oldElo"`
-	NewElo    int    `json:"newElo"`
-	UpdatedAt int64  `json:"updatedAt"`
-}
+type Reward = entity.Reward
 
 // Rewards represent for list reward of users.
 type Rewards struct {
@@ -25,28 +27,220 @@ type Rewards struct {
 }
 
 // CreateRewardRequest represents for request of create reward for user.
+//
+// The outcome of the battle is given by the placement of every team, otherwise by the result of every team,
+// otherwise by the winner which is a member of the winner team or by draw. Placements of only some teams
+// are rejected rather than ignored.
 type CreateRewardRequest struct {
-	Winner string         `json:"winner" validate:"required"`
-	Teams  []*entity.Team `json:"teams" validate:"required,eq=2"`
+	BattleID string         `param:"battle_id" json:"-" validate:"required"`
+	Winner   string         `json:"winner,omitempty"`
+	Draw     bool           `json:"draw,omitempty"`
+	Teams    []*entity.Team `json:"teams" validate:"required,min=2,max=16,unique=Owner,dive"`
 }
 
-// GetWinnerIndex retrieve index of winner from request
-//
-// 0 means draw
-//
-// 1 means first team wins
+// HasPlacements reports whether every team of the request has a placement.
+func (c *CreateRewardRequest) HasPlacements() bool {
+	for _, team := range c.Teams {
+		if team.Placement <= 0 {
+			return false
+		}
+	}
+
+	return len(c.Teams) > 0
+}
+
+// HasAnyPlacement reports whether some team of the request has a placement.
+func (c *CreateRewardRequest) HasAnyPlacement() bool {
+	return slices.ContainsFunc(c.Teams, func(team *entity.Team) bool {
+		return team.Placement > 0
+	})
+}
+
+// HasResults reports whether some team of the request has a result.
+func (c *CreateRewardRequest) HasResults() bool {
+	return slices.ContainsFunc(c.Teams, func(team *entity.Team) bool {
+		return team.Result != nil
+	})
+}
+
+// ValidateOutcome checks that the outcome of the battle is given and consistent.
+func (c *CreateRewardRequest) ValidateOutcome() error {
+	switch {
+	case c.HasPlacements():
+		return nil
+	case c.HasAnyPlacement():
+		return errors.New("placement of every team is required")
+	case c.HasResults():
+		var wins, ties int
+		for _, team := range c.Teams {
+			if team.Result == nil {
+				return errors.New("result of every team is required")
+			}
+
+			switch *team.Result {
+			case enum.BattleResultWin:
+				wins++
+			case enum.BattleResultTie:
+				ties++
+			}
+		}
+
+		if !(wins == 1 && ties == 0) && ties != len(c.Teams) {
+			return errors.New("results must have exactly one winner or all ties")
+		}
+
+		return nil
+	case c.Draw && c.Winner != "":
+		return errors.New("winner of a draw must be empty")
+	case c.Draw:
+		return nil
+	case c.Winner == "":
+		return errors.New("winner, draw, result or placement of every team is required")
+	case slices.ContainsFunc(c.Teams, c.isWinner):
+		return nil
+	default:
+		return fmt.Errorf("winner %s is not in the battle", c.Winner)
+	}
+}
+
+// GetPlacements retrieve the finishing placement of the teams in the request order, 1 is the first.
 //
-// 2 means second team wins
-func (c *CreateRewardRequest) GetWinnerIndex() int {
-	winnerIndex := 0
-	if c.Winner == c.Teams[0].Owner {
-		winnerIndex = 1
-	} else if c.Winner == c.Teams[1].Owner {
-		winnerIndex = 2
+// Without placements they are derived from the results or the winner: the winner team is placed first and
+// the other teams second, every team is placed first on a draw.
+func (c *CreateRewardRequest) GetPlacements() []int {
+	placements := make([]int, 0, len(c.Teams))
+	if c.HasPlacements() {
+		for _, team := range c.Teams {
+			placements = append(placements, team.Placement)
+		}
+
+		return placements
+	}
+
+	hasResults := c.HasResults()
+	for _, team := range c.Teams {
+		first := c.Draw || c.isWinner(team)
+		if hasResults {
+			first = *team.Result != enum.BattleResultLose
+		}
+
+		if first {
+			placements = append(placements, 1)
+		} else {
+			placements = append(placements, 2)
+		}
+	}
+
+	return placements
+}
+
+// isWinner reports whether the winner of the request is a member of team.
+func (c *CreateRewardRequest) isWinner(team *entity.Team) bool {
+	return slices.Contains(team.GetMembers(), c.Winner)
+}
+
+// GetUserIDs retrieve user ids of the members of all teams in the request order.
+func (c *CreateRewardRequest) GetUserIDs() []string {
+	return getUserIDs(c.Teams)
+}
+
+// HasUniqueMembers reports whether every user is a member of a single team only.
+func (c *CreateRewardRequest) HasUniqueMembers() bool {
+	return hasUniqueMembers(c.Teams)
+}
+
+// GetOpponents retrieve user ids of the members of the other teams than the team at teamIdx.
+func (c *CreateRewardRequest) GetOpponents(teamIdx int) []string {
+	var opponents []string
+	for idx, team := range c.Teams {
+		if idx != teamIdx {
+			opponents = append(opponents, team.GetMembers()...)
+		}
 	}
 
-	return winnerIndex
+	return opponents
+}
+
+// GetResult retrieve the battle result of the team at teamIdx.
+//
+// Teams at the best placement win and the others lose, it is a tie if all teams share the same placement.
+func (c *CreateRewardRequest) GetResult(teamIdx int) enum.BattleResult {
+	placements := c.GetPlacements()
+	best, worst := slices.Min(placements), slices.Max(placements)
+	switch {
+	case best == worst:
+		return enum.BattleResultTie
+	case placements[teamIdx] == best:
+		return enum.BattleResultWin
+	default:
+		return enum.BattleResultLose
+	}
 }
 
 // CreateRewardResponse represents for response create reward.
 type CreateRewardResponse = Rewards
+
+// PreviewBattleRequest represents for request of preview the outcomes of a battle before it starts.
+//
+// The teams are given as in CreateRewardRequest, their results and placements are ignored.
+type PreviewBattleRequest struct {
+	Teams []*entity.Team `json:"teams" validate:"required,min=2,max=16,unique=Owner,dive"`
+}
+
+// GetUserIDs retrieve user ids of the members of all teams in the request order.
+func (c *PreviewBattleRequest) GetUserIDs() []string {
+	return getUserIDs(c.Teams)
+}
+
+// HasUniqueMembers reports whether every user is a member of a single team only.
+func (c *PreviewBattleRequest) HasUniqueMembers() bool {
+	return hasUniqueMembers(c.Teams)
+}
+
+// TeamPreview represents for the expected outcomes of a battle for a team.
+type TeamPreview struct {
+	TeamID         string           `json:"teamID,omitempty"`
+	WinProbability float64          `json:"winProbability"`
+	Members        []*MemberPreview `json:"members"`
+}
+
+// MemberPreview represents for the elo change of a team member for every outcome of a battle,
+// the team wins if it finishes first alone and loses if it finishes last alone.
+type MemberPreview struct {
+	UserID string `json:"userID"`
+	Elo    int    `json:"elo"`
+	Win    int    `json:"win"`
+	Draw   int    `json:"draw"`
+	Lose   int    `json:"lose"`
+}
+
+// PreviewBattleResponse represents for response preview battle.
+type PreviewBattleResponse struct {
+	Teams []*TeamPreview `json:"teams"`
+}
+
+// getUserIDs retrieve user ids of the members of all teams in order.
+func getUserIDs(teams []*entity.Team) []string {
+	var userIDs []string
+	for _, team := range teams {
+		userIDs = append(userIDs, team.GetMembers()...)
+	}
+
+	return userIDs
+}
+
+// hasUniqueMembers reports whether every user is a member of a single team only.
+func hasUniqueMembers(teams []*entity.Team) bool {
+	userIDs := getUserIDs(teams)
+	slices.Sort(userIDs)
+
+	return len(slices.Compact(userIDs)) == len(getUserIDs(teams))
+}
+
+// DeleteRewardRequest represents for request of delete the reward of a battle.
+type DeleteRewardRequest struct {
+	BattleID string `param:"battle_id" json:"-" validate:"required"`
+}
+
+// DeleteRewardResponse represents for response delete reward, it holds the reversal of every reward.
+type DeleteRewardResponse = Rewards
@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+// SeasonService exposes all available use cases of season.
+type SeasonService interface {
+	ListSeasons(c echo.Context) error
+	ListSeasonLeaderboard(c echo.Context) error
+	GetSeasonUserRank(c echo.Context) error
+	RolloverSeason(c echo.Context) error
+}
+
+// Season represent for a ranked season.
+type Season = entity.Season
+
+// ListSeasonsResponse represents for response list the current and the ended seasons.
+type ListSeasonsResponse struct {
+	Current *Season   `json:"current"`
+	Items   []*Season `json:"seasons"`
+}
+
+// ListSeasonLeaderboardRequest represents for request of list the standings of a season.
+type ListSeasonLeaderboardRequest struct {
+	Pagination
+	SeasonID string `param:"season_id" json:"-" validate:"required"`
+}
+
+// ListSeasonLeaderboardResponse represents for response list the standings of a season.
+type ListSeasonLeaderboardResponse struct {
+	Season *Season     `json:"season"`
+	Page   int64       `json:"page"`
+	Size   int64       `json:"size"`
+	Items  []*UserRank `json:"ranks"`
+}
+
+// GetSeasonUserRankRequest represents for request of get the standing of a user in a season.
+type GetSeasonUserRankRequest struct {
+	SeasonID string `param:"season_id" json:"-" validate:"required"`
+	UserID   string `param:"user_id" json:"-" validate:"required"`
+}
+
+// GetSeasonUserRankResponse represents for response get the standing of a user in a season.
+type GetSeasonUserRankResponse struct {
+	Season *Season   `json:"season"`
+	User   *UserRank `json:"user"`
+}
+
+// RolloverSeasonRequest represents for request of end the current season and start a new one.
+type RolloverSeasonRequest struct {
+	SeasonID string `json:"seasonID" validate:"required,max=64"`
+}
+
+// RolloverSeasonResponse represents for response rollover season.
+type RolloverSeasonResponse struct {
+	Ended   *Season `json:"ended"`
+	Current *Season `json:"current"`
+}
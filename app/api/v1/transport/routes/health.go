@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/me0den/example-service/infra/health"
+)
+
+// RegisterHealthRoutes registers the probes of the service: /healthz reports the process is alive without
+// checking its dependencies, /readyz runs the registered checks and fails while a component is down or the
+// service is shutting down.
+func RegisterHealthRoutes(e *echo.Echo, registry *health.Registry) {
+	e.GET("/healthz", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, &health.Report{Status: health.StatusUp})
+	})
+
+	e.GET("/readyz", func(c echo.Context) error {
+		report := registry.Check(c.Request().Context())
+		if report.Status != health.StatusUp {
+			return c.JSON(http.StatusServiceUnavailable, report)
+		}
+
+		return c.JSON(http.StatusOK, report)
+	})
+}
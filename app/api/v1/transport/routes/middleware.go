@@ -0,0 +1,84 @@
+package routes
+
+import (
+	"crypto/subtle"
+	"log/slog"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"github.com/me0den/example-service/infra/logging"
+)
+
+// requestIDMiddleware returns the middleware taking the id of a request from its X-Request-Id header or
+// generating one, the id is sent back in the response and carried by the context of the request, so every
+// line logged with the context has it.
+func requestIDMiddleware() echo.MiddlewareFunc {
+	return middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		RequestIDHandler: func(c echo.Context, requestID string) {
+			req := c.Request()
+			c.SetRequest(req.WithContext(logging.ContextWithRequestID(req.Context(), requestID)))
+		},
+	})
+}
+
+// accessLogMiddleware returns the middleware logging every handled request with logger, the requests failed
+// with a server error are logged at error level.
+func accessLogMiddleware(logger *slog.Logger) echo.MiddlewareFunc {
+	return middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		HandleError:      true,
+		LogMethod:        true,
+		LogURI:           true,
+		LogRoutePath:     true,
+		LogStatus:        true,
+		LogLatency:       true,
+		LogRemoteIP:      true,
+		LogUserAgent:     true,
+		LogContentLength: true,
+		LogResponseSize:  true,
+		LogError:         true,
+		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+			level := slog.LevelInfo
+			if v.Status >= 500 {
+				level = slog.LevelError
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", v.Method),
+				slog.String("uri", v.URI),
+				slog.String("route", v.RoutePath),
+				slog.Int("status", v.Status),
+				slog.Duration("latency", v.Latency),
+				slog.String("remote_ip", v.RemoteIP),
+				slog.String("user_agent", v.UserAgent),
+				slog.String("bytes_in", v.ContentLength),
+				slog.Int64("bytes_out", v.ResponseSize),
+			}
+			if v.Error != nil {
+				attrs = append(attrs, slog.String("error", v.Error.Error()))
+			}
+
+			logger.LogAttrs(c.Request().Context(), level, "request", attrs...)
+			return nil
+		},
+	})
+}
+
+// recoverMiddleware returns the middleware turning a panic of a handler into an internal server error, the panic
+// is logged with its stack with logger.
+func recoverMiddleware(logger *slog.Logger) echo.MiddlewareFunc {
+	return middleware.RecoverWithConfig(middleware.RecoverConfig{
+		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
+			logger.ErrorContext(c.Request().Context(), "handler panicked", "error", err, "stack", string(stack))
+			return err
+		},
+	})
+}
+
+// adminMiddleware returns the middleware authorizing the requests bearing token in their Authorization header,
+// every request is rejected when token is empty.
+func adminMiddleware(token string) echo.MiddlewareFunc {
+	return middleware.KeyAuth(func(key string, _ echo.Context) (bool, error) {
+		return token != "" && subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1, nil
+	})
+}
@@ -8,12 +8,35 @@ import (
 	v1 "github.com/me0den/example-service/app/api/v1"
 )
 
-// RegisterRoutes implement and config routing for http server.
-func RegisterRoutes(e *echo.Echo, rewardService v1.RewardService) {
+// RegisterRoutes implement and config routing for http server, the admin routes require adminToken as bearer token.
+func RegisterRoutes(
+	e *echo.Echo,
+	adminToken string,
+	rewardService v1.RewardService,
+	leaderboardService v1.LeaderboardService,
+	userService v1.UserService,
+	seasonService v1.SeasonService,
+	matchmakingService v1.MatchmakingService,
+) {
 	e.GET("/ping", func(c echo.Context) error {
 		return c.String(http.StatusOK, "pong")
 	})
 
+	admin := adminMiddleware(adminToken)
 	groupV1 := e.Group("/v1")
 	groupV1.POST("/battle/:battle_id/reward", rewardService.CreateReward)
+	groupV1.DELETE("/battle/:battle_id/reward", rewardService.DeleteReward, admin)
+	groupV1.POST("/battle/preview", rewardService.PreviewBattle)
+	groupV1.GET("/leaderboard", leaderboardService.ListLeaderboard)
+	groupV1.GET("/leaderboard/users/:user_id", leaderboardService.GetUserLeaderboard)
+	groupV1.GET("/users/:user_id/elo", userService.GetUserElo)
+	groupV1.POST("/users/elo\\:batchGet", userService.BatchGetUserElos)
+	groupV1.GET("/users/:user_id/history", userService.ListRatingHistory)
+	groupV1.GET("/seasons", seasonService.ListSeasons)
+	groupV1.POST("/seasons/rollover", seasonService.RolloverSeason, admin)
+	groupV1.GET("/seasons/:season_id/leaderboard", seasonService.ListSeasonLeaderboard)
+	groupV1.GET("/seasons/:season_id/users/:user_id", seasonService.GetSeasonUserRank)
+	groupV1.POST("/matchmaking/queue", matchmakingService.JoinQueue)
+	groupV1.DELETE("/matchmaking/queue/:user_id", matchmakingService.LeaveQueue)
+	groupV1.GET("/matchmaking/candidates", matchmakingService.ListCandidates)
 }
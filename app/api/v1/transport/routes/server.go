@@ -5,18 +5,29 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
-	"os"
-	"os/signal"
 	"reflect"
-	"syscall"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 
 	v1 "github.com/me0den/example-service/app/api/v1"
+	"github.com/me0den/example-service/infra/config"
+	"github.com/me0den/example-service/infra/health"
+	"github.com/me0den/example-service/infra/metrics"
+)
+
+const (
+	defaultAddr = ":8080"
+	// DefaultShutdownTimeout bounds the draining of the in-flight requests when none is configured.
+	DefaultShutdownTimeout = 10 * time.Second
 )
 
 // ServerFXModule represents a FX module for http server.
@@ -31,6 +42,22 @@ type Validator struct {
 	Validator *validator.Validate
 }
 
+// NewValidator creates a Validator reporting fields by their json, query or path param name.
+func NewValidator() *Validator {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		for _, tag := range []string{"json", "query", "param"} {
+			name, _, _ := strings.Cut(fld.Tag.Get(tag), ",")
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+		return ""
+	})
+
+	return &Validator{Validator: validate}
+}
+
 // Validate implement custom validate.
 func (v *Validator) Validate(i interface{}) error {
 	if err := v.Validator.Struct(i); err != nil {
@@ -43,6 +70,12 @@ func (v *Validator) Validate(i interface{}) error {
 				errs = append(errs, fmt.Sprintf("%s is required", fieldError.Field()))
 			case "eq":
 				errs = append(errs, fmt.Sprintf("%s must be equals to %s", fieldError.Field(), fieldError.Param()))
+			case "min":
+				errs = append(errs, fmt.Sprintf("%s must be greater than or equals to %s", fieldError.Field(), fieldError.Param()))
+			case "max":
+				errs = append(errs, fmt.Sprintf("%s must be less than or equals to %s", fieldError.Field(), fieldError.Param()))
+			case "unique":
+				errs = append(errs, fmt.Sprintf("%s must be unique", fieldError.Field()))
 			default:
 				errs = append(errs, err.Error())
 			}
@@ -53,37 +86,99 @@ func (v *Validator) Validate(i interface{}) error {
 	return nil
 }
 
-// startHTTPServer create a new instance echo http server.
-func startHTTPServer(rewardService v1.RewardService) {
+// startHTTPServer creates a new instance echo http server and runs it within the application lifecycle.
+func startHTTPServer(
+	lc fx.Lifecycle,
+	shutdowner fx.Shutdowner,
+	cfg *config.Config,
+	logger *slog.Logger,
+	registry *health.Registry,
+	metricsRegistry *prometheus.Registry,
+	httpMetrics *metrics.HTTPMetrics,
+	tp trace.TracerProvider,
+	rewardService v1.RewardService,
+	leaderboardService v1.LeaderboardService,
+	userService v1.UserService,
+	seasonService v1.SeasonService,
+	matchmakingService v1.MatchmakingService,
+) {
 	// Echo instance
 	e := echo.New()
+	e.HideBanner = true
 
 	// Middleware
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
+	e.Use(requestIDMiddleware())
+	e.Use(otelecho.Middleware(cfg.Tracing.ServiceName, otelecho.WithTracerProvider(tp), otelecho.WithSkipper(isProbe)))
+	e.Use(httpMetrics.Middleware())
+	e.Use(accessLogMiddleware(logger))
+	e.Use(recoverMiddleware(logger))
 
-	validate := validator.New()
-	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
-		name := fld.Tag.Get("json")
-		if name == "-" {
-			return ""
-		}
-		return name
-	})
+	e.Validator = NewValidator()
 
-	e.Validator = &Validator{Validator: validate}
+	RegisterHealthRoutes(e, registry)
+	RegisterMetricsRoutes(e, metricsRegistry)
+	RegisterRoutes(e, cfg.HTTPServer.AdminToken, rewardService, leaderboardService, userService, seasonService, matchmakingService)
 
-	RegisterRoutes(e, rewardService)
+	addr := cfg.HTTPServer.Addr
+	if addr == "" {
+		addr = defaultAddr
+	}
 
-	// Start server
-	if err := e.Start(":8080"); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		slog.Error("failed to start server", "error", err)
+	shutdownTimeout := cfg.HTTPServer.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = DefaultShutdownTimeout
 	}
 
-	// Graceful shutdown
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-	<-stop
+	// The drain delay and the shutdown timeout both run within the fx stop timeout.
+	drainDelay := max(min(cfg.HTTPServer.DrainDelay, fx.DefaultTimeout-shutdownTimeout), 0)
 
-	_ = e.Server.Shutdown(context.Background())
+	e.Server.Addr = addr
+	e.Server.Handler = e
+	e.Server.ReadTimeout = cfg.HTTPServer.ReadTimeout
+	e.Server.WriteTimeout = cfg.HTTPServer.WriteTimeout
+	e.Server.IdleTimeout = cfg.HTTPServer.IdleTimeout
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// Listening before returning fails the start when the address is not available.
+			listener, err := new(net.ListenConfig).Listen(ctx, "tcp", addr)
+			if err != nil {
+				return fmt.Errorf("error when listen on %s: %w", addr, err)
+			}
+
+			logger.Info("http server started", "addr", listener.Addr().String())
+			go func() {
+				if err := e.Server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Error("failed to serve http", "error", err)
+					_ = shutdowner.Shutdown(fx.ExitCode(1))
+				}
+			}()
+
+			return nil
+		},
+		// The application stops on SIGINT or SIGTERM. The server keeps serving while not ready for the drain delay,
+		// then the in-flight requests are drained until the shutdown timeout.
+		OnStop: func(ctx context.Context) error {
+			registry.SetShuttingDown()
+			select {
+			case <-ctx.Done():
+			case <-time.After(drainDelay):
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+			defer cancel()
+
+			return e.Shutdown(ctx)
+		},
+	})
+}
+
+// isProbe reports whether the request is a health probe or a metrics scrape, which are not traced.
+func isProbe(c echo.Context) bool {
+	switch c.Request().URL.Path {
+	case "/ping", "/healthz", "/readyz", "/metrics":
+		return true
+	default:
+		return false
+	}
 }
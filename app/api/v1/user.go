@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+// UserService exposes all available use cases of user.
+type UserService interface {
+	GetUserElo(c echo.Context) error
+	BatchGetUserElos(c echo.Context) error
+	ListRatingHistory(c echo.Context) error
+}
+
+// UserElo represent for the current rating of a user.
+type UserElo = entity.UserElo
+
+// GetUserEloRequest represents for request of get the current rating of a user.
+type GetUserEloRequest struct {
+	UserID string `param:"user_id" json:"-" validate:"required"`
+}
+
+// GetUserEloResponse represents for response get the current rating of a user.
+type GetUserEloResponse = UserElo
+
+// BatchGetUserElosRequest represents for request of get the current ratings of users.
+type BatchGetUserElosRequest struct {
+	UserIDs []string `json:"userIDs" validate:"required,min=1,max=100,dive,required"`
+}
+
+// BatchGetUserElosResponse represents for response get the current ratings of users.
+type BatchGetUserElosResponse struct {
+	Items []*UserElo `json:"elos"`
+}
+
+// RatingHistory represent for a rating change of a user.
+type RatingHistory = entity.RatingHistory
+
+// ListRatingHistoryRequest represents for request of list the rating history of a user.
+type ListRatingHistoryRequest struct {
+	Pagination
+	UserID string `param:"user_id" json:"-" validate:"required"`
+	// From and To are inclusive unix timestamps bounding the history, zero means unbounded.
+	From int64 `query:"from" validate:"omitempty,min=0"`
+	To   int64 `query:"to" validate:"omitempty,min=0"`
+}
+
+// ListRatingHistoryResponse represents for response list the rating history of a user.
+type ListRatingHistoryResponse struct {
+	Page  int64            `json:"page"`
+	Size  int64            `json:"size"`
+	Items []*RatingHistory `json:"history"`
+}
@@ -0,0 +1,166 @@
+package v1impl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/me0den/example-service/app/api/v1"
+	"github.com/me0den/example-service/app/api/v1/transport/routes"
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/rating"
+	"github.com/me0den/example-service/infra/config"
+	"github.com/me0den/example-service/infra/health"
+	"github.com/me0den/example-service/infra/repoimpl"
+)
+
+const testAdminToken = "admin-token"
+
+// newTestServer returns the routes of every service backed by the in-memory repo and the configured calculators.
+func newTestServer(t *testing.T) *echo.Echo {
+	cfg := &config.Config{}
+	cfg.Storage.Driver = config.StorageDriverMemory
+	cfg.Rating.KFactor = 32
+	cfg.Season.ID = "2026-09"
+	cfg.Season.ResetFactor = 0.5
+
+	ratingRepo, err := repoimpl.NewRatingRepo(cfg, nil, nil, nil)
+	require.NoError(t, err)
+
+	teamCalculator := rating.NewTeamCalculator(rating.NewElo(cfg.Rating.KFactor), rating.AggregateAverage)
+	e := echo.New()
+	e.Validator = routes.NewValidator()
+	routes.RegisterRoutes(
+		e,
+		testAdminToken,
+		NewRewardService(ratingRepo, teamCalculator, nil),
+		NewLeaderboardService(ratingRepo),
+		NewUserService(ratingRepo),
+		NewSeasonService(ratingRepo, rating.NewSoftReset(cfg.Season.ResetFactor)),
+		NewMatchmakingService(ratingRepo, rating.NewMatchmaking(&cfg.Matchmaking)),
+	)
+
+	return e
+}
+
+// serve sends a request to e and decodes the JSON response into res when it is not nil.
+func serve(t *testing.T, e *echo.Echo, method, target string, body, res any) int {
+	return serveAuthorized(t, e, method, target, "", body, res)
+}
+
+// serveAuthorized sends a request with the authorization header to e as serve does, the empty authorization is not sent.
+func serveAuthorized(t *testing.T, e *echo.Echo, method, target, authorization string, body, res any) int {
+	var reader io.Reader
+	if body != nil {
+		marshalled, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(marshalled)
+	}
+
+	req := httptest.NewRequest(method, target, reader)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if authorization != "" {
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if res != nil && rec.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), res))
+	}
+
+	return rec.Code
+}
+
+func TestEndToEnd_Reward(t *testing.T) {
+	e := newTestServer(t)
+	reward := &v1.CreateRewardRequest{
+		Winner: "user_1",
+		Teams:  []*entity.Team{{ID: "team_1", Owner: "user_1"}, {ID: "team_2", Owner: "user_2"}},
+	}
+
+	assert.Equal(t, http.StatusOK, serve(t, e, http.MethodPost, "/v1/battle/battle_1/reward", reward, nil))
+
+	userElo := &v1.GetUserEloResponse{}
+	assert.Equal(t, http.StatusOK, serve(t, e, http.MethodGet, "/v1/users/user_1/elo", nil, userElo))
+	assert.Equal(t, entity.DefaultElo+16, userElo.Elo)
+
+	leaderboard := &v1.ListLeaderboardResponse{}
+	assert.Equal(t, http.StatusOK, serve(t, e, http.MethodGet, "/v1/leaderboard", nil, leaderboard))
+	require.Len(t, leaderboard.Items, 2)
+	assert.Equal(t, "user_1", leaderboard.Items[0].UserID)
+	assert.Equal(t, "user_2", leaderboard.Items[1].UserID)
+
+	// The voided battle gives the users their elo back and cannot be voided twice.
+	admin := "Bearer " + testAdminToken
+	assert.Equal(t, http.StatusOK, serveAuthorized(t, e, http.MethodDelete, "/v1/battle/battle_1/reward", admin, nil, nil))
+	assert.Equal(t, http.StatusConflict, serveAuthorized(t, e, http.MethodDelete, "/v1/battle/battle_1/reward", admin, nil, nil))
+	assert.Equal(t, http.StatusOK, serve(t, e, http.MethodGet, "/v1/users/user_1/elo", nil, userElo))
+	assert.Equal(t, entity.DefaultElo, userElo.Elo)
+
+	history := &v1.ListRatingHistoryResponse{}
+	assert.Equal(t, http.StatusOK, serve(t, e, http.MethodGet, "/v1/users/user_2/history", nil, history))
+	require.Len(t, history.Items, 2)
+	assert.Equal(t, entity.HistoryReasonVoid, history.Items[0].Reason)
+	assert.Equal(t, entity.HistoryReasonBattle, history.Items[1].Reason)
+}
+
+func TestEndToEnd_AdminRoutes(t *testing.T) {
+	e := newTestServer(t)
+	rollover := func(authorization string) int {
+		return serveAuthorized(t, e, http.MethodPost, "/v1/seasons/rollover", authorization,
+			&v1.RolloverSeasonRequest{SeasonID: "2026-10"}, nil)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, rollover(""))
+	assert.Equal(t, http.StatusUnauthorized, rollover("Bearer wrong-token"))
+	assert.Equal(t, http.StatusOK, rollover("Bearer "+testAdminToken))
+}
+
+func TestEndToEnd_VoidRequiresAdmin(t *testing.T) {
+	e := newTestServer(t)
+	reward := &v1.CreateRewardRequest{
+		Winner: "user_1",
+		Teams:  []*entity.Team{{ID: "team_1", Owner: "user_1"}, {ID: "team_2", Owner: "user_2"}},
+	}
+	require.Equal(t, http.StatusOK, serve(t, e, http.MethodPost, "/v1/battle/battle_1/reward", reward, nil))
+
+	// An anonymous caller cannot reverse the ratings of a battle.
+	voidBattle := func(authorization string) int {
+		return serveAuthorized(t, e, http.MethodDelete, "/v1/battle/battle_1/reward", authorization, nil, nil)
+	}
+	assert.Equal(t, http.StatusBadRequest, voidBattle(""))
+	assert.Equal(t, http.StatusUnauthorized, voidBattle("Bearer wrong-token"))
+
+	userElo := &v1.GetUserEloResponse{}
+	assert.Equal(t, http.StatusOK, serve(t, e, http.MethodGet, "/v1/users/user_1/elo", nil, userElo))
+	assert.Equal(t, entity.DefaultElo+16, userElo.Elo)
+}
+
+func TestEndToEnd_Probes(t *testing.T) {
+	e := newTestServer(t)
+	registry := health.NewRegistry()
+	routes.RegisterHealthRoutes(e, registry)
+	redisErr := errors.New("connection refused")
+	registry.Register("redis", func(context.Context) error { return redisErr })
+
+	report := &health.Report{}
+	assert.Equal(t, http.StatusOK, serve(t, e, http.MethodGet, "/healthz", nil, report))
+	assert.Equal(t, health.StatusUp, report.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, serve(t, e, http.MethodGet, "/readyz", nil, nil))
+
+	redisErr = nil
+	assert.Equal(t, http.StatusOK, serve(t, e, http.MethodGet, "/readyz", nil, report))
+	assert.Equal(t, &health.ComponentStatus{Status: health.StatusUp}, report.Components["redis"])
+
+	registry.SetShuttingDown()
+	assert.Equal(t, http.StatusServiceUnavailable, serve(t, e, http.MethodGet, "/readyz", nil, nil))
+}
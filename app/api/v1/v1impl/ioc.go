@@ -5,4 +5,8 @@ import "go.uber.org/fx"
 // FXModule represents a FX module for app api service.
 var FXModule = fx.Provide(
 	NewRewardService,
+	NewLeaderboardService,
+	NewUserService,
+	NewSeasonService,
+	NewMatchmakingService,
 )
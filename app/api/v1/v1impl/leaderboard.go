@@ -0,0 +1,88 @@
+package v1impl
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	v1 "github.com/me0den/example-service/app/api/v1"
+	"github.com/me0den/example-service/domain/repo"
+)
+
+// LeaderboardService implements all use cases of leaderboard service.
+type LeaderboardService struct {
+	ratingRepo repo.RatingRepo
+}
+
+// NewLeaderboardService creates and returns new instance of LeaderboardService.
+func NewLeaderboardService(
+	ratingRepo repo.RatingRepo,
+) v1.LeaderboardService {
+	svc := &LeaderboardService{
+		ratingRepo: ratingRepo,
+	}
+
+	return svc
+}
+
+// ListLeaderboard to list a page of the top users ordered by descending elo.
+func (s *LeaderboardService) ListLeaderboard(c echo.Context) error {
+	req := new(v1.ListLeaderboardRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	userRanks, err := s.ratingRepo.ListUserRanks(ctx, req.GetOffset(), req.GetSize())
+	if err != nil {
+		return err
+	}
+
+	res := &v1.ListLeaderboardResponse{
+		Page:  req.GetPage(),
+		Size:  req.GetSize(),
+		Items: userRanks,
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetUserLeaderboard to get the rank of a user with the neighbours above and below.
+func (s *LeaderboardService) GetUserLeaderboard(c echo.Context) error {
+	req := new(v1.GetUserLeaderboardRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	userRank, err := s.ratingRepo.GetUserRank(ctx, req.UserID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "user is not ranked")
+	}
+	if err != nil {
+		return err
+	}
+
+	neighbours := req.GetNeighbours()
+	offset := max(userRank.Rank-1-neighbours, 0)
+	userRanks, err := s.ratingRepo.ListUserRanks(ctx, offset, userRank.Rank-offset+neighbours)
+	if err != nil {
+		return err
+	}
+
+	res := &v1.GetUserLeaderboardResponse{
+		User:  userRank,
+		Items: userRanks,
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,237 @@
+package v1impl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+
+	v1 "github.com/me0den/example-service/app/api/v1"
+	"github.com/me0den/example-service/app/api/v1/transport/routes"
+	"github.com/me0den/example-service/app/api/v1/v1impl/mock"
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/repo"
+)
+
+// newTestContext creates an echo context for a request with target, JSON body and path params.
+func newTestContext(method, target string, body any, params map[string]string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	e.Validator = routes.NewValidator()
+
+	rec := httptest.NewRecorder()
+	var reader io.Reader
+	if body != nil {
+		marshalled, _ := json.Marshal(body)
+		reader = bytes.NewReader(marshalled)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c := e.NewContext(req, rec)
+	var names, values []string
+	for name, value := range params {
+		names = append(names, name)
+		values = append(values, value)
+	}
+	c.SetParamNames(names...)
+	c.SetParamValues(values...)
+
+	return c, rec
+}
+
+func TestLeaderboardService_ListLeaderboard(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		want       *v1.ListLeaderboardResponse
+		err        error
+		wantErr    bool
+		setupMocks func(repo *mock.RatingRepo)
+	}{
+		{
+			name:   "Default page and size",
+			target: "/v1/leaderboard",
+			want: &v1.ListLeaderboardResponse{
+				Page: 1,
+				Size: v1.DefaultPageSize,
+				Items: []*v1.UserRank{
+					{UserID: "user_1", Elo: 1200, Rank: 1},
+					{UserID: "user_2", Elo: 1100, Rank: 2},
+				},
+			},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("ListUserRanks", tmock.Anything, int64(0), int64(v1.DefaultPageSize)).
+					Return([]*entity.UserRank{
+						{UserID: "user_1", Elo: 1200, Rank: 1},
+						{UserID: "user_2", Elo: 1100, Rank: 2},
+					}, nil)
+			},
+		},
+		{
+			name:   "Third page of 10",
+			target: "/v1/leaderboard?page=3&size=10",
+			want: &v1.ListLeaderboardResponse{
+				Page: 3,
+				Size: 10,
+				Items: []*v1.UserRank{
+					{UserID: "user_21", Elo: 1000, Rank: 21},
+				},
+			},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("ListUserRanks", tmock.Anything, int64(20), int64(10)).
+					Return([]*entity.UserRank{{UserID: "user_21", Elo: 1000, Rank: 21}}, nil)
+			},
+		},
+		{
+			name:       "Page size is too large",
+			target:     "/v1/leaderboard?size=1000",
+			err:        echo.NewHTTPError(http.StatusBadRequest, []string{"size must be less than or equals to 100"}),
+			wantErr:    true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {},
+		},
+		{
+			name:       "Page is too large",
+			target:     "/v1/leaderboard?page=9223372036854775807",
+			err:        echo.NewHTTPError(http.StatusBadRequest, []string{"page must be less than or equals to 1000000"}),
+			wantErr:    true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {},
+		},
+		{
+			name:    "Failed to list user ranks",
+			target:  "/v1/leaderboard",
+			err:     echo.ErrInternalServerError,
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("ListUserRanks", tmock.Anything, tmock.Anything, tmock.Anything).
+					Return(nil, echo.ErrInternalServerError)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratingRepo := &mock.RatingRepo{}
+			tt.setupMocks(ratingRepo)
+			svc := &LeaderboardService{
+				ratingRepo: ratingRepo,
+			}
+
+			c, rec := newTestContext(http.MethodGet, tt.target, nil, nil)
+			err := svc.ListLeaderboard(c)
+			if tt.wantErr {
+				assert.Equal(t, tt.err.Error(), err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			var resp v1.ListLeaderboardResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.want, &resp)
+		})
+	}
+}
+
+func TestLeaderboardService_GetUserLeaderboard(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		userID     string
+		want       *v1.GetUserLeaderboardResponse
+		err        error
+		wantErr    bool
+		setupMocks func(repo *mock.RatingRepo)
+	}{
+		{
+			name:   "Neighbours above and below",
+			target: "/v1/leaderboard/users/user_5?neighbours=1",
+			userID: "user_5",
+			want: &v1.GetUserLeaderboardResponse{
+				User: &v1.UserRank{UserID: "user_5", Elo: 1100, Rank: 5},
+				Items: []*v1.UserRank{
+					{UserID: "user_4", Elo: 1150, Rank: 4},
+					{UserID: "user_5", Elo: 1100, Rank: 5},
+					{UserID: "user_6", Elo: 1050, Rank: 6},
+				},
+			},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetUserRank", tmock.Anything, "user_5").
+					Return(&entity.UserRank{UserID: "user_5", Elo: 1100, Rank: 5}, nil)
+				mockRepo.On("ListUserRanks", tmock.Anything, int64(3), int64(3)).
+					Return([]*entity.UserRank{
+						{UserID: "user_4", Elo: 1150, Rank: 4},
+						{UserID: "user_5", Elo: 1100, Rank: 5},
+						{UserID: "user_6", Elo: 1050, Rank: 6},
+					}, nil)
+			},
+		},
+		{
+			name:   "Top user has no neighbours above",
+			target: "/v1/leaderboard/users/user_1",
+			userID: "user_1",
+			want: &v1.GetUserLeaderboardResponse{
+				User: &v1.UserRank{UserID: "user_1", Elo: 1300, Rank: 1},
+				Items: []*v1.UserRank{
+					{UserID: "user_1", Elo: 1300, Rank: 1},
+					{UserID: "user_2", Elo: 1200, Rank: 2},
+				},
+			},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetUserRank", tmock.Anything, "user_1").
+					Return(&entity.UserRank{UserID: "user_1", Elo: 1300, Rank: 1}, nil)
+				mockRepo.On("ListUserRanks", tmock.Anything, int64(0), int64(1+v1.DefaultLeaderboardNeighbours)).
+					Return([]*entity.UserRank{
+						{UserID: "user_1", Elo: 1300, Rank: 1},
+						{UserID: "user_2", Elo: 1200, Rank: 2},
+					}, nil)
+			},
+		},
+		{
+			name:    "User is not ranked",
+			target:  "/v1/leaderboard/users/user_x",
+			userID:  "user_x",
+			err:     echo.NewHTTPError(http.StatusNotFound, "user is not ranked"),
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetUserRank", tmock.Anything, "user_x").Return(nil, repo.ErrNotFound)
+			},
+		},
+		{
+			name:    "Failed to get user rank",
+			target:  "/v1/leaderboard/users/user_1",
+			userID:  "user_1",
+			err:     errors.New("redis connection failed"),
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetUserRank", tmock.Anything, "user_1").Return(nil, errors.New("redis connection failed"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratingRepo := &mock.RatingRepo{}
+			tt.setupMocks(ratingRepo)
+			svc := &LeaderboardService{
+				ratingRepo: ratingRepo,
+			}
+
+			c, rec := newTestContext(http.MethodGet, tt.target, nil, map[string]string{"user_id": tt.userID})
+			err := svc.GetUserLeaderboard(c)
+			if tt.wantErr {
+				assert.Equal(t, tt.err.Error(), err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			var resp v1.GetUserLeaderboardResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.want, &resp)
+		})
+	}
+}
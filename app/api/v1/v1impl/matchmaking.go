@@ -0,0 +1,121 @@
+package v1impl
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	v1 "github.com/me0den/example-service/app/api/v1"
+	"github.com/me0den/example-service/domain/rating"
+	"github.com/me0den/example-service/domain/repo"
+)
+
+// MatchmakingService implements all use cases of matchmaking service.
+type MatchmakingService struct {
+	ratingRepo  repo.RatingRepo
+	matchmaking *rating.Matchmaking
+}
+
+// NewMatchmakingService creates and returns new instance of MatchmakingService.
+func NewMatchmakingService(
+	ratingRepo repo.RatingRepo,
+	matchmaking *rating.Matchmaking,
+) v1.MatchmakingService {
+	svc := &MatchmakingService{
+		ratingRepo:  ratingRepo,
+		matchmaking: matchmaking,
+	}
+
+	return svc
+}
+
+// JoinQueue to queue a user for matchmaking, a queued user keeps waiting since they joined.
+func (s *MatchmakingService) JoinQueue(c echo.Context) error {
+	now := time.Now()
+	req := new(v1.JoinQueueRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	queued, err := s.ratingRepo.JoinQueue(ctx, req.UserID, now.Unix(), s.queuedSince(now))
+	if err != nil {
+		return err
+	}
+
+	var res *v1.JoinQueueResponse = queued
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// LeaveQueue to remove a user from the matchmaking queue.
+func (s *MatchmakingService) LeaveQueue(c echo.Context) error {
+	req := new(v1.LeaveQueueRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	err := s.ratingRepo.LeaveQueue(ctx, req.UserID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "user is not queued")
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListCandidates to list the queued users rated within the window of a queued user, the closest rating first.
+// The window widens over the wait time of the user.
+func (s *MatchmakingService) ListCandidates(c echo.Context) error {
+	now := time.Now()
+	req := new(v1.ListCandidatesRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	since := s.queuedSince(now)
+	user, err := s.ratingRepo.GetQueuedUser(ctx, req.UserID, since)
+	if errors.Is(err, repo.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "user is not queued")
+	}
+	if err != nil {
+		return err
+	}
+
+	window := s.matchmaking.Window(req.Window, now.Sub(time.Unix(user.JoinedAt, 0)))
+	queued, err := s.ratingRepo.ListQueuedUsers(ctx, since, user.Elo-window, user.Elo+window)
+	if err != nil {
+		return err
+	}
+
+	res := &v1.ListCandidatesResponse{
+		User:   user,
+		Window: window,
+		Items:  s.matchmaking.Candidates(user, queued, req.GetSize()),
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// queuedSince returns the unix timestamp from which the users queued at now are still waiting.
+func (s *MatchmakingService) queuedSince(now time.Time) int64 {
+	return now.Add(-s.matchmaking.QueueTimeout()).Unix()
+}
@@ -0,0 +1,241 @@
+package v1impl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+
+	v1 "github.com/me0den/example-service/app/api/v1"
+	"github.com/me0den/example-service/app/api/v1/v1impl/mock"
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/rating"
+	"github.com/me0den/example-service/domain/repo"
+)
+
+func newTestMatchmaking() *rating.Matchmaking {
+	return rating.NewMatchmaking(&rating.MatchmakingConfig{
+		Window:           100,
+		WideningStep:     50,
+		WideningInterval: 10 * time.Second,
+		MaxWindow:        300,
+		QueueTimeout:     time.Minute,
+	})
+}
+
+func TestMatchmakingService_JoinQueue(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *v1.JoinQueueRequest
+		want       *v1.JoinQueueResponse
+		err        error
+		wantErr    bool
+		setupMocks func(repo *mock.RatingRepo)
+	}{
+		{
+			name: "Successful join queue",
+			req:  &v1.JoinQueueRequest{UserID: "user_1"},
+			want: &v1.JoinQueueResponse{UserID: "user_1", Elo: 1200, JoinedAt: 100},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("JoinQueue", tmock.Anything, "user_1", tmock.Anything, tmock.Anything).
+					Return(func(_ context.Context, userID string, joinedAt, since int64) (*entity.QueuedUser, error) {
+						if joinedAt-since != int64(time.Minute.Seconds()) {
+							return nil, errors.New("unexpected queue timeout")
+						}
+
+						return &entity.QueuedUser{UserID: userID, Elo: 1200, JoinedAt: 100}, nil
+					})
+			},
+		},
+		{
+			name:       "Validator request form: user id is required",
+			req:        &v1.JoinQueueRequest{},
+			err:        echo.NewHTTPError(http.StatusBadRequest, []string{"userID is required"}),
+			wantErr:    true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {},
+		},
+		{
+			name:    "Failed to join queue",
+			req:     &v1.JoinQueueRequest{UserID: "user_1"},
+			err:     echo.ErrInternalServerError,
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("JoinQueue", tmock.Anything, "user_1", tmock.Anything, tmock.Anything).
+					Return(nil, echo.ErrInternalServerError)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratingRepo := &mock.RatingRepo{}
+			tt.setupMocks(ratingRepo)
+			svc := &MatchmakingService{
+				ratingRepo:  ratingRepo,
+				matchmaking: newTestMatchmaking(),
+			}
+
+			c, rec := newTestContext(http.MethodPost, "/v1/matchmaking/queue", tt.req, nil)
+			err := svc.JoinQueue(c)
+			if tt.wantErr {
+				assert.Equal(t, tt.err.Error(), err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			var resp v1.JoinQueueResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.want, &resp)
+		})
+	}
+}
+
+func TestMatchmakingService_LeaveQueue(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		err        error
+		wantErr    bool
+		setupMocks func(repo *mock.RatingRepo)
+	}{
+		{
+			name:   "Successful leave queue",
+			userID: "user_1",
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("LeaveQueue", tmock.Anything, "user_1").Return(nil)
+			},
+		},
+		{
+			name:    "User is not queued",
+			userID:  "user_x",
+			err:     echo.NewHTTPError(http.StatusNotFound, "user is not queued"),
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("LeaveQueue", tmock.Anything, "user_x").Return(repo.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratingRepo := &mock.RatingRepo{}
+			tt.setupMocks(ratingRepo)
+			svc := &MatchmakingService{
+				ratingRepo:  ratingRepo,
+				matchmaking: newTestMatchmaking(),
+			}
+
+			c, rec := newTestContext(http.MethodDelete, "/", nil, map[string]string{"user_id": tt.userID})
+			err := svc.LeaveQueue(c)
+			if tt.wantErr {
+				assert.Equal(t, tt.err.Error(), err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+		})
+	}
+}
+
+func TestMatchmakingService_ListCandidates(t *testing.T) {
+	joinedAt := time.Now().Add(-25 * time.Second).Unix()
+	user := &entity.QueuedUser{UserID: "user_1", Elo: 1000, JoinedAt: joinedAt}
+	queued := []*entity.QueuedUser{
+		user,
+		{UserID: "user_3", Elo: 1150, JoinedAt: joinedAt + 5},
+		{UserID: "user_4", Elo: 1050, JoinedAt: joinedAt + 10},
+	}
+
+	tests := []struct {
+		name       string
+		target     string
+		want       *v1.ListCandidatesResponse
+		err        error
+		wantErr    bool
+		setupMocks func(repo *mock.RatingRepo)
+	}{
+		{
+			name:   "Window widened over the wait time",
+			target: "/v1/matchmaking/candidates?user_id=user_1",
+			want: &v1.ListCandidatesResponse{
+				User:   user,
+				Window: 200,
+				Items:  []*v1.QueuedUser{queued[2], queued[1]},
+			},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetQueuedUser", tmock.Anything, "user_1", tmock.Anything).Return(user, nil)
+				mockRepo.On("ListQueuedUsers", tmock.Anything, tmock.Anything, 800, 1200).Return(queued, nil)
+			},
+		},
+		{
+			name:   "Requested window and size",
+			target: "/v1/matchmaking/candidates?user_id=user_1&window=250&size=1",
+			want: &v1.ListCandidatesResponse{
+				User:   user,
+				Window: 300,
+				Items:  []*v1.QueuedUser{queued[2]},
+			},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetQueuedUser", tmock.Anything, "user_1", tmock.Anything).Return(user, nil)
+				mockRepo.On("ListQueuedUsers", tmock.Anything, tmock.Anything, 700, 1300).Return(queued, nil)
+			},
+		},
+		{
+			name:    "User is not queued",
+			target:  "/v1/matchmaking/candidates?user_id=user_x",
+			err:     echo.NewHTTPError(http.StatusNotFound, "user is not queued"),
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetQueuedUser", tmock.Anything, "user_x", tmock.Anything).Return(nil, repo.ErrNotFound)
+			},
+		},
+		{
+			name:       "Validator request form: user id is required",
+			target:     "/v1/matchmaking/candidates",
+			err:        echo.NewHTTPError(http.StatusBadRequest, []string{"user_id is required"}),
+			wantErr:    true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {},
+		},
+		{
+			name:    "Failed to list queued users",
+			target:  "/v1/matchmaking/candidates?user_id=user_1",
+			err:     errors.New("redis connection failed"),
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetQueuedUser", tmock.Anything, "user_1", tmock.Anything).Return(user, nil)
+				mockRepo.On("ListQueuedUsers", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything).
+					Return(nil, errors.New("redis connection failed"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratingRepo := &mock.RatingRepo{}
+			tt.setupMocks(ratingRepo)
+			svc := &MatchmakingService{
+				ratingRepo:  ratingRepo,
+				matchmaking: newTestMatchmaking(),
+			}
+
+			c, rec := newTestContext(http.MethodGet, tt.target, nil, nil)
+			err := svc.ListCandidates(c)
+			if tt.wantErr {
+				assert.Equal(t, tt.err.Error(), err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			var resp v1.ListCandidatesResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.want, &resp)
+		})
+	}
+}
@@ -2,27 +2,42 @@ package v1impl
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	v1 "github.com/me0den/example-service/app/api/v1"
 	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/rating"
 	"github.com/me0den/example-service/domain/repo"
+	"github.com/me0den/example-service/infra/metrics"
 )
 
+// tracer creates the spans of the steps of the services with the global tracer provider.
+var tracer = otel.Tracer("github.com/me0den/example-service/app/api/v1/v1impl")
+
 // RewardService implements all use cases of reward service.
 type RewardService struct {
-	redisRepo repo.RedisRepo
+	ratingRepo     repo.RatingRepo
+	teamCalculator *rating.TeamCalculator
+	ratingMetrics  *metrics.RatingMetrics
 }
 
 // NewRewardService creates and returns new instance of RewardService.
 func NewRewardService(
-	redisRepo repo.RedisRepo,
+	ratingRepo repo.RatingRepo,
+	teamCalculator *rating.TeamCalculator,
+	ratingMetrics *metrics.RatingMetrics,
 ) v1.RewardService {
 	svc := &RewardService{
-		redisRepo: redisRepo,
+		ratingRepo:     ratingRepo,
+		teamCalculator: teamCalculator,
+		ratingMetrics:  ratingMetrics,
 	}
 
 	return svc
@@ -31,7 +46,79 @@ func NewRewardService(
 // CreateReward to calculate and update new reward elo for user after a battle.
 func (s *RewardService) CreateReward(c echo.Context) error {
 	updatedAt := time.Now().Unix()
-	req := new(v1.CreateRewardRequest)
+	ctx := c.Request().Context()
+	req, err := s.bindCreateReward(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	placements := req.GetPlacements()
+	// fn may be called again on a conflict, then the battle may be found stored by a concurrent retry.
+	var calculatedBattle *entity.Battle
+	battle, err := s.ratingRepo.ApplyBattle(ctx, req.BattleID, req.GetUserIDs(),
+		func(userElos []*entity.UserElo) (*entity.Battle, []*entity.UserElo, error) {
+			teamElos := splitTeams(req.Teams, userElos)
+			_, span := tracer.Start(ctx, "RewardService.calculateElo", trace.WithAttributes(attribute.Int("team.count", len(teamElos))))
+			newTeamElos := s.calculateElo(ctx, teamElos, placements)
+			span.End()
+			battle := &entity.Battle{ID: req.BattleID, Placements: placements}
+			for _, team := range req.Teams {
+				battle.Teams = append(battle.Teams, team.GetMembers())
+			}
+
+			var newUserElos []*entity.UserElo
+			for teamIdx, team := range req.Teams {
+				for memberIdx, elo := range newTeamElos[teamIdx] {
+					elo.AddGame()
+					rankReward := &v1.Reward{
+						NewElo:     elo.Elo,
+						OldElo:     teamElos[teamIdx][memberIdx].Elo,
+						UserID:     elo.UserID,
+						TeamID:     team.ID,
+						Deviation:  elo.Deviation,
+						Volatility: elo.Volatility,
+						Opponents:  req.GetOpponents(teamIdx),
+						Result:     req.GetResult(teamIdx),
+						Placement:  placements[teamIdx],
+						UpdatedAt:  updatedAt,
+					}
+
+					battle.Rewards = append(battle.Rewards, rankReward)
+					newUserElos = append(newUserElos, elo)
+				}
+			}
+
+			calculatedBattle = battle
+			return battle, newUserElos, nil
+		},
+	)
+	if errors.Is(err, repo.ErrConflict) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	// A retried battle gets the stored result instead of being applied again, so it is not observed again.
+	// Only the battle stored by this call is the one last calculated by fn.
+	stored := battle == calculatedBattle
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("battle.id", battle.ID),
+		attribute.Bool("battle.replayed", !stored),
+	)
+	if stored {
+		s.ratingMetrics.ObserveBattle(battle)
+	}
+
+	res := &v1.CreateRewardResponse{Items: battle.Rewards}
+
+	return c.JSON(http.StatusOK, &res)
+}
+
+// DeleteReward to void a battle and reverse the elo changes of its reward for every user.
+func (s *RewardService) DeleteReward(c echo.Context) error {
+	voidedAt := time.Now().Unix()
+	req := new(v1.DeleteRewardRequest)
 	if err := c.Bind(req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
@@ -41,64 +128,111 @@ func (s *RewardService) CreateReward(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
-	userElos, err := s.listUserElos(ctx, req.Teams)
+	battle, err := s.ratingRepo.VoidBattle(ctx, req.BattleID, voidedAt)
+	if errors.Is(err, repo.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "battle is not found")
+	}
+	if errors.Is(err, repo.ErrVoided) {
+		return echo.NewHTTPError(http.StatusConflict, "battle is already voided")
+	}
+	if errors.Is(err, repo.ErrSeasonEnded) {
+		return echo.NewHTTPError(http.StatusConflict, "battle belongs to an ended season")
+	}
+	if errors.Is(err, repo.ErrConflict) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
 	if err != nil {
 		return err
 	}
 
-	winnerIndex := req.GetWinnerIndex()
-	newUserElos := s.calculateElo(ctx, userElos, winnerIndex)
-	res := &v1.CreateRewardResponse{}
-	for idx, elo := range newUserElos {
-		rankReward := &v1.Reward{
-			NewElo:    elo.Elo,
-			OldElo:    userElos[idx].Elo,
-			UserID:    elo.UserID,
-			UpdatedAt: updatedAt,
-		}
+	res := &v1.DeleteRewardResponse{Items: battle.Reversals}
+
+	return c.JSON(http.StatusOK, &res)
+}
 
-		res.Items = append(res.Items, rankReward)
+// PreviewBattle to calculate the win probability of every team and the elo change of its members for every
+// outcome of a battle, nothing is stored.
+func (s *RewardService) PreviewBattle(c echo.Context) error {
+	req := new(v1.PreviewBattleRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	if err := s.redisRepo.BatchUpdateElo(ctx, newUserElos); err != nil {
+	if err := c.Validate(req); err != nil {
 		return err
 	}
 
+	if !req.HasUniqueMembers() {
+		return echo.NewHTTPError(http.StatusBadRequest, []string{"members of teams must be unique"})
+	}
+
+	ctx := c.Request().Context()
+	userElos, err := s.ratingRepo.ListUserElos(ctx, req.GetUserIDs())
+	if err != nil {
+		return err
+	}
+
+	teamElos := splitTeams(req.Teams, userElos)
+	res := &v1.PreviewBattleResponse{}
+	for teamIdx, preview := range s.teamCalculator.Preview(teamElos) {
+		teamPreview := &v1.TeamPreview{
+			TeamID:         req.Teams[teamIdx].ID,
+			WinProbability: preview.WinProbability,
+		}
+		for memberIdx, elo := range teamElos[teamIdx] {
+			teamPreview.Members = append(teamPreview.Members, &v1.MemberPreview{
+				UserID: elo.UserID,
+				Elo:    elo.Elo,
+				Win:    preview.Win[memberIdx].Elo - elo.Elo,
+				Draw:   preview.Draw[memberIdx].Elo - elo.Elo,
+				Lose:   preview.Lose[memberIdx].Elo - elo.Elo,
+			})
+		}
+
+		res.Teams = append(res.Teams, teamPreview)
+	}
+
 	return c.JSON(http.StatusOK, &res)
 }
 
-// listUserElos to list all current elo of users.
-func (s *RewardService) listUserElos(ctx context.Context, teams []*entity.Team) ([]*entity.UserElo, error) {
-	var userElos []*entity.UserElo
-	for _, team := range teams {
-		userElo, err := s.redisRepo.GetUserElo(ctx, team.Owner)
-		if err != nil {
-			return nil, err
-		}
+// calculateElo to calculate the new elo of the members of every team based on the finishing placement of the teams.
+func (s *RewardService) calculateElo(ctx context.Context, teams [][]*entity.UserElo, placements []int) [][]*entity.UserElo {
+	return s.teamCalculator.Calculate(teams, placements)
+}
+
+// bindCreateReward binds the request of CreateReward and validates its teams and outcome.
+func (s *RewardService) bindCreateReward(ctx context.Context, c echo.Context) (*v1.CreateRewardRequest, error) {
+	_, span := tracer.Start(ctx, "RewardService.bindCreateReward")
+	defer span.End()
+
+	req := new(v1.CreateRewardRequest)
+	if err := c.Bind(req); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return nil, err
+	}
+
+	if !req.HasUniqueMembers() {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, []string{"members of teams must be unique"})
+	}
 
-		userElos = append(userElos, userElo)
+	if err := req.ValidateOutcome(); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, []string{err.Error()})
 	}
 
-	return userElos, nil
+	return req, nil
 }
 
-// calculateElo to calculate the new elo based on battle result.
-func (s *RewardService) calculateElo(ctx context.Context, userElos []*entity.UserElo, winnerIdx int) []*entity.UserElo {
-	newUserElos := []*entity.UserElo{
-		userElos[0].Clone(),
-		userElos[1].Clone(),
-	}
-	switch winnerIdx {
-	case 0:
-		newUserElos[0].Elo += 5
-		newUserElos[1].Elo += 5
-	case 1:
-		newUserElos[0].Elo += 10
-		newUserElos[1].Elo -= 10
-	case 2:
-		newUserElos[1].Elo += 10
-		newUserElos[0].Elo -= 10
-	}
-
-	return newUserElos
+// splitTeams splits userElos listed in the order of the members of teams into the elos of every team.
+func splitTeams(teams []*entity.Team, userElos []*entity.UserElo) [][]*entity.UserElo {
+	teamElos := make([][]*entity.UserElo, 0, len(teams))
+	for _, team := range teams {
+		size := len(team.GetMembers())
+		teamElos = append(teamElos, userElos[:size])
+		userElos = userElos[size:]
+	}
+
+	return teamElos
 }
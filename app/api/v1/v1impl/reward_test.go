@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -13,15 +12,25 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	tmock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	v1 "github.com/me0den/example-service/app/api/v1"
 	"github.com/me0den/example-service/app/api/v1/transport/routes"
 	"github.com/me0den/example-service/app/api/v1/v1impl/mock"
 	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/enum"
+	"github.com/me0den/example-service/domain/rating"
+	"github.com/me0den/example-service/domain/repo"
+	"github.com/me0den/example-service/infra/metrics"
 )
 
+func ptr[T any](v T) *T {
+	return &v
+}
+
 func TestRewardService_CreateReward(t *testing.T) {
 	type args struct {
 		ctx       echo.Context
@@ -29,45 +38,27 @@ func TestRewardService_CreateReward(t *testing.T) {
 		isBadBind bool
 	}
 
-	type listUserElosArgs struct {
-		teams []*entity.Team
-	}
-
-	type listUserElosWant struct {
-		err      error
+	type ApplyBattleArgs struct {
+		userIDs  []string
 		userElos []*entity.UserElo
 	}
 
-	type calculateEloArgs struct {
-		userElos  []*entity.UserElo
-		winnerIdx int
-	}
-
-	type calculateEloWant struct {
-		newUserElos []*entity.UserElo
-	}
-
-	type BatchUpdateEloArgs struct {
-		newUserElos []*entity.UserElo
-	}
-
-	type BatchUpdateEloWant struct {
-		err error
+	type ApplyBattleWant struct {
+		storedBattle *entity.Battle
+		newUserElos  []*entity.UserElo
+		err          error
 	}
 
+	battleID := "battle_1"
 	defaultElo := 1000
 	tests := []struct {
-		name               string
-		args               args
-		err                error
-		want               *v1.CreateRewardResponse
-		wantErr            bool
-		listUserElosArgs   *listUserElosArgs
-		listUserElosWant   *listUserElosWant
-		calculateEloArgs   *calculateEloArgs
-		calculateEloWant   *calculateEloWant
-		BatchUpdateEloArgs *BatchUpdateEloArgs
-		BatchUpdateEloWant *BatchUpdateEloWant
+		name            string
+		args            args
+		err             error
+		want            *v1.CreateRewardResponse
+		wantErr         bool
+		ApplyBattleArgs *ApplyBattleArgs
+		ApplyBattleWant *ApplyBattleWant
 	}{
 		{
 			name: "Validator request form: required validation fail",
@@ -75,11 +66,55 @@ func TestRewardService_CreateReward(t *testing.T) {
 				req: &v1.CreateRewardRequest{},
 			},
 			want:    nil,
-			err:     echo.NewHTTPError(http.StatusBadRequest, []string{"winner is required", "teams is required"}),
+			err:     echo.NewHTTPError(http.StatusBadRequest, []string{"teams is required"}),
 			wantErr: true,
 		},
 		{
-			name: "Validator request form: teams is not equals to 2.",
+			name: "Validator request form: users of teams are not unique",
+			args: args{
+				req: &v1.CreateRewardRequest{
+					Teams: []*entity.Team{
+						{ID: "team_1", Owner: "user_1"},
+						{ID: "team_2", Owner: "user_1"},
+					},
+					Winner: "user_1",
+				},
+			},
+			want:    nil,
+			err:     echo.NewHTTPError(http.StatusBadRequest, []string{"teams must be unique"}),
+			wantErr: true,
+		},
+		{
+			name: "Neither winner, results nor placements",
+			args: args{
+				req: &v1.CreateRewardRequest{
+					Teams: []*entity.Team{
+						{ID: "team_1", Owner: "user_1"},
+						{ID: "team_2", Owner: "user_2"},
+					},
+				},
+			},
+			want:    nil,
+			err:     echo.NewHTTPError(http.StatusBadRequest, []string{"winner, draw, result or placement of every team is required"}),
+			wantErr: true,
+		},
+		{
+			name: "Placement of a team is missing",
+			args: args{
+				req: &v1.CreateRewardRequest{
+					Teams: []*entity.Team{
+						{ID: "team_1", Owner: "user_1", Placement: 1},
+						{ID: "team_2", Owner: "user_2"},
+					},
+					Winner: "user_1",
+				},
+			},
+			want:    nil,
+			err:     echo.NewHTTPError(http.StatusBadRequest, []string{"placement of every team is required"}),
+			wantErr: true,
+		},
+		{
+			name: "Validator request form: teams is less than 2.",
 			args: args{
 				req: &v1.CreateRewardRequest{
 					Teams: []*entity.Team{
@@ -92,7 +127,7 @@ func TestRewardService_CreateReward(t *testing.T) {
 				},
 			},
 			want:    nil,
-			err:     echo.NewHTTPError(http.StatusBadRequest, []string{"teams must be equals to 2"}),
+			err:     echo.NewHTTPError(http.StatusBadRequest, []string{"teams must be greater than or equals to 2"}),
 			wantErr: true,
 		},
 		{
@@ -125,86 +160,150 @@ func TestRewardService_CreateReward(t *testing.T) {
 			want: &v1.CreateRewardResponse{
 				Items: []*v1.Reward{
 					{
-						UserID: "user_1",
-						OldElo: defaultElo,
-						NewElo: defaultElo + 10,
+						UserID:     "user_1",
+						TeamID:     "team_1",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo + 10,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_2"},
+						Result:     enum.BattleResultWin,
+						Placement:  1,
 					},
 					{
-						UserID: "user_2",
-						OldElo: defaultElo,
-						NewElo: defaultElo - 10,
+						UserID:     "user_2",
+						TeamID:     "team_2",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo - 10,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_1"},
+						Result:     enum.BattleResultLose,
+						Placement:  2,
 					},
 				},
 			},
 			err:     nil,
 			wantErr: false,
-			listUserElosArgs: &listUserElosArgs{
-				teams: []*entity.Team{
+			ApplyBattleArgs: &ApplyBattleArgs{
+				userIDs: []string{"user_1", "user_2"},
+				userElos: []*entity.UserElo{
 					{
-						ID:    "team_1",
-						Owner: "user_1",
+						UserID:     "user_1",
+						Elo:        defaultElo,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
 					},
 					{
-						ID:    "team_2",
-						Owner: "user_2",
+						UserID:     "user_2",
+						Elo:        defaultElo,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
 					},
 				},
 			},
-			listUserElosWant: &listUserElosWant{
-				userElos: []*entity.UserElo{
+			ApplyBattleWant: &ApplyBattleWant{
+				newUserElos: []*entity.UserElo{
 					{
-						UserID: "user_1",
-						Elo:    defaultElo,
+						UserID:      "user_1",
+						Elo:         defaultElo + 10,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
 					},
 					{
-						UserID: "user_2",
-						Elo:    defaultElo,
+						UserID:      "user_2",
+						Elo:         defaultElo - 10,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
 					},
 				},
 			},
-			calculateEloArgs: &calculateEloArgs{
-				userElos: []*entity.UserElo{
+		},
+		{
+			name: "Successful create reward: user_2 is winner",
+			args: args{
+				req: &v1.CreateRewardRequest{
+					Teams: []*entity.Team{
+						{
+							ID:    "team_1",
+							Owner: "user_1",
+						},
+						{
+							ID:    "team_2",
+							Owner: "user_2",
+						},
+					},
+					Winner: "user_2",
+				},
+			},
+			want: &v1.CreateRewardResponse{
+				Items: []*v1.Reward{
 					{
-						UserID: "user_1",
-						Elo:    defaultElo,
+						UserID:     "user_1",
+						TeamID:     "team_1",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo - 10,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_2"},
+						Result:     enum.BattleResultLose,
+						Placement:  2,
 					},
 					{
-						UserID: "user_2",
-						Elo:    defaultElo,
+						UserID:     "user_2",
+						TeamID:     "team_2",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo + 10,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_1"},
+						Result:     enum.BattleResultWin,
+						Placement:  1,
 					},
 				},
-				winnerIdx: 1,
 			},
-			calculateEloWant: &calculateEloWant{
-				newUserElos: []*entity.UserElo{
+			err:     nil,
+			wantErr: false,
+			ApplyBattleArgs: &ApplyBattleArgs{
+				userIDs: []string{"user_1", "user_2"},
+				userElos: []*entity.UserElo{
 					{
-						UserID: "user_1",
-						Elo:    defaultElo + 10,
+						UserID:     "user_1",
+						Elo:        defaultElo,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
 					},
 					{
-						UserID: "user_2",
-						Elo:    defaultElo - 10,
+						UserID:     "user_2",
+						Elo:        defaultElo,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
 					},
 				},
 			},
-			BatchUpdateEloArgs: &BatchUpdateEloArgs{
+			ApplyBattleWant: &ApplyBattleWant{
 				newUserElos: []*entity.UserElo{
 					{
-						UserID: "user_1",
-						Elo:    defaultElo + 10,
+						UserID:      "user_1",
+						Elo:         defaultElo - 10,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
 					},
 					{
-						UserID: "user_2",
-						Elo:    defaultElo - 10,
+						UserID:      "user_2",
+						Elo:         defaultElo + 10,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
 					},
 				},
 			},
-			BatchUpdateEloWant: &BatchUpdateEloWant{
-				err: nil,
-			},
 		},
 		{
-			name: "Successful create reward: user_2 is winner",
+			name: "Successful create reward: draw",
 			args: args{
 				req: &v1.CreateRewardRequest{
 					Teams: []*entity.Team{
@@ -217,190 +316,435 @@ func TestRewardService_CreateReward(t *testing.T) {
 							Owner: "user_2",
 						},
 					},
-					Winner: "user_2",
+					Draw: true,
 				},
 			},
 			want: &v1.CreateRewardResponse{
 				Items: []*v1.Reward{
 					{
-						UserID: "user_1",
-						OldElo: defaultElo,
-						NewElo: defaultElo - 10,
+						UserID:     "user_1",
+						TeamID:     "team_1",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_2"},
+						Result:     enum.BattleResultTie,
+						Placement:  1,
 					},
 					{
-						UserID: "user_2",
-						OldElo: defaultElo,
-						NewElo: defaultElo + 10,
+						UserID:     "user_2",
+						TeamID:     "team_2",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_1"},
+						Result:     enum.BattleResultTie,
+						Placement:  1,
 					},
 				},
 			},
 			err:     nil,
 			wantErr: false,
-			listUserElosArgs: &listUserElosArgs{
-				teams: []*entity.Team{
+			ApplyBattleArgs: &ApplyBattleArgs{
+				userIDs: []string{"user_1", "user_2"},
+				userElos: []*entity.UserElo{
 					{
-						ID:    "team_1",
-						Owner: "user_1",
+						UserID:     "user_1",
+						Elo:        defaultElo,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
 					},
 					{
-						ID:    "team_2",
-						Owner: "user_2",
+						UserID:     "user_2",
+						Elo:        defaultElo,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
 					},
 				},
 			},
-			listUserElosWant: &listUserElosWant{
-				userElos: []*entity.UserElo{
+			ApplyBattleWant: &ApplyBattleWant{
+				newUserElos: []*entity.UserElo{
 					{
-						UserID: "user_1",
-						Elo:    defaultElo,
+						UserID:      "user_1",
+						Elo:         defaultElo,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
 					},
 					{
-						UserID: "user_2",
-						Elo:    defaultElo,
+						UserID:      "user_2",
+						Elo:         defaultElo,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
 					},
 				},
 			},
-			calculateEloArgs: &calculateEloArgs{
-				userElos: []*entity.UserElo{
-					{
-						UserID: "user_1",
-						Elo:    defaultElo,
-					},
-					{
-						UserID: "user_2",
-						Elo:    defaultElo,
+		},
+		{
+			name: "Successful create reward: free-for-all placements",
+			args: args{
+				req: &v1.CreateRewardRequest{
+					Teams: []*entity.Team{
+						{ID: "team_1", Owner: "user_1", Placement: 2},
+						{ID: "team_2", Owner: "user_2", Placement: 1},
+						{ID: "team_3", Owner: "user_3", Placement: 3},
 					},
 				},
-				winnerIdx: 2,
 			},
-			calculateEloWant: &calculateEloWant{
-				newUserElos: []*entity.UserElo{
+			want: &v1.CreateRewardResponse{
+				Items: []*v1.Reward{
 					{
-						UserID: "user_1",
-						Elo:    defaultElo - 10,
+						UserID:     "user_1",
+						TeamID:     "team_1",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_2", "user_3"},
+						Result:     enum.BattleResultLose,
+						Placement:  2,
 					},
 					{
-						UserID: "user_2",
-						Elo:    defaultElo + 10,
+						UserID:     "user_2",
+						TeamID:     "team_2",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo + 10,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_1", "user_3"},
+						Result:     enum.BattleResultWin,
+						Placement:  1,
 					},
+					{
+						UserID:     "user_3",
+						TeamID:     "team_3",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo - 10,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_1", "user_2"},
+						Result:     enum.BattleResultLose,
+						Placement:  3,
+					},
+				},
+			},
+			err:     nil,
+			wantErr: false,
+			ApplyBattleArgs: &ApplyBattleArgs{
+				userIDs: []string{"user_1", "user_2", "user_3"},
+				userElos: []*entity.UserElo{
+					entity.NewUserDefaultElo("user_1"),
+					entity.NewUserDefaultElo("user_2"),
+					entity.NewUserDefaultElo("user_3"),
 				},
 			},
-			BatchUpdateEloArgs: &BatchUpdateEloArgs{
+			ApplyBattleWant: &ApplyBattleWant{
 				newUserElos: []*entity.UserElo{
 					{
-						UserID: "user_1",
-						Elo:    defaultElo - 10,
+						UserID:      "user_1",
+						Elo:         defaultElo,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
 					},
 					{
-						UserID: "user_2",
-						Elo:    defaultElo + 10,
+						UserID:      "user_2",
+						Elo:         defaultElo + 10,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
+					},
+					{
+						UserID:      "user_3",
+						Elo:         defaultElo - 10,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
 					},
 				},
 			},
-			BatchUpdateEloWant: &BatchUpdateEloWant{
-				err: nil,
+		},
+		{
+			name: "Validator request form: members of teams are not unique",
+			args: args{
+				req: &v1.CreateRewardRequest{
+					Teams: []*entity.Team{
+						{ID: "team_1", Owner: "user_1", Members: []string{"user_2"}},
+						{ID: "team_2", Owner: "user_3", Members: []string{"user_2"}},
+					},
+					Winner: "user_1",
+				},
 			},
+			want:    nil,
+			err:     echo.NewHTTPError(http.StatusBadRequest, []string{"members of teams must be unique"}),
+			wantErr: true,
 		},
 		{
-			name: "Successful create reward: draw",
+			name: "Successful create reward: every team member is rewarded",
 			args: args{
 				req: &v1.CreateRewardRequest{
 					Teams: []*entity.Team{
-						{
-							ID:    "team_1",
-							Owner: "user_1",
-						},
-						{
-							ID:    "team_2",
-							Owner: "user_2",
-						},
+						{ID: "team_1", Owner: "user_1", Members: []string{"user_1", "user_2"}},
+						{ID: "team_2", Owner: "user_3", Members: []string{"user_4"}},
 					},
-					Winner: "draw",
+					Winner: "user_1",
 				},
 			},
 			want: &v1.CreateRewardResponse{
 				Items: []*v1.Reward{
 					{
-						UserID: "user_1",
-						OldElo: defaultElo,
-						NewElo: defaultElo + 5,
+						UserID:     "user_1",
+						TeamID:     "team_1",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo + 10,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_3", "user_4"},
+						Result:     enum.BattleResultWin,
+						Placement:  1,
 					},
 					{
-						UserID: "user_2",
-						OldElo: defaultElo,
-						NewElo: defaultElo + 5,
+						UserID:     "user_2",
+						TeamID:     "team_1",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo + 10,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_3", "user_4"},
+						Result:     enum.BattleResultWin,
+						Placement:  1,
+					},
+					{
+						UserID:     "user_3",
+						TeamID:     "team_2",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo - 10,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_1", "user_2"},
+						Result:     enum.BattleResultLose,
+						Placement:  2,
+					},
+					{
+						UserID:     "user_4",
+						TeamID:     "team_2",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo - 10,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_1", "user_2"},
+						Result:     enum.BattleResultLose,
+						Placement:  2,
 					},
 				},
 			},
 			err:     nil,
 			wantErr: false,
-			listUserElosArgs: &listUserElosArgs{
-				teams: []*entity.Team{
+			ApplyBattleArgs: &ApplyBattleArgs{
+				userIDs: []string{"user_1", "user_2", "user_3", "user_4"},
+				userElos: []*entity.UserElo{
+					entity.NewUserDefaultElo("user_1"),
+					entity.NewUserDefaultElo("user_2"),
+					entity.NewUserDefaultElo("user_3"),
+					entity.NewUserDefaultElo("user_4"),
+				},
+			},
+			ApplyBattleWant: &ApplyBattleWant{
+				newUserElos: []*entity.UserElo{
 					{
-						ID:    "team_1",
-						Owner: "user_1",
+						UserID:      "user_1",
+						Elo:         defaultElo + 10,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
 					},
 					{
-						ID:    "team_2",
-						Owner: "user_2",
+						UserID:      "user_2",
+						Elo:         defaultElo + 10,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
 					},
-				},
-			},
-			listUserElosWant: &listUserElosWant{
-				userElos: []*entity.UserElo{
 					{
-						UserID: "user_1",
-						Elo:    defaultElo,
+						UserID:      "user_3",
+						Elo:         defaultElo - 10,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
 					},
 					{
-						UserID: "user_2",
-						Elo:    defaultElo,
+						UserID:      "user_4",
+						Elo:         defaultElo - 10,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
 					},
 				},
 			},
-			calculateEloArgs: &calculateEloArgs{
-				userElos: []*entity.UserElo{
-					{
-						UserID: "user_1",
-						Elo:    defaultElo,
+		},
+		{
+			name: "Winner is not in the battle",
+			args: args{
+				req: &v1.CreateRewardRequest{
+					Teams: []*entity.Team{
+						{ID: "team_1", Owner: "user_1"},
+						{ID: "team_2", Owner: "user_2"},
 					},
-					{
-						UserID: "user_2",
-						Elo:    defaultElo,
+					Winner: "user_3",
+				},
+			},
+			want:    nil,
+			err:     echo.NewHTTPError(http.StatusBadRequest, []string{"winner user_3 is not in the battle"}),
+			wantErr: true,
+		},
+		{
+			name: "Winner named draw is not in the battle",
+			args: args{
+				req: &v1.CreateRewardRequest{
+					Teams: []*entity.Team{
+						{ID: "team_1", Owner: "user_1"},
+						{ID: "team_2", Owner: "user_2"},
 					},
+					Winner: "draw",
 				},
-				winnerIdx: 2,
 			},
-			calculateEloWant: &calculateEloWant{
-				newUserElos: []*entity.UserElo{
+			want:    nil,
+			err:     echo.NewHTTPError(http.StatusBadRequest, []string{"winner draw is not in the battle"}),
+			wantErr: true,
+		},
+		{
+			name: "Draw with a winner",
+			args: args{
+				req: &v1.CreateRewardRequest{
+					Teams: []*entity.Team{
+						{ID: "team_1", Owner: "user_1"},
+						{ID: "team_2", Owner: "user_2"},
+					},
+					Winner: "user_1",
+					Draw:   true,
+				},
+			},
+			want:    nil,
+			err:     echo.NewHTTPError(http.StatusBadRequest, []string{"winner of a draw must be empty"}),
+			wantErr: true,
+		},
+		{
+			name: "Results have more than one winner",
+			args: args{
+				req: &v1.CreateRewardRequest{
+					Teams: []*entity.Team{
+						{ID: "team_1", Owner: "user_1", Result: ptr(enum.BattleResultWin)},
+						{ID: "team_2", Owner: "user_2", Result: ptr(enum.BattleResultWin)},
+					},
+				},
+			},
+			want:    nil,
+			err:     echo.NewHTTPError(http.StatusBadRequest, []string{"results must have exactly one winner or all ties"}),
+			wantErr: true,
+		},
+		{
+			name: "Result of a team is missing",
+			args: args{
+				req: &v1.CreateRewardRequest{
+					Teams: []*entity.Team{
+						{ID: "team_1", Owner: "user_1", Result: ptr(enum.BattleResultWin)},
+						{ID: "team_2", Owner: "user_2"},
+					},
+				},
+			},
+			want:    nil,
+			err:     echo.NewHTTPError(http.StatusBadRequest, []string{"result of every team is required"}),
+			wantErr: true,
+		},
+		{
+			name: "Successful create reward: results of every team",
+			args: args{
+				req: &v1.CreateRewardRequest{
+					Teams: []*entity.Team{
+						{ID: "team_1", Owner: "user_1", Result: ptr(enum.BattleResultLose)},
+						{ID: "team_2", Owner: "user_2", Result: ptr(enum.BattleResultWin)},
+						{ID: "team_3", Owner: "user_3", Result: ptr(enum.BattleResultLose)},
+					},
+				},
+			},
+			want: &v1.CreateRewardResponse{
+				Items: []*v1.Reward{
+					{
+						UserID:     "user_1",
+						TeamID:     "team_1",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo - 5,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_2", "user_3"},
+						Result:     enum.BattleResultLose,
+						Placement:  2,
+					},
 					{
-						UserID: "user_1",
-						Elo:    defaultElo + 5,
+						UserID:     "user_2",
+						TeamID:     "team_2",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo + 10,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_1", "user_3"},
+						Result:     enum.BattleResultWin,
+						Placement:  1,
 					},
 					{
-						UserID: "user_2",
-						Elo:    defaultElo + 5,
+						UserID:     "user_3",
+						TeamID:     "team_3",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo - 5,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_1", "user_2"},
+						Result:     enum.BattleResultLose,
+						Placement:  2,
 					},
 				},
 			},
-			BatchUpdateEloArgs: &BatchUpdateEloArgs{
+			err:     nil,
+			wantErr: false,
+			ApplyBattleArgs: &ApplyBattleArgs{
+				userIDs: []string{"user_1", "user_2", "user_3"},
+				userElos: []*entity.UserElo{
+					entity.NewUserDefaultElo("user_1"),
+					entity.NewUserDefaultElo("user_2"),
+					entity.NewUserDefaultElo("user_3"),
+				},
+			},
+			ApplyBattleWant: &ApplyBattleWant{
 				newUserElos: []*entity.UserElo{
 					{
-						UserID: "user_1",
-						Elo:    defaultElo + 5,
+						UserID:      "user_1",
+						Elo:         defaultElo - 5,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
+					},
+					{
+						UserID:      "user_2",
+						Elo:         defaultElo + 10,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
 					},
 					{
-						UserID: "user_2",
-						Elo:    defaultElo + 5,
+						UserID:      "user_3",
+						Elo:         defaultElo - 5,
+						Deviation:   entity.DefaultDeviation,
+						Volatility:  entity.DefaultVolatility,
+						GamesPlayed: 1,
 					},
 				},
 			},
-			BatchUpdateEloWant: &BatchUpdateEloWant{
-				err: nil,
-			},
 		},
 		{
-			name: "Failed to get user elo",
+			name: "Replay processed battle: stored reward is returned",
 			args: args{
 				req: &v1.CreateRewardRequest{
 					Teams: []*entity.Team{
@@ -413,31 +757,72 @@ func TestRewardService_CreateReward(t *testing.T) {
 							Owner: "user_2",
 						},
 					},
-					Winner: "draw",
+					Winner: "user_1",
 				},
 			},
-			want:    nil,
-			err:     echo.ErrInternalServerError,
-			wantErr: true,
-			listUserElosArgs: &listUserElosArgs{
-				teams: []*entity.Team{
+			want: &v1.CreateRewardResponse{
+				Items: []*v1.Reward{
 					{
-						ID:    "team_1",
-						Owner: "user_1",
+						UserID:     "user_1",
+						TeamID:     "team_1",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo + 10,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_2"},
+						Result:     enum.BattleResultWin,
+						Placement:  1,
 					},
 					{
-						ID:    "team_2",
-						Owner: "user_2",
+						UserID:     "user_2",
+						TeamID:     "team_2",
+						OldElo:     defaultElo,
+						NewElo:     defaultElo - 10,
+						Deviation:  entity.DefaultDeviation,
+						Volatility: entity.DefaultVolatility,
+						Opponents:  []string{"user_1"},
+						Result:     enum.BattleResultLose,
+						Placement:  2,
 					},
 				},
 			},
-			listUserElosWant: &listUserElosWant{
-				userElos: nil,
-				err:      echo.ErrInternalServerError,
+			err:     nil,
+			wantErr: false,
+			ApplyBattleArgs: &ApplyBattleArgs{
+				userIDs: []string{"user_1", "user_2"},
+			},
+			ApplyBattleWant: &ApplyBattleWant{
+				storedBattle: &entity.Battle{
+					ID: battleID,
+					Rewards: []*entity.Reward{
+						{
+							UserID:     "user_1",
+							TeamID:     "team_1",
+							OldElo:     defaultElo,
+							NewElo:     defaultElo + 10,
+							Deviation:  entity.DefaultDeviation,
+							Volatility: entity.DefaultVolatility,
+							Opponents:  []string{"user_2"},
+							Result:     enum.BattleResultWin,
+							Placement:  1,
+						},
+						{
+							UserID:     "user_2",
+							TeamID:     "team_2",
+							OldElo:     defaultElo,
+							NewElo:     defaultElo - 10,
+							Deviation:  entity.DefaultDeviation,
+							Volatility: entity.DefaultVolatility,
+							Opponents:  []string{"user_1"},
+							Result:     enum.BattleResultLose,
+							Placement:  2,
+						},
+					},
+				},
 			},
 		},
 		{
-			name: "Failed to batch update elo",
+			name: "Failed to apply battle",
 			args: args{
 				req: &v1.CreateRewardRequest{
 					Teams: []*entity.Team{
@@ -456,69 +841,38 @@ func TestRewardService_CreateReward(t *testing.T) {
 			want:    nil,
 			err:     echo.ErrInternalServerError,
 			wantErr: true,
-			listUserElosArgs: &listUserElosArgs{
-				teams: []*entity.Team{
-					{
-						ID:    "team_1",
-						Owner: "user_1",
-					},
-					{
-						ID:    "team_2",
-						Owner: "user_2",
-					},
-				},
+			ApplyBattleArgs: &ApplyBattleArgs{
+				userIDs: []string{"user_1", "user_2"},
 			},
-			listUserElosWant: &listUserElosWant{
-				userElos: []*entity.UserElo{
-					{
-						UserID: "user_1",
-						Elo:    defaultElo,
-					},
-					{
-						UserID: "user_2",
-						Elo:    defaultElo,
-					},
-				},
+			ApplyBattleWant: &ApplyBattleWant{
+				err: echo.ErrInternalServerError,
 			},
-			calculateEloArgs: &calculateEloArgs{
-				userElos: []*entity.UserElo{
-					{
-						UserID: "user_1",
-						Elo:    defaultElo,
-					},
-					{
-						UserID: "user_2",
-						Elo:    defaultElo,
+		},
+		{
+			name: "Conflict with concurrent battles",
+			args: args{
+				req: &v1.CreateRewardRequest{
+					Teams: []*entity.Team{
+						{
+							ID:    "team_1",
+							Owner: "user_1",
+						},
+						{
+							ID:    "team_2",
+							Owner: "user_2",
+						},
 					},
+					Winner: "user_1",
 				},
-				winnerIdx: 2,
 			},
-			calculateEloWant: &calculateEloWant{
-				newUserElos: []*entity.UserElo{
-					{
-						UserID: "user_1",
-						Elo:    defaultElo + 10,
-					},
-					{
-						UserID: "user_2",
-						Elo:    defaultElo - 10,
-					},
-				},
+			want:    nil,
+			err:     echo.NewHTTPError(http.StatusConflict, repo.ErrConflict.Error()),
+			wantErr: true,
+			ApplyBattleArgs: &ApplyBattleArgs{
+				userIDs: []string{"user_1", "user_2"},
 			},
-			BatchUpdateEloArgs: &BatchUpdateEloArgs{
-				newUserElos: []*entity.UserElo{
-					{
-						UserID: "user_1",
-						Elo:    defaultElo + 10,
-					},
-					{
-						UserID: "user_2",
-						Elo:    defaultElo - 10,
-					},
-				},
-			},
-			BatchUpdateEloWant: &BatchUpdateEloWant{
-				err: echo.ErrInternalServerError,
+			ApplyBattleWant: &ApplyBattleWant{
+				err: repo.ErrConflict,
 			},
 		},
 	}
@@ -534,6 +888,8 @@ func TestRewardService_CreateReward(t *testing.T) {
 				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 			}
 			tt.args.ctx = e.NewContext(req, rec)
+			tt.args.ctx.SetParamNames("battle_id")
+			tt.args.ctx.SetParamValues(battleID)
 		}
 
 		validate := validator.New()
@@ -548,30 +904,28 @@ func TestRewardService_CreateReward(t *testing.T) {
 		e.Validator = &routes.Validator{Validator: validate}
 
 		t.Run(tt.name, func(t *testing.T) {
-			redisRepo := &mock.RedisRepo{}
-			svcMock := &mock.RewardService{}
+			ratingRepo := &mock.RatingRepo{}
 			svc := &RewardService{
-				redisRepo: redisRepo,
+				ratingRepo:     ratingRepo,
+				teamCalculator: rating.NewTeamCalculator(rating.NewElo(20), rating.AggregateAverage),
 			}
 
-			if tt.listUserElosArgs != nil && tt.listUserElosWant != nil {
-				for idx, team := range tt.listUserElosArgs.teams {
-					userElo := entity.NewUserDefaultElo(team.Owner)
-					redisRepo.On("GetUserElo", ctx, team.Owner).Return(userElo, tt.listUserElosWant.err)
-					if tt.listUserElosWant.err == nil {
-						tt.listUserElosWant.userElos[idx] = userElo
-					}
-				}
-			}
+			var newUserElos []*entity.UserElo
+			if tt.ApplyBattleArgs != nil && tt.ApplyBattleWant != nil {
+				ratingRepo.On("ApplyBattle", ctx, battleID, tt.ApplyBattleArgs.userIDs, tmock.Anything).
+					Return(func(_ context.Context, _ string, _ []string, fn repo.BattleFunc) (*entity.Battle, error) {
+						if tt.ApplyBattleWant.err != nil {
+							return nil, tt.ApplyBattleWant.err
+						}
 
-			if tt.calculateEloArgs != nil && tt.calculateEloWant != nil {
-				svcMock.On("calculateElo", tt.args.ctx, tt.calculateEloArgs.userElos, tt.calculateEloArgs.winnerIdx).
-					Return(tt.calculateEloWant.newUserElos)
-			}
+						if tt.ApplyBattleWant.storedBattle != nil {
+							return tt.ApplyBattleWant.storedBattle, nil
+						}
 
-			if tt.BatchUpdateEloArgs != nil && tt.BatchUpdateEloWant != nil {
-				redisRepo.On("BatchUpdateElo", ctx, tt.BatchUpdateEloArgs.newUserElos).
-					Return(tt.BatchUpdateEloWant.err)
+						battle, elos, err := fn(tt.ApplyBattleArgs.userElos)
+						newUserElos = elos
+						return battle, err
+					})
 			}
 
 			beforeTime := time.Now().Unix()
@@ -583,15 +937,21 @@ func TestRewardService_CreateReward(t *testing.T) {
 				assert.NoError(t, err)
 			}
 
+			if tt.ApplyBattleWant != nil && tt.ApplyBattleWant.newUserElos != nil {
+				assert.Equal(t, tt.ApplyBattleWant.newUserElos, newUserElos)
+			}
+
 			if tt.want != nil {
 				var resp v1.CreateRewardResponse
 				err = json.Unmarshal(rec.Body.Bytes(), &resp)
 				assert.NoError(t, err)
 				wantMarshal, err := json.Marshal(tt.want)
 				assert.NoError(t, err)
-				// Verify timestamp is within reasonable range
-				assert.True(t, resp.Items[0].UpdatedAt >= beforeTime)
-				assert.True(t, resp.Items[0].UpdatedAt <= afterTime)
+				// Verify timestamp is within reasonable range, replayed rewards keep their original timestamp
+				if tt.ApplyBattleWant.storedBattle == nil {
+					assert.True(t, resp.Items[0].UpdatedAt >= beforeTime)
+					assert.True(t, resp.Items[0].UpdatedAt <= afterTime)
+				}
 
 				// Remove time field in response to compare
 				for _, item := range resp.Items {
@@ -606,246 +966,461 @@ func TestRewardService_CreateReward(t *testing.T) {
 	}
 }
 
-func TestRewardService_calculateElo(t *testing.T) {
-	type args struct {
-		ctx       context.Context
-		userElos  []*entity.UserElo
-		winnerIdx int
+func TestRewardService_CreateReward_Metrics(t *testing.T) {
+	userElos := []*entity.UserElo{entity.NewUserDefaultElo("user_1"), entity.NewUserDefaultElo("user_2")}
+	tests := []struct {
+		name        string
+		applyBattle func(fn repo.BattleFunc) (*entity.Battle, error)
+		wantRewards float64
+	}{
+		{
+			name: "Battle stored by the call is observed",
+			applyBattle: func(fn repo.BattleFunc) (*entity.Battle, error) {
+				battle, _, err := fn(userElos)
+				return battle, err
+			},
+			wantRewards: 2,
+		},
+		{
+			name: "Battle stored by a concurrent retry after a conflict is not observed",
+			applyBattle: func(fn repo.BattleFunc) (*entity.Battle, error) {
+				battle, _, err := fn(userElos)
+				if err != nil {
+					return nil, err
+				}
+
+				storedBattle := *battle
+				return &storedBattle, nil
+			},
+			wantRewards: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			ratingMetrics, err := metrics.NewRatingMetrics(reg)
+			require.NoError(t, err)
+
+			ratingRepo := &mock.RatingRepo{}
+			ratingRepo.On("ApplyBattle", tmock.Anything, "battle_1", []string{"user_1", "user_2"}, tmock.Anything).
+				Return(func(_ context.Context, _ string, _ []string, fn repo.BattleFunc) (*entity.Battle, error) {
+					return tt.applyBattle(fn)
+				})
+			svc := &RewardService{
+				ratingRepo:     ratingRepo,
+				teamCalculator: rating.NewTeamCalculator(rating.NewElo(20), rating.AggregateAverage),
+				ratingMetrics:  ratingMetrics,
+			}
+
+			e := echo.New()
+			e.Validator = routes.NewValidator()
+			marshalled, err := json.Marshal(&v1.CreateRewardRequest{
+				Winner: "user_1",
+				Teams:  []*entity.Team{{ID: "team_1", Owner: "user_1"}, {ID: "team_2", Owner: "user_2"}},
+			})
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(marshalled))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			c := e.NewContext(req, httptest.NewRecorder())
+			c.SetParamNames("battle_id")
+			c.SetParamValues("battle_1")
+
+			require.NoError(t, svc.CreateReward(c))
+			assert.Equal(t, tt.wantRewards, createdRewards(t, reg))
+		})
 	}
+}
+
+// createdRewards returns the count of created rewards gathered from reg.
+func createdRewards(t *testing.T, reg *prometheus.Registry) float64 {
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() == "rating_rewards_created_total" {
+			return family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+
+	return 0
+}
+
+func TestRewardService_DeleteReward(t *testing.T) {
 	tests := []struct {
-		name string
-		args args
-		want []*entity.UserElo
+		name       string
+		battleID   string
+		want       *v1.DeleteRewardResponse
+		err        error
+		wantErr    bool
+		setupMocks func(repo *mock.RatingRepo)
 	}{
 		{
-			name: "Winner index 0 - tie case",
-			args: args{
-				userElos: []*entity.UserElo{
-					{UserID: "user_1", Elo: 1000},
-					{UserID: "user_2", Elo: 1200},
+			name:     "Successful delete reward",
+			battleID: "battle_1",
+			want: &v1.DeleteRewardResponse{
+				Items: []*v1.Reward{
+					{UserID: "user_1", OldElo: 1030, NewElo: 1020, Result: enum.BattleResultWin, UpdatedAt: 100},
+					{UserID: "user_2", OldElo: 980, NewElo: 990, Result: enum.BattleResultLose, UpdatedAt: 100},
 				},
-				winnerIdx: 0,
 			},
-			want: []*entity.UserElo{
-				{UserID: "user_1", Elo: 1005},
-				{UserID: "user_2", Elo: 1205},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("VoidBattle", tmock.Anything, "battle_1", tmock.Anything).
+					Return(&entity.Battle{
+						ID: "battle_1",
+						Reversals: []*entity.Reward{
+							{UserID: "user_1", OldElo: 1030, NewElo: 1020, Result: enum.BattleResultWin, UpdatedAt: 100},
+							{UserID: "user_2", OldElo: 980, NewElo: 990, Result: enum.BattleResultLose, UpdatedAt: 100},
+						},
+						VoidedAt: 100,
+					}, nil)
 			},
 		},
 		{
-			name: "Winner index 1 - player 0 wins",
-			args: args{
-				userElos: []*entity.UserElo{
-					{UserID: "user_1", Elo: 1000},
-					{UserID: "user_2", Elo: 1200},
-				},
-				winnerIdx: 1,
+			name:       "Validator request form: battle id is required",
+			err:        echo.NewHTTPError(http.StatusBadRequest, []string{"battle_id is required"}),
+			wantErr:    true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {},
+		},
+		{
+			name:     "Battle is unknown",
+			battleID: "battle_x",
+			err:      echo.NewHTTPError(http.StatusNotFound, "battle is not found"),
+			wantErr:  true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("VoidBattle", tmock.Anything, "battle_x", tmock.Anything).Return(nil, repo.ErrNotFound)
 			},
-			want: []*entity.UserElo{
-				{UserID: "user_1", Elo: 1010},
-				{UserID: "user_2", Elo: 1190},
+		},
+		{
+			name:     "Battle is already voided",
+			battleID: "battle_1",
+			err:      echo.NewHTTPError(http.StatusConflict, "battle is already voided"),
+			wantErr:  true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("VoidBattle", tmock.Anything, "battle_1", tmock.Anything).Return(nil, repo.ErrVoided)
 			},
 		},
 		{
-			name: "Winner index 2 - player 1 wins",
-			args: args{
-				userElos: []*entity.UserElo{
-					{UserID: "user_1", Elo: 1000},
-					{UserID: "user_2", Elo: 1200},
-				},
-				winnerIdx: 2,
+			name:     "Battle belongs to an ended season",
+			battleID: "battle_1",
+			err:      echo.NewHTTPError(http.StatusConflict, "battle belongs to an ended season"),
+			wantErr:  true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("VoidBattle", tmock.Anything, "battle_1", tmock.Anything).Return(nil, repo.ErrSeasonEnded)
 			},
-			want: []*entity.UserElo{
-				{UserID: "user_1", Elo: 990},
-				{UserID: "user_2", Elo: 1210},
+		},
+		{
+			name:     "Conflict with concurrent battles",
+			battleID: "battle_1",
+			err:      echo.NewHTTPError(http.StatusConflict, repo.ErrConflict.Error()),
+			wantErr:  true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("VoidBattle", tmock.Anything, "battle_1", tmock.Anything).Return(nil, repo.ErrConflict)
 			},
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratingRepo := &mock.RatingRepo{}
+			tt.setupMocks(ratingRepo)
+			svc := &RewardService{
+				ratingRepo: ratingRepo,
+			}
+
+			c, rec := newTestContext(http.MethodDelete, "/", nil, map[string]string{"battle_id": tt.battleID})
+			err := svc.DeleteReward(c)
+			if tt.wantErr {
+				assert.Equal(t, tt.err.Error(), err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			var resp v1.DeleteRewardResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.want, &resp)
+		})
+	}
+}
+
+func TestRewardService_PreviewBattle(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *v1.PreviewBattleRequest
+		want       *v1.PreviewBattleResponse
+		err        error
+		wantErr    bool
+		setupMocks func(repo *mock.RatingRepo)
+	}{
 		{
-			name: "Zero Elo values",
-			args: args{
-				userElos: []*entity.UserElo{
-					{UserID: "user_1", Elo: 0},
-					{UserID: "user_2", Elo: 0},
+			name: "Successful preview battle",
+			req: &v1.PreviewBattleRequest{
+				Teams: []*entity.Team{
+					{ID: "team_1", Owner: "user_1"},
+					{ID: "team_2", Owner: "user_2"},
 				},
-				winnerIdx: 1,
 			},
-			want: []*entity.UserElo{
-				{UserID: "user_1", Elo: 10},
-				{UserID: "user_2", Elo: -10},
+			want: &v1.PreviewBattleResponse{
+				Teams: []*v1.TeamPreview{
+					{
+						TeamID:         "team_1",
+						WinProbability: rating.ExpectedScore(1200, 1000),
+						Members:        []*v1.MemberPreview{{UserID: "user_1", Elo: 1200, Win: 5, Draw: -5, Lose: -15}},
+					},
+					{
+						TeamID:         "team_2",
+						WinProbability: rating.ExpectedScore(1000, 1200),
+						Members:        []*v1.MemberPreview{{UserID: "user_2", Elo: 1000, Win: 15, Draw: 5, Lose: -5}},
+					},
+				},
+			},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("ListUserElos", tmock.Anything, []string{"user_1", "user_2"}).
+					Return([]*entity.UserElo{{UserID: "user_1", Elo: 1200}, {UserID: "user_2", Elo: 1000}}, nil)
 			},
 		},
 		{
-			name: "Negative Elo values",
-			args: args{
-				userElos: []*entity.UserElo{
-					{UserID: "user_1", Elo: -50},
-					{UserID: "user_2", Elo: -30},
+			name: "Successful preview battle: every team member is previewed",
+			req: &v1.PreviewBattleRequest{
+				Teams: []*entity.Team{
+					{ID: "team_1", Owner: "user_1", Members: []string{"user_2"}},
+					{ID: "team_2", Owner: "user_3", Members: []string{"user_4"}},
+				},
+			},
+			want: &v1.PreviewBattleResponse{
+				Teams: []*v1.TeamPreview{
+					{
+						TeamID:         "team_1",
+						WinProbability: 0.5,
+						Members: []*v1.MemberPreview{
+							{UserID: "user_1", Elo: 1100, Win: 10, Draw: 0, Lose: -10},
+							{UserID: "user_2", Elo: 900, Win: 10, Draw: 0, Lose: -10},
+						},
+					},
+					{
+						TeamID:         "team_2",
+						WinProbability: 0.5,
+						Members: []*v1.MemberPreview{
+							{UserID: "user_3", Elo: 1000, Win: 10, Draw: 0, Lose: -10},
+							{UserID: "user_4", Elo: 1000, Win: 10, Draw: 0, Lose: -10},
+						},
+					},
 				},
-				winnerIdx: 2,
 			},
-			want: []*entity.UserElo{
-				{UserID: "user_1", Elo: -60},
-				{UserID: "user_2", Elo: -20},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("ListUserElos", tmock.Anything, []string{"user_1", "user_2", "user_3", "user_4"}).
+					Return([]*entity.UserElo{
+						{UserID: "user_1", Elo: 1100},
+						{UserID: "user_2", Elo: 900},
+						{UserID: "user_3", Elo: 1000},
+						{UserID: "user_4", Elo: 1000},
+					}, nil)
 			},
 		},
 		{
-			name: "Large Elo values",
-			args: args{
-				userElos: []*entity.UserElo{
-					{UserID: "user_1", Elo: 2500},
-					{UserID: "user_2", Elo: 2800},
-				},
-				winnerIdx: 0,
+			name: "Validator request form: teams is less than 2",
+			req: &v1.PreviewBattleRequest{
+				Teams: []*entity.Team{{ID: "team_1", Owner: "user_1"}},
 			},
-			want: []*entity.UserElo{
-				{UserID: "user_1", Elo: 2505},
-				{UserID: "user_2", Elo: 2805},
+			err:        echo.NewHTTPError(http.StatusBadRequest, []string{"teams must be greater than or equals to 2"}),
+			wantErr:    true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {},
+		},
+		{
+			name: "Validator request form: members of teams are not unique",
+			req: &v1.PreviewBattleRequest{
+				Teams: []*entity.Team{
+					{ID: "team_1", Owner: "user_1", Members: []string{"user_2"}},
+					{ID: "team_2", Owner: "user_2"},
+				},
 			},
+			err:        echo.NewHTTPError(http.StatusBadRequest, []string{"members of teams must be unique"}),
+			wantErr:    true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {},
 		},
 		{
-			name: "Invalid negative index",
-			args: args{
-				userElos: []*entity.UserElo{
-					{UserID: "user_1", Elo: 1000},
-					{UserID: "user_2", Elo: 1200},
+			name: "Failed to list user elos",
+			req: &v1.PreviewBattleRequest{
+				Teams: []*entity.Team{
+					{ID: "team_1", Owner: "user_1"},
+					{ID: "team_2", Owner: "user_2"},
 				},
-				winnerIdx: -1,
 			},
-			want: []*entity.UserElo{
-				{UserID: "user_1", Elo: 1000},
-				{UserID: "user_2", Elo: 1200},
+			err:     echo.ErrInternalServerError,
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("ListUserElos", tmock.Anything, tmock.Anything).Return(nil, echo.ErrInternalServerError)
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			svc := &RewardService{}
-			res := svc.calculateElo(tt.args.ctx, tt.args.userElos, tt.args.winnerIdx)
-			if tt.want != nil {
-				assert.Equal(t, tt.want, res)
+			ratingRepo := &mock.RatingRepo{}
+			tt.setupMocks(ratingRepo)
+			svc := &RewardService{
+				ratingRepo:     ratingRepo,
+				teamCalculator: rating.NewTeamCalculator(rating.NewElo(20), rating.AggregateAverage),
+			}
+
+			c, rec := newTestContext(http.MethodPost, "/v1/battle/preview", tt.req, nil)
+			err := svc.PreviewBattle(c)
+			if tt.wantErr {
+				assert.Equal(t, tt.err.Error(), err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			var resp v1.PreviewBattleResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			// Remove the rounding error of the win probability to compare
+			for idx, team := range resp.Teams {
+				assert.InDelta(t, tt.want.Teams[idx].WinProbability, team.WinProbability, 0.0001)
+				team.WinProbability = tt.want.Teams[idx].WinProbability
 			}
+			assert.Equal(t, tt.want, &resp)
+			// Nothing is stored by a preview.
+			ratingRepo.AssertNotCalled(t, "ApplyBattle", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything)
 		})
 	}
 }
 
-func TestRewardService_listUserElos(t *testing.T) {
+func TestRewardService_calculateElo(t *testing.T) {
 	type args struct {
-		ctx   context.Context
-		teams []*entity.Team
+		ctx        context.Context
+		teams      [][]*entity.UserElo
+		placements []int
 	}
 	tests := []struct {
-		name       string
-		args       args
-		err        error
-		want       []*entity.UserElo
-		wantErr    bool
-		setupMocks func(repo *mock.RedisRepo)
+		name string
+		args args
+		want [][]*entity.UserElo
 	}{
 		{
-			name: "successful retrieval of user elos",
+			name: "Same placement - tie case",
 			args: args{
-				teams: []*entity.Team{
-					{Owner: "user_1"},
-					{Owner: "user_2"},
+				teams: [][]*entity.UserElo{
+					{{UserID: "user_1", Elo: 1000}},
+					{{UserID: "user_2", Elo: 1200}},
 				},
+				placements: []int{1, 1},
 			},
-			want: []*entity.UserElo{
-				{UserID: "user_1", Elo: 1000},
-				{UserID: "user_2", Elo: 1000},
+			want: [][]*entity.UserElo{
+				{{UserID: "user_1", Elo: 1005}},
+				{{UserID: "user_2", Elo: 1195}},
 			},
-			err:     nil,
-			wantErr: false,
-			setupMocks: func(mockRepo *mock.RedisRepo) {
-				mockRepo.On("GetUserElo", tmock.Anything, "user_1").Return(&entity.UserElo{UserID: "user_1", Elo: 1000}, nil)
-				mockRepo.On("GetUserElo", tmock.Anything, "user_2").Return(&entity.UserElo{UserID: "user_2", Elo: 1000}, nil)
+		},
+		{
+			name: "Player 0 placed first",
+			args: args{
+				teams: [][]*entity.UserElo{
+					{{UserID: "user_1", Elo: 1000}},
+					{{UserID: "user_2", Elo: 1200}},
+				},
+				placements: []int{1, 2},
+			},
+			want: [][]*entity.UserElo{
+				{{UserID: "user_1", Elo: 1015}},
+				{{UserID: "user_2", Elo: 1185}},
 			},
 		},
 		{
-			name: "single team",
+			name: "Player 1 placed first",
 			args: args{
-				teams: []*entity.Team{
-					{Owner: "user_1"},
+				teams: [][]*entity.UserElo{
+					{{UserID: "user_1", Elo: 1000}},
+					{{UserID: "user_2", Elo: 1200}},
 				},
+				placements: []int{2, 1},
 			},
-			setupMocks: func(mockRepo *mock.RedisRepo) {
-				mockRepo.On("GetUserElo", tmock.Anything, "user_1").Return(&entity.UserElo{UserID: "user_1", Elo: 1000}, nil)
+			want: [][]*entity.UserElo{
+				{{UserID: "user_1", Elo: 995}},
+				{{UserID: "user_2", Elo: 1205}},
 			},
-			want: []*entity.UserElo{
-				{UserID: "user_1", Elo: 1000},
+		},
+		{
+			name: "Zero Elo values",
+			args: args{
+				teams: [][]*entity.UserElo{
+					{{UserID: "user_1", Elo: 0}},
+					{{UserID: "user_2", Elo: 0}},
+				},
+				placements: []int{1, 2},
+			},
+			want: [][]*entity.UserElo{
+				{{UserID: "user_1", Elo: 10}},
+				{{UserID: "user_2", Elo: -10}},
 			},
-			err:     nil,
-			wantErr: false,
 		},
 		{
-			name: "empty teams slice",
+			name: "Negative Elo values",
 			args: args{
-				teams: []*entity.Team{},
+				teams: [][]*entity.UserElo{
+					{{UserID: "user_1", Elo: -50}},
+					{{UserID: "user_2", Elo: -30}},
+				},
+				placements: []int{2, 1},
+			},
+			want: [][]*entity.UserElo{
+				{{UserID: "user_1", Elo: -59}},
+				{{UserID: "user_2", Elo: -21}},
 			},
-			want:       nil,
-			err:        nil,
-			wantErr:    false,
-			setupMocks: func(mockRepo *mock.RedisRepo) {},
 		},
 		{
-			name: "nil teams slice",
+			name: "Large Elo values",
 			args: args{
-				teams: nil,
+				teams: [][]*entity.UserElo{
+					{{UserID: "user_1", Elo: 2500}},
+					{{UserID: "user_2", Elo: 2800}},
+				},
+				placements: []int{1, 1},
+			},
+			want: [][]*entity.UserElo{
+				{{UserID: "user_1", Elo: 2507}},
+				{{UserID: "user_2", Elo: 2793}},
 			},
-			want:       nil,
-			err:        nil,
-			wantErr:    false,
-			setupMocks: func(mockRepo *mock.RedisRepo) {},
 		},
 		{
-			name: "error from redis repository",
+			name: "Free-for-all with tied placements",
 			args: args{
-				teams: []*entity.Team{
-					{Owner: "user_1"},
-					{Owner: "user_2"},
+				teams: [][]*entity.UserElo{
+					{{UserID: "user_1", Elo: 1000}},
+					{{UserID: "user_2", Elo: 1000}},
+					{{UserID: "user_3", Elo: 1000}},
+					{{UserID: "user_4", Elo: 1000}},
 				},
+				placements: []int{1, 2, 2, 4},
 			},
-			setupMocks: func(mockRepo *mock.RedisRepo) {
-				mockRepo.On("GetUserElo", tmock.Anything, "user_1").Return(&entity.UserElo{UserID: "user_1", Elo: 1000}, nil)
-				mockRepo.On("GetUserElo", tmock.Anything, "user_2").Return(nil, errors.New("redis connection failed"))
+			want: [][]*entity.UserElo{
+				{{UserID: "user_1", Elo: 1009}},
+				{{UserID: "user_2", Elo: 1000}},
+				{{UserID: "user_3", Elo: 1000}},
+				{{UserID: "user_4", Elo: 991}},
 			},
-			want:    nil,
-			err:     errors.New("redis connection failed"),
-			wantErr: true,
 		},
 		{
-			name: "error on first user",
+			name: "Teams get the rating change of their average rating",
 			args: args{
-				teams: []*entity.Team{
-					{Owner: "user_1"},
-					{Owner: "user_2"},
+				teams: [][]*entity.UserElo{
+					{{UserID: "user_1", Elo: 1000}, {UserID: "user_2", Elo: 1200}},
+					{{UserID: "user_3", Elo: 1100}, {UserID: "user_4", Elo: 1100}},
 				},
+				placements: []int{1, 2},
 			},
-			setupMocks: func(mockRepo *mock.RedisRepo) {
-				mockRepo.On("GetUserElo", tmock.Anything, "user_1").Return(nil, errors.New("user not found"))
+			want: [][]*entity.UserElo{
+				{{UserID: "user_1", Elo: 1010}, {UserID: "user_2", Elo: 1210}},
+				{{UserID: "user_3", Elo: 1090}, {UserID: "user_4", Elo: 1090}},
 			},
-			want:    nil,
-			err:     errors.New("user not found"),
-			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
-		ctx := context.Background()
 		t.Run(tt.name, func(t *testing.T) {
-			redisRepo := &mock.RedisRepo{}
-			tt.setupMocks(redisRepo)
 			svc := &RewardService{
-				redisRepo: redisRepo,
+				teamCalculator: rating.NewTeamCalculator(rating.NewElo(20), rating.AggregateAverage),
 			}
-			rec, err := svc.listUserElos(ctx, tt.args.teams)
-			if tt.wantErr {
-				assert.Equal(t, tt.err.Error(), err.Error())
-			} else {
-				assert.NoError(t, err)
-			}
-
+			res := svc.calculateElo(tt.args.ctx, tt.args.teams, tt.args.placements)
 			if tt.want != nil {
-				assert.Equal(t, tt.want, rec)
+				assert.Equal(t, tt.want, res)
 			}
 		})
 	}
@@ -0,0 +1,159 @@
+package v1impl
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	v1 "github.com/me0den/example-service/app/api/v1"
+	"github.com/me0den/example-service/domain/rating"
+	"github.com/me0den/example-service/domain/repo"
+)
+
+// SeasonService implements all use cases of season service.
+type SeasonService struct {
+	ratingRepo repo.RatingRepo
+	softReset  *rating.SoftReset
+}
+
+// NewSeasonService creates and returns new instance of SeasonService.
+func NewSeasonService(
+	ratingRepo repo.RatingRepo,
+	softReset *rating.SoftReset,
+) v1.SeasonService {
+	svc := &SeasonService{
+		ratingRepo: ratingRepo,
+		softReset:  softReset,
+	}
+
+	return svc
+}
+
+// ListSeasons to list the current season and the ended seasons, latest ended first.
+func (s *SeasonService) ListSeasons(c echo.Context) error {
+	ctx := c.Request().Context()
+	current, err := s.ratingRepo.GetCurrentSeason(ctx)
+	if err != nil {
+		return err
+	}
+
+	seasons, err := s.ratingRepo.ListSeasons(ctx)
+	if err != nil {
+		return err
+	}
+
+	res := &v1.ListSeasonsResponse{
+		Current: current,
+		Items:   seasons,
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// ListSeasonLeaderboard to list a page of the standings of a season ordered by descending elo.
+func (s *SeasonService) ListSeasonLeaderboard(c echo.Context) error {
+	req := new(v1.ListSeasonLeaderboardRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	season, err := s.ratingRepo.GetSeason(ctx, req.SeasonID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "season is not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	userRanks, err := s.ratingRepo.ListSeasonUserRanks(ctx, season.ID, req.GetOffset(), req.GetSize())
+	if err != nil {
+		return err
+	}
+
+	res := &v1.ListSeasonLeaderboardResponse{
+		Season: season,
+		Page:   req.GetPage(),
+		Size:   req.GetSize(),
+		Items:  userRanks,
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetSeasonUserRank to get the standing of a user in a season.
+func (s *SeasonService) GetSeasonUserRank(c echo.Context) error {
+	req := new(v1.GetSeasonUserRankRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	season, err := s.ratingRepo.GetSeason(ctx, req.SeasonID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "season is not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	userRank, err := s.ratingRepo.GetSeasonUserRank(ctx, season.ID, req.UserID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "user is not ranked")
+	}
+	if err != nil {
+		return err
+	}
+
+	res := &v1.GetSeasonUserRankResponse{
+		Season: season,
+		User:   userRank,
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// RolloverSeason to end the current season and start a new one with soft reset elos of every user.
+func (s *SeasonService) RolloverSeason(c echo.Context) error {
+	endedAt := time.Now().Unix()
+	req := new(v1.RolloverSeasonRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	ended, err := s.ratingRepo.RolloverSeason(ctx, req.SeasonID, endedAt, s.softReset.Reset)
+	if errors.Is(err, repo.ErrAlreadyExists) {
+		return echo.NewHTTPError(http.StatusConflict, "season already exists")
+	}
+	if errors.Is(err, repo.ErrUnnamedSeason) {
+		return echo.NewHTTPError(http.StatusConflict, "current season has no id, season.id must be configured before the first rollover")
+	}
+	if errors.Is(err, repo.ErrConflict) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	res := &v1.RolloverSeasonResponse{
+		Ended:   ended,
+		Current: &v1.Season{ID: req.SeasonID},
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,241 @@
+package v1impl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+
+	v1 "github.com/me0den/example-service/app/api/v1"
+	"github.com/me0den/example-service/app/api/v1/v1impl/mock"
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/rating"
+	"github.com/me0den/example-service/domain/repo"
+)
+
+func TestSeasonService_ListSeasonLeaderboard(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		seasonID   string
+		want       *v1.ListSeasonLeaderboardResponse
+		err        error
+		wantErr    bool
+		setupMocks func(repo *mock.RatingRepo)
+	}{
+		{
+			name:     "Standings of an ended season",
+			target:   "/v1/seasons/2026-09/leaderboard?size=2",
+			seasonID: "2026-09",
+			want: &v1.ListSeasonLeaderboardResponse{
+				Season: &v1.Season{ID: "2026-09", EndedAt: 100},
+				Page:   1,
+				Size:   2,
+				Items: []*v1.UserRank{
+					{UserID: "user_1", Elo: 1400, Rank: 1},
+					{UserID: "user_2", Elo: 900, Rank: 2},
+				},
+			},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetSeason", tmock.Anything, "2026-09").
+					Return(&entity.Season{ID: "2026-09", EndedAt: 100}, nil)
+				mockRepo.On("ListSeasonUserRanks", tmock.Anything, "2026-09", int64(0), int64(2)).
+					Return([]*entity.UserRank{
+						{UserID: "user_1", Elo: 1400, Rank: 1},
+						{UserID: "user_2", Elo: 900, Rank: 2},
+					}, nil)
+			},
+		},
+		{
+			name:     "Unknown season",
+			target:   "/v1/seasons/2026-08/leaderboard",
+			seasonID: "2026-08",
+			err:      echo.NewHTTPError(http.StatusNotFound, "season is not found"),
+			wantErr:  true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetSeason", tmock.Anything, "2026-08").Return(nil, repo.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratingRepo := &mock.RatingRepo{}
+			tt.setupMocks(ratingRepo)
+			svc := &SeasonService{
+				ratingRepo: ratingRepo,
+			}
+
+			c, rec := newTestContext(http.MethodGet, tt.target, nil, map[string]string{"season_id": tt.seasonID})
+			err := svc.ListSeasonLeaderboard(c)
+			if tt.wantErr {
+				assert.Equal(t, tt.err.Error(), err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			var resp v1.ListSeasonLeaderboardResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.want, &resp)
+		})
+	}
+}
+
+func TestSeasonService_GetSeasonUserRank(t *testing.T) {
+	tests := []struct {
+		name       string
+		seasonID   string
+		userID     string
+		want       *v1.GetSeasonUserRankResponse
+		err        error
+		wantErr    bool
+		setupMocks func(repo *mock.RatingRepo)
+	}{
+		{
+			name:     "Final standing of a user",
+			seasonID: "2026-09",
+			userID:   "user_1",
+			want: &v1.GetSeasonUserRankResponse{
+				Season: &v1.Season{ID: "2026-09", EndedAt: 100},
+				User:   &v1.UserRank{UserID: "user_1", Elo: 1400, Rank: 1},
+			},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetSeason", tmock.Anything, "2026-09").
+					Return(&entity.Season{ID: "2026-09", EndedAt: 100}, nil)
+				mockRepo.On("GetSeasonUserRank", tmock.Anything, "2026-09", "user_1").
+					Return(&entity.UserRank{UserID: "user_1", Elo: 1400, Rank: 1}, nil)
+			},
+		},
+		{
+			name:     "User did not play the season",
+			seasonID: "2026-09",
+			userID:   "user_x",
+			err:      echo.NewHTTPError(http.StatusNotFound, "user is not ranked"),
+			wantErr:  true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetSeason", tmock.Anything, "2026-09").
+					Return(&entity.Season{ID: "2026-09", EndedAt: 100}, nil)
+				mockRepo.On("GetSeasonUserRank", tmock.Anything, "2026-09", "user_x").Return(nil, repo.ErrNotFound)
+			},
+		},
+		{
+			name:     "Unknown season",
+			seasonID: "2026-08",
+			userID:   "user_1",
+			err:      echo.NewHTTPError(http.StatusNotFound, "season is not found"),
+			wantErr:  true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetSeason", tmock.Anything, "2026-08").Return(nil, repo.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratingRepo := &mock.RatingRepo{}
+			tt.setupMocks(ratingRepo)
+			svc := &SeasonService{
+				ratingRepo: ratingRepo,
+			}
+
+			target := "/v1/seasons/" + tt.seasonID + "/users/" + tt.userID
+			c, rec := newTestContext(http.MethodGet, target, nil, map[string]string{"season_id": tt.seasonID, "user_id": tt.userID})
+			err := svc.GetSeasonUserRank(c)
+			if tt.wantErr {
+				assert.Equal(t, tt.err.Error(), err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			var resp v1.GetSeasonUserRankResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.want, &resp)
+		})
+	}
+}
+
+func TestSeasonService_RolloverSeason(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *v1.RolloverSeasonRequest
+		want       *v1.RolloverSeasonResponse
+		err        error
+		wantErr    bool
+		setupMocks func(repo *mock.RatingRepo)
+	}{
+		{
+			name: "Start a new season",
+			req:  &v1.RolloverSeasonRequest{SeasonID: "2026-10"},
+			want: &v1.RolloverSeasonResponse{
+				Ended:   &v1.Season{ID: "2026-09", EndedAt: 100},
+				Current: &v1.Season{ID: "2026-10"},
+			},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("RolloverSeason", tmock.Anything, "2026-10", tmock.Anything, tmock.Anything).
+					Return(func(_ context.Context, _ string, _ int64, fn repo.SeasonResetFunc) (*entity.Season, error) {
+						// Elos are soft reset with the configured factor.
+						if fn(&entity.UserElo{Elo: 1400}).Elo != 1200 {
+							return nil, errors.New("elos are not soft reset")
+						}
+
+						return &entity.Season{ID: "2026-09", EndedAt: 100}, nil
+					})
+			},
+		},
+		{
+			name:       "Validator request form: required validation fail",
+			req:        &v1.RolloverSeasonRequest{},
+			err:        echo.NewHTTPError(http.StatusBadRequest, []string{"seasonID is required"}),
+			wantErr:    true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {},
+		},
+		{
+			name:    "Season already exists",
+			req:     &v1.RolloverSeasonRequest{SeasonID: "2026-09"},
+			err:     echo.NewHTTPError(http.StatusConflict, "season already exists"),
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("RolloverSeason", tmock.Anything, "2026-09", tmock.Anything, tmock.Anything).
+					Return(nil, repo.ErrAlreadyExists)
+			},
+		},
+		{
+			name:    "Current season has no id",
+			req:     &v1.RolloverSeasonRequest{SeasonID: "2026-10"},
+			err:     echo.NewHTTPError(http.StatusConflict, "current season has no id, season.id must be configured before the first rollover"),
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("RolloverSeason", tmock.Anything, "2026-10", tmock.Anything, tmock.Anything).
+					Return(nil, repo.ErrUnnamedSeason)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratingRepo := &mock.RatingRepo{}
+			tt.setupMocks(ratingRepo)
+			svc := &SeasonService{
+				ratingRepo: ratingRepo,
+				softReset:  rating.NewSoftReset(0.5),
+			}
+
+			c, rec := newTestContext(http.MethodPost, "/v1/seasons/rollover", tt.req, nil)
+			err := svc.RolloverSeason(c)
+			if tt.wantErr {
+				assert.Equal(t, tt.err.Error(), err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			var resp v1.RolloverSeasonResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.want, &resp)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package v1impl
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	v1 "github.com/me0den/example-service/app/api/v1"
+	"github.com/me0den/example-service/domain/repo"
+)
+
+// UserService implements all use cases of user service.
+type UserService struct {
+	ratingRepo repo.RatingRepo
+}
+
+// NewUserService creates and returns new instance of UserService.
+func NewUserService(
+	ratingRepo repo.RatingRepo,
+) v1.UserService {
+	svc := &UserService{
+		ratingRepo: ratingRepo,
+	}
+
+	return svc
+}
+
+// GetUserElo to get the current rating of a user, unknown users get the default rating.
+func (s *UserService) GetUserElo(c echo.Context) error {
+	req := new(v1.GetUserEloRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	userElo, err := s.ratingRepo.GetUserElo(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, userElo)
+}
+
+// BatchGetUserElos to get the current ratings of users in the request order, unknown users get the default rating.
+func (s *UserService) BatchGetUserElos(c echo.Context) error {
+	req := new(v1.BatchGetUserElosRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	userElos, err := s.ratingRepo.ListUserElos(ctx, req.UserIDs)
+	if err != nil {
+		return err
+	}
+
+	res := &v1.BatchGetUserElosResponse{
+		Items: userElos,
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// ListRatingHistory to list a page of the rating history of a user, newest first.
+func (s *UserService) ListRatingHistory(c echo.Context) error {
+	req := new(v1.ListRatingHistoryRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	if req.From > 0 && req.To > 0 && req.From > req.To {
+		return echo.NewHTTPError(http.StatusBadRequest, "from must be less than or equals to to")
+	}
+
+	ctx := c.Request().Context()
+	history, err := s.ratingRepo.ListRatingHistory(ctx, req.UserID, req.From, req.To, req.GetOffset(), req.GetSize())
+	if err != nil {
+		return err
+	}
+
+	res := &v1.ListRatingHistoryResponse{
+		Page:  req.GetPage(),
+		Size:  req.GetSize(),
+		Items: history,
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,240 @@
+package v1impl
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+
+	v1 "github.com/me0den/example-service/app/api/v1"
+	"github.com/me0den/example-service/app/api/v1/v1impl/mock"
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/enum"
+)
+
+func TestUserService_GetUserElo(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		want       *v1.GetUserEloResponse
+		err        error
+		wantErr    bool
+		setupMocks func(repo *mock.RatingRepo)
+	}{
+		{
+			name:   "Known user",
+			userID: "user_1",
+			want:   &v1.GetUserEloResponse{UserID: "user_1", Elo: 1200, Deviation: 80, Volatility: 0.06},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetUserElo", tmock.Anything, "user_1").
+					Return(&entity.UserElo{UserID: "user_1", Elo: 1200, Deviation: 80, Volatility: 0.06}, nil)
+			},
+		},
+		{
+			name:   "Unknown user gets default elo",
+			userID: "user_2",
+			want:   entity.NewUserDefaultElo("user_2"),
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetUserElo", tmock.Anything, "user_2").Return(entity.NewUserDefaultElo("user_2"), nil)
+			},
+		},
+		{
+			name:    "Failed to get user elo",
+			userID:  "user_1",
+			err:     echo.ErrInternalServerError,
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetUserElo", tmock.Anything, "user_1").Return(nil, echo.ErrInternalServerError)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratingRepo := &mock.RatingRepo{}
+			tt.setupMocks(ratingRepo)
+			svc := &UserService{
+				ratingRepo: ratingRepo,
+			}
+
+			c, rec := newTestContext(http.MethodGet, "/v1/users/"+tt.userID+"/elo", nil, map[string]string{"user_id": tt.userID})
+			err := svc.GetUserElo(c)
+			if tt.wantErr {
+				assert.Equal(t, tt.err.Error(), err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			var resp v1.GetUserEloResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.want, &resp)
+		})
+	}
+}
+
+func TestUserService_BatchGetUserElos(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *v1.BatchGetUserElosRequest
+		want       *v1.BatchGetUserElosResponse
+		err        error
+		wantErr    bool
+		setupMocks func(repo *mock.RatingRepo)
+	}{
+		{
+			name: "Known and unknown users in request order",
+			req:  &v1.BatchGetUserElosRequest{UserIDs: []string{"user_2", "user_1"}},
+			want: &v1.BatchGetUserElosResponse{
+				Items: []*v1.UserElo{
+					entity.NewUserDefaultElo("user_2"),
+					{UserID: "user_1", Elo: 1200, Deviation: 80, Volatility: 0.06},
+				},
+			},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("ListUserElos", tmock.Anything, []string{"user_2", "user_1"}).
+					Return([]*entity.UserElo{
+						entity.NewUserDefaultElo("user_2"),
+						{UserID: "user_1", Elo: 1200, Deviation: 80, Volatility: 0.06},
+					}, nil)
+			},
+		},
+		{
+			name:       "Validator request form: required validation fail",
+			req:        &v1.BatchGetUserElosRequest{},
+			err:        echo.NewHTTPError(http.StatusBadRequest, []string{"userIDs is required"}),
+			wantErr:    true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {},
+		},
+		{
+			name:    "Failed to list user elos",
+			req:     &v1.BatchGetUserElosRequest{UserIDs: []string{"user_1"}},
+			err:     echo.ErrInternalServerError,
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("ListUserElos", tmock.Anything, []string{"user_1"}).Return(nil, echo.ErrInternalServerError)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratingRepo := &mock.RatingRepo{}
+			tt.setupMocks(ratingRepo)
+			svc := &UserService{
+				ratingRepo: ratingRepo,
+			}
+
+			c, rec := newTestContext(http.MethodPost, "/v1/users/elo:batchGet", tt.req, nil)
+			err := svc.BatchGetUserElos(c)
+			if tt.wantErr {
+				assert.Equal(t, tt.err.Error(), err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			var resp v1.BatchGetUserElosResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.want, &resp)
+		})
+	}
+}
+
+func TestUserService_ListRatingHistory(t *testing.T) {
+	history := []*entity.RatingHistory{
+		{
+			BattleID:  "battle_2",
+			UserID:    "user_1",
+			OldElo:    1016,
+			NewElo:    1000,
+			Opponents: []string{"user_3"},
+			Result:    ptr(enum.BattleResultLose),
+			CreatedAt: 1700000200,
+		},
+		{
+			BattleID:  "battle_1",
+			UserID:    "user_1",
+			OldElo:    1000,
+			NewElo:    1016,
+			Opponents: []string{"user_2"},
+			Result:    ptr(enum.BattleResultWin),
+			CreatedAt: 1700000100,
+		},
+	}
+	tests := []struct {
+		name       string
+		target     string
+		want       *v1.ListRatingHistoryResponse
+		err        error
+		wantErr    bool
+		setupMocks func(repo *mock.RatingRepo)
+	}{
+		{
+			name:   "Default page without time range",
+			target: "/v1/users/user_1/history",
+			want: &v1.ListRatingHistoryResponse{
+				Page:  1,
+				Size:  v1.DefaultPageSize,
+				Items: history,
+			},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("ListRatingHistory", tmock.Anything, "user_1", int64(0), int64(0), int64(0), int64(v1.DefaultPageSize)).
+					Return(history, nil)
+			},
+		},
+		{
+			name:   "Second page within time range",
+			target: "/v1/users/user_1/history?from=1700000000&to=1700000300&page=2&size=1",
+			want: &v1.ListRatingHistoryResponse{
+				Page:  2,
+				Size:  1,
+				Items: history[1:],
+			},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("ListRatingHistory", tmock.Anything, "user_1", int64(1700000000), int64(1700000300), int64(1), int64(1)).
+					Return(history[1:], nil)
+			},
+		},
+		{
+			name:       "Time range is reversed",
+			target:     "/v1/users/user_1/history?from=1700000300&to=1700000000",
+			err:        echo.NewHTTPError(http.StatusBadRequest, "from must be less than or equals to to"),
+			wantErr:    true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {},
+		},
+		{
+			name:    "Failed to list rating history",
+			target:  "/v1/users/user_1/history",
+			err:     echo.ErrInternalServerError,
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("ListRatingHistory", tmock.Anything, "user_1", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything).
+					Return(nil, echo.ErrInternalServerError)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratingRepo := &mock.RatingRepo{}
+			tt.setupMocks(ratingRepo)
+			svc := &UserService{
+				ratingRepo: ratingRepo,
+			}
+
+			c, rec := newTestContext(http.MethodGet, tt.target, nil, map[string]string{"user_id": "user_1"})
+			err := svc.ListRatingHistory(c)
+			if tt.wantErr {
+				assert.Equal(t, tt.err.Error(), err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			var resp v1.ListRatingHistoryResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.want, &resp)
+		})
+	}
+}
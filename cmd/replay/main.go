@@ -0,0 +1,93 @@
+// Command replay recomputes the ratings of the current season from the battle log with the configured rating
+// calculator, stores them in a fresh replay namespace and compares them to the live ratings.
+// With -swap the live ratings are atomically replaced by the replayed ones, unless battles were logged meanwhile.
+//
+// Only logged battles are replayed: battles accepted before the log existed and decays are not. The decay
+// should be disabled while swapping, as a decay between the replay and the swap is overwritten.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/me0den/example-service/infra/cache"
+	"github.com/me0den/example-service/infra/calculator"
+	"github.com/me0den/example-service/infra/config"
+	"github.com/me0den/example-service/infra/database"
+	"github.com/me0den/example-service/infra/health"
+	"github.com/me0den/example-service/infra/logging"
+	"github.com/me0den/example-service/infra/metrics"
+	"github.com/me0den/example-service/infra/repoimpl"
+	"github.com/me0den/example-service/infra/tracing"
+	"github.com/me0den/example-service/infra/worker"
+	"github.com/me0den/example-service/x/viper"
+)
+
+func main() {
+	namespace := flag.String("namespace", time.Now().UTC().Format("20060102T150405"), "namespace of the replayed ratings")
+	top := flag.Int("top", 20, "number of the largest changes to print")
+	swap := flag.Bool("swap", false, "replace the live ratings with the replayed ones")
+	force := flag.Bool("force", false, "swap even if ranked users are absent from the battle log")
+	flag.Parse()
+
+	var (
+		replayer *worker.Replayer
+		logger   *slog.Logger
+	)
+	app := fx.New(
+		viper.FXModule,
+		config.FXModule,
+		logging.FXModule,
+		health.FXModule,
+		metrics.FXModule,
+		tracing.FXModule,
+		cache.RedisFXModule,
+		database.PostgresFXModule,
+		calculator.RatingFXModule,
+		repoimpl.FXModule,
+		fx.Provide(worker.NewReplayer),
+		fx.Populate(&replayer, &logger),
+		fx.NopLogger,
+	)
+	if err := app.Err(); err != nil {
+		slog.Error("error when init replay", "error", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	report, err := replayer.Replay(ctx, *namespace)
+	if err != nil {
+		logger.Error("error when replay battle log", "error", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("namespace %q, season %q, replayed up to %q\n", report.Namespace, report.Season, report.LastLogID)
+	fmt.Printf("battles: %d, voided: %d, users: %d, changed: %d, missing: %d\n",
+		report.Battles, report.Voided, report.Users, len(report.Changes), report.Missing)
+	for _, change := range report.Changes[:min(*top, len(report.Changes))] {
+		fmt.Printf("%s\t%d -> %d (%+d)\n", change.UserID, change.LiveElo, change.ReplayedElo, change.Delta())
+	}
+
+	if !*swap {
+		return
+	}
+
+	if report.Missing > 0 && !*force {
+		logger.Error("ranked users are absent from the battle log, swap with -force to drop them",
+			"missing", report.Missing)
+		os.Exit(1)
+	}
+
+	if err := replayer.Swap(ctx, report); err != nil {
+		logger.Error("error when swap replayed ratings", "error", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("swapped the replayed ratings in")
+}
@@ -6,8 +6,15 @@ import (
 	"github.com/me0den/example-service/app/api/v1/transport/routes"
 	"github.com/me0den/example-service/app/api/v1/v1impl"
 	"github.com/me0den/example-service/infra/cache"
+	"github.com/me0den/example-service/infra/calculator"
 	"github.com/me0den/example-service/infra/config"
+	"github.com/me0den/example-service/infra/database"
+	"github.com/me0den/example-service/infra/health"
+	"github.com/me0den/example-service/infra/logging"
+	"github.com/me0den/example-service/infra/metrics"
 	"github.com/me0den/example-service/infra/repoimpl"
+	"github.com/me0den/example-service/infra/tracing"
+	"github.com/me0den/example-service/infra/worker"
 	"github.com/me0den/example-service/x/viper"
 )
 
@@ -15,10 +22,18 @@ func main() {
 	app := fx.New(
 		viper.FXModule,
 		config.FXModule,
+		logging.FXModule,
+		logging.FXEventLogger,
+		health.FXModule,
+		metrics.FXModule,
+		tracing.FXModule,
 		routes.ServerFXModule,
 		cache.RedisFXModule,
+		database.PostgresFXModule,
+		calculator.RatingFXModule,
 		repoimpl.FXModule,
 		v1impl.FXModule,
+		worker.DecayFXModule,
 	)
 	app.Run()
 }
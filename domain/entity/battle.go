@@ -0,0 +1,119 @@
+package entity
+
+// Battle defines data model for a processed battle and the rewards applied by it.
+//
+// A voided battle keeps the reversals which undid its rewards.
+type Battle struct {
+	ID     string `json:"id"`
+	Season string `json:"season,omitempty"`
+	// Teams holds the members of every team, Placements holds the finishing placement of every team.
+	Teams      [][]string `json:"teams,omitempty"`
+	Placements []int      `json:"placements,omitempty"`
+	Rewards    []*Reward  `json:"rewards"`
+	Reversals  []*Reward  `json:"reversals,omitempty"`
+	VoidedAt   int64      `json:"voidedAt,omitempty"`
+}
+
+// IsVoided reports whether the battle was voided.
+func (b *Battle) IsVoided() bool {
+	return b.VoidedAt > 0
+}
+
+// History returns the rating history entries of every user rewarded by the battle.
+func (b *Battle) History() []*RatingHistory {
+	history := make([]*RatingHistory, 0, len(b.Rewards))
+	for _, reward := range b.Rewards {
+		result := reward.Result
+		history = append(history, &RatingHistory{
+			BattleID:  b.ID,
+			Season:    b.Season,
+			UserID:    reward.UserID,
+			Reason:    HistoryReasonBattle,
+			OldElo:    reward.OldElo,
+			NewElo:    reward.NewElo,
+			Opponents: reward.Opponents,
+			Result:    &result,
+			Placement: reward.Placement,
+			CreatedAt: reward.UpdatedAt,
+		})
+	}
+
+	return history
+}
+
+// Log returns the entry of the battle log recording the outcome of the battle.
+func (b *Battle) Log() *BattleLog {
+	entry := &BattleLog{
+		Kind:       BattleLogKindBattle,
+		BattleID:   b.ID,
+		Season:     b.Season,
+		Teams:      b.Teams,
+		Placements: b.Placements,
+	}
+	if len(b.Rewards) > 0 {
+		entry.CreatedAt = b.Rewards[0].UpdatedAt
+	}
+
+	return entry
+}
+
+// VoidLog returns the entry of the battle log recording the void of the battle.
+func (b *Battle) VoidLog() *BattleLog {
+	return &BattleLog{
+		Kind:      BattleLogKindVoid,
+		BattleID:  b.ID,
+		Season:    b.Season,
+		CreatedAt: b.VoidedAt,
+	}
+}
+
+// Void reverses the elo change of every rewarded user from their current userElos in the order of the rewards,
+// it marks the battle voided at voidedAt and returns the new elos.
+//
+// The deviation and volatility are kept, as they cannot be reverted once other battles followed.
+func (b *Battle) Void(userElos []*UserElo, voidedAt int64) []*UserElo {
+	newUserElos := make([]*UserElo, 0, len(userElos))
+	b.Reversals = make([]*Reward, 0, len(b.Rewards))
+	for idx, reward := range b.Rewards {
+		newUserElo := userElos[idx].Clone()
+		newUserElo.Elo -= reward.NewElo - reward.OldElo
+		if newUserElo.GamesPlayed > 0 {
+			newUserElo.GamesPlayed--
+		}
+
+		newUserElos = append(newUserElos, newUserElo)
+		b.Reversals = append(b.Reversals, &Reward{
+			UserID:     reward.UserID,
+			TeamID:     reward.TeamID,
+			OldElo:     userElos[idx].Elo,
+			NewElo:     newUserElo.Elo,
+			Deviation:  newUserElo.Deviation,
+			Volatility: newUserElo.Volatility,
+			Opponents:  reward.Opponents,
+			Result:     reward.Result,
+			Placement:  reward.Placement,
+			UpdatedAt:  voidedAt,
+		})
+	}
+
+	b.VoidedAt = voidedAt
+	return newUserElos
+}
+
+// ReversalHistory returns the rating history entries of every user whose reward was reversed by voiding the battle.
+func (b *Battle) ReversalHistory() []*RatingHistory {
+	history := make([]*RatingHistory, 0, len(b.Reversals))
+	for _, reversal := range b.Reversals {
+		history = append(history, &RatingHistory{
+			BattleID:  b.ID,
+			Season:    b.Season,
+			UserID:    reversal.UserID,
+			Reason:    HistoryReasonVoid,
+			OldElo:    reversal.OldElo,
+			NewElo:    reversal.NewElo,
+			CreatedAt: reversal.UpdatedAt,
+		})
+	}
+
+	return history
+}
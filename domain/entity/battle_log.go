@@ -0,0 +1,21 @@
+package entity
+
+const (
+	BattleLogKindBattle = "battle"
+	BattleLogKindVoid   = "void"
+)
+
+// BattleLog defines data model for an entry of the append-only log of accepted and voided battles.
+//
+// A battle entry records the outcome of the battle, so the ratings can be recomputed from the log.
+type BattleLog struct {
+	// ID is the id of the entry in the log, entries are ordered by id.
+	ID       string `json:"-"`
+	Kind     string `json:"kind"`
+	BattleID string `json:"battleID"`
+	Season   string `json:"season,omitempty"`
+	// Teams holds the members of every team, Placements holds the finishing placement of every team.
+	Teams      [][]string `json:"teams,omitempty"`
+	Placements []int      `json:"placements,omitempty"`
+	CreatedAt  int64      `json:"createdAt"`
+}
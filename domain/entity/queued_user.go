@@ -0,0 +1,9 @@
+package entity
+
+// QueuedUser defines data model for a user waiting in the matchmaking queue.
+type QueuedUser struct {
+	UserID string `json:"userID"`
+	Elo    int    `json:"elo"`
+	// JoinedAt is the unix timestamp the user joined the queue at.
+	JoinedAt int64 `json:"joinedAt"`
+}
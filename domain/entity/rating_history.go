@@ -0,0 +1,24 @@
+package entity
+
+import "github.com/me0den/example-service/domain/enum"
+
+// Reasons of a rating change.
+const (
+	HistoryReasonBattle = "battle"
+	HistoryReasonDecay  = "decay"
+	HistoryReasonVoid   = "void"
+)
+
+// RatingHistory defines data model for a rating change of a user, the battle fields are only set by battles.
+type RatingHistory struct {
+	BattleID  string             `json:"battleID,omitempty"`
+	Season    string             `json:"season,omitempty"`
+	UserID    string             `json:"userID"`
+	Reason    string             `json:"reason"`
+	OldElo    int                `json:"oldElo"`
+	NewElo    int                `json:"newElo"`
+	Opponents []string           `json:"opponents,omitempty"`
+	Result    *enum.BattleResult `json:"result,omitempty"`
+	Placement int                `json:"placement,omitempty"`
+	CreatedAt int64              `json:"createdAt"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "github.com/me0den/example-service/domain/enum"
+
+// Reward defines data model for the reward of a user after a battle.
+type Reward struct {
+	UserID     string            `json:"userID"`
+	TeamID     string            `json:"teamID,omitempty"`
+	OldElo     int               `json:"oldElo"`
+	NewElo     int               `json:"newElo"`
+	Deviation  float64           `json:"deviation"`
+	Volatility float64           `json:"volatility"`
+	Opponents  []string          `json:"opponents"`
+	Result     enum.BattleResult `json:"result"`
+	Placement  int               `json:"placement"`
+	UpdatedAt  int64             `json:"updatedAt"`
+}
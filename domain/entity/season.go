@@ -0,0 +1,7 @@
+package entity
+
+// Season defines data model for a ranked season, an ended season keeps the final standings of its users.
+type Season struct {
+	ID      string `json:"id"`
+	EndedAt int64  `json:"endedAt,omitempty"`
+}
@@ -1,7 +1,24 @@
 package entity
 
+import "github.com/me0den/example-service/domain/enum"
+
 // Team defines data model for resource Team struct.
 type Team struct {
-	ID    string `json:"id"`
-	Owner string `json:"userID"`
+	ID        string             `json:"id"`
+	Owner     string             `json:"userID"`
+	Members   []string           `json:"members,omitempty" validate:"max=16,dive,required"`
+	Result    *enum.BattleResult `json:"result,omitempty"`
+	Placement int                `json:"placement,omitempty" validate:"min=0"`
+}
+
+// GetMembers returns the user ids of the team members, the owner is always the first member.
+func (t *Team) GetMembers() []string {
+	members := []string{t.Owner}
+	for _, member := range t.Members {
+		if member != t.Owner {
+			members = append(members, member)
+		}
+	}
+
+	return members
 }
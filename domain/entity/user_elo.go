@@ -1,27 +1,66 @@
 package entity
 
 const (
-	DefaultElo = 1000
+	DefaultElo        = 1000
+	DefaultDeviation  = 350
+	DefaultVolatility = 0.06
+	// UnknownGamesPlayed is the number of games of a user rated before games were counted.
+	UnknownGamesPlayed = -1
 )
 
 // UserElo defines data model for resource UserElo struct.
 type UserElo struct {
-	UserID string `json:"userID"`
-	Elo    int    `json:"elo"`
+	UserID      string  `json:"userID"`
+	Elo         int     `json:"elo"`
+	Deviation   float64 `json:"deviation"`
+	Volatility  float64 `json:"volatility"`
+	GamesPlayed int     `json:"gamesPlayed"`
 }
 
 // Clone create a new object UserElo with exists value.
 func (e *UserElo) Clone() *UserElo {
 	return &UserElo{
-		UserID: e.UserID,
-		Elo:    e.Elo,
+		UserID:      e.UserID,
+		Elo:         e.Elo,
+		Deviation:   e.Deviation,
+		Volatility:  e.Volatility,
+		GamesPlayed: e.GamesPlayed,
 	}
 }
 
+// AddGame counts a game played by the user, the games of a user rated before games were counted stay unknown.
+func (e *UserElo) AddGame() {
+	if e.GamesPlayed != UnknownGamesPlayed {
+		e.GamesPlayed++
+	}
+}
+
+// IsProvisional reports whether the user has played less than games, users with unknown games are established.
+func (e *UserElo) IsProvisional(games int) bool {
+	return e.GamesPlayed != UnknownGamesPlayed && e.GamesPlayed < games
+}
+
+// IsLegacy reports whether UserElo was stored before rating deviation and volatility existed.
+func (e *UserElo) IsLegacy() bool {
+	return e.Deviation == 0 && e.Volatility == 0
+}
+
+// Migrate fills rating deviation and volatility of a legacy UserElo with default values.
+func (e *UserElo) Migrate() {
+	if !e.IsLegacy() {
+		return
+	}
+
+	e.Deviation = DefaultDeviation
+	e.Volatility = DefaultVolatility
+}
+
 // NewUserDefaultElo create a new object UserElo with default value.
 func NewUserDefaultElo(userID string) *UserElo {
 	return &UserElo{
-		UserID: userID,
-		Elo:    DefaultElo,
+		UserID:     userID,
+		Elo:        DefaultElo,
+		Deviation:  DefaultDeviation,
+		Volatility: DefaultVolatility,
 	}
 }
@@ -0,0 +1,9 @@
+package entity
+
+// UserRank defines data model for the position of a user on the leaderboard.
+type UserRank struct {
+	UserID string `json:"userID"`
+	Elo    int    `json:"elo"`
+	// Rank is the 1-based position of the user, ordered by descending elo.
+	Rank int64 `json:"rank"`
+}
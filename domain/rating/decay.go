@@ -0,0 +1,53 @@
+package rating
+
+import (
+	"time"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+const (
+	DefaultDecayInterval  = time.Hour
+	DefaultDecayThreshold = 14 * 24 * time.Hour
+	DefaultDecayPeriod    = 24 * time.Hour
+	DefaultDecayBatchSize = 100
+)
+
+// DecayConfig is a group of options for the decay of inactive users.
+type DecayConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is the time between two runs of the decay.
+	Interval time.Duration `mapstructure:"interval"`
+	// Threshold is the inactivity of a user before the first decay, Period is the inactivity between two decays.
+	Threshold time.Duration `mapstructure:"threshold"`
+	Period    time.Duration `mapstructure:"period"`
+	Amount    int           `mapstructure:"amount"`
+	Floor     int           `mapstructure:"floor"`
+	BatchSize int64         `mapstructure:"batch_size"`
+}
+
+// Decay lowers the ratings of inactive users down to a floor.
+type Decay struct {
+	amount int
+	floor  int
+}
+
+// NewDecay creates and returns new instance of Decay losing amount of elo per decay but never below floor.
+func NewDecay(amount, floor int) *Decay {
+	return &Decay{
+		amount: amount,
+		floor:  floor,
+	}
+}
+
+// Apply returns the decayed rating of an inactive user or nil if the rating does not decay.
+func (d *Decay) Apply(userElo *entity.UserElo) *entity.UserElo {
+	if d.amount <= 0 || userElo.Elo <= d.floor {
+		return nil
+	}
+
+	newUserElo := userElo.Clone()
+	newUserElo.Elo = max(userElo.Elo-d.amount, d.floor)
+
+	return newUserElo
+}
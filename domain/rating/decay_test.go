@@ -0,0 +1,52 @@
+package rating
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+func TestDecay_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  int
+		floor   int
+		userElo *entity.UserElo
+		want    *entity.UserElo
+	}{
+		{
+			name:    "Above the floor",
+			amount:  10,
+			floor:   1200,
+			userElo: &entity.UserElo{UserID: "user_1", Elo: 1500, Deviation: 50, Volatility: 0.06},
+			want:    &entity.UserElo{UserID: "user_1", Elo: 1490, Deviation: 50, Volatility: 0.06},
+		},
+		{
+			name:    "Stops at the floor",
+			amount:  10,
+			floor:   1200,
+			userElo: &entity.UserElo{UserID: "user_1", Elo: 1205},
+			want:    &entity.UserElo{UserID: "user_1", Elo: 1200},
+		},
+		{
+			name:    "At the floor does not decay",
+			amount:  10,
+			floor:   1200,
+			userElo: &entity.UserElo{UserID: "user_1", Elo: 1200},
+		},
+		{
+			name:    "Zero amount does not decay",
+			amount:  0,
+			floor:   1200,
+			userElo: &entity.UserElo{UserID: "user_1", Elo: 1500},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewDecay(tt.amount, tt.floor).Apply(tt.userElo))
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package rating
+
+import (
+	"math"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+const (
+	DefaultKFactor = 32
+)
+
+// KFactorSchedule gives provisional users and highly rated users their own K-factor, a zero option disables its tier.
+type KFactorSchedule struct {
+	// ProvisionalGames is the number of placement games a new user plays with ProvisionalKFactor.
+	ProvisionalGames   int     `mapstructure:"provisional_games"`
+	ProvisionalKFactor float64 `mapstructure:"provisional_k_factor"`
+	// HighRatingThreshold is the elo from which established users play with HighRatingKFactor.
+	HighRatingThreshold int     `mapstructure:"high_rating_threshold"`
+	HighRatingKFactor   float64 `mapstructure:"high_rating_k_factor"`
+}
+
+// Elo implements RatingCalculator with the classic Elo rating system.
+type Elo struct {
+	kFactor  float64
+	schedule KFactorSchedule
+}
+
+// NewElo creates and returns new instance of Elo, a non-positive kFactor falls back to DefaultKFactor.
+func NewElo(kFactor float64) *Elo {
+	return NewScheduledElo(kFactor, KFactorSchedule{})
+}
+
+// NewScheduledElo creates and returns new instance of Elo with the K-factor of every user given by schedule,
+// kFactor is used by users out of the schedule tiers and a non-positive one falls back to DefaultKFactor.
+func NewScheduledElo(kFactor float64, schedule KFactorSchedule) *Elo {
+	if kFactor <= 0 {
+		kFactor = DefaultKFactor
+	}
+
+	return &Elo{
+		kFactor:  kFactor,
+		schedule: schedule,
+	}
+}
+
+// KFactor returns the K-factor of a user, the provisional tier takes precedence over the high rating one.
+func (e *Elo) KFactor(userElo *entity.UserElo) float64 {
+	switch {
+	case e.schedule.ProvisionalKFactor > 0 && userElo.IsProvisional(e.schedule.ProvisionalGames):
+		return e.schedule.ProvisionalKFactor
+	case e.schedule.HighRatingKFactor > 0 && e.schedule.HighRatingThreshold > 0 &&
+		userElo.Elo >= e.schedule.HighRatingThreshold:
+		return e.schedule.HighRatingKFactor
+	default:
+		return e.kFactor
+	}
+}
+
+// ExpectedScore returns the expected score of a player rated elo against an opponent rated opponentElo.
+func ExpectedScore(elo, opponentElo int) float64 {
+	return 1 / (1 + math.Pow(10, float64(opponentElo-elo)/400))
+}
+
+// Calculate returns the new ratings of both players, score is the actual score of the first player.
+//
+// The rating change of every player is scaled by their own K-factor and rounded, so the total rating of both
+// players is preserved when they share the same K-factor.
+func (e *Elo) Calculate(first, second *entity.UserElo, score float64) (*entity.UserElo, *entity.UserElo) {
+	newFirst, newSecond := first.Clone(), second.Clone()
+	surprise := score - ExpectedScore(first.Elo, second.Elo)
+	newFirst.Elo += int(math.Round(e.KFactor(first) * surprise))
+	newSecond.Elo -= int(math.Round(e.KFactor(second) * surprise))
+
+	return newFirst, newSecond
+}
+
+// CalculateMultiplayer returns the new ratings of players in the same order, every pair of players is rated as a battle.
+//
+// The K-factor of a player is shared among their opponents, so a two players battle is rated as Calculate does.
+func (e *Elo) CalculateMultiplayer(userElos []*entity.UserElo, placements []int) []*entity.UserElo {
+	newUserElos := make([]*entity.UserElo, 0, len(userElos))
+	for _, userElo := range userElos {
+		newUserElos = append(newUserElos, userElo.Clone())
+	}
+
+	if len(userElos) < 2 {
+		return newUserElos
+	}
+
+	opponents := float64(len(userElos) - 1)
+	for i := range userElos {
+		for j := i + 1; j < len(userElos); j++ {
+			surprise := PlacementScore(placements[i], placements[j]) - ExpectedScore(userElos[i].Elo, userElos[j].Elo)
+			newUserElos[i].Elo += int(math.Round(e.KFactor(userElos[i]) / opponents * surprise))
+			newUserElos[j].Elo -= int(math.Round(e.KFactor(userElos[j]) / opponents * surprise))
+		}
+	}
+
+	return newUserElos
+}
+
+// WinProbability returns the expected probability of every player to finish first in the same order,
+// the probability of the first of two players is ExpectedScore.
+func (e *Elo) WinProbability(userElos []*entity.UserElo) []float64 {
+	strengths := make([]float64, 0, len(userElos))
+	for _, userElo := range userElos {
+		strengths = append(strengths, float64(userElo.Elo)*math.Ln10/400)
+	}
+
+	return winProbability(strengths)
+}
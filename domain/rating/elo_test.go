@@ -0,0 +1,300 @@
+package rating
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+func TestElo_Calculate(t *testing.T) {
+	type args struct {
+		first  *entity.UserElo
+		second *entity.UserElo
+		score  float64
+	}
+	tests := []struct {
+		name       string
+		kFactor    float64
+		args       args
+		wantFirst  *entity.UserElo
+		wantSecond *entity.UserElo
+	}{
+		{
+			name:    "Equal ratings - first player wins",
+			kFactor: 32,
+			args: args{
+				first:  &entity.UserElo{UserID: "user_1", Elo: 1000},
+				second: &entity.UserElo{UserID: "user_2", Elo: 1000},
+				score:  ScoreWin,
+			},
+			wantFirst:  &entity.UserElo{UserID: "user_1", Elo: 1016},
+			wantSecond: &entity.UserElo{UserID: "user_2", Elo: 984},
+		},
+		{
+			name:    "Equal ratings - draw",
+			kFactor: 32,
+			args: args{
+				first:  &entity.UserElo{UserID: "user_1", Elo: 1000},
+				second: &entity.UserElo{UserID: "user_2", Elo: 1000},
+				score:  ScoreDraw,
+			},
+			wantFirst:  &entity.UserElo{UserID: "user_1", Elo: 1000},
+			wantSecond: &entity.UserElo{UserID: "user_2", Elo: 1000},
+		},
+		{
+			name:    "Favourite beats underdog - small gain",
+			kFactor: 32,
+			args: args{
+				first:  &entity.UserElo{UserID: "user_1", Elo: 1600},
+				second: &entity.UserElo{UserID: "user_2", Elo: 1400},
+				score:  ScoreWin,
+			},
+			wantFirst:  &entity.UserElo{UserID: "user_1", Elo: 1608},
+			wantSecond: &entity.UserElo{UserID: "user_2", Elo: 1392},
+		},
+		{
+			name:    "Heavy favourite beats underdog - rounds to no change",
+			kFactor: 32,
+			args: args{
+				first:  &entity.UserElo{UserID: "user_1", Elo: 2400},
+				second: &entity.UserElo{UserID: "user_2", Elo: 900},
+				score:  ScoreWin,
+			},
+			wantFirst:  &entity.UserElo{UserID: "user_1", Elo: 2400},
+			wantSecond: &entity.UserElo{UserID: "user_2", Elo: 900},
+		},
+		{
+			name:    "Underdog beats favourite - large gain",
+			kFactor: 32,
+			args: args{
+				first:  &entity.UserElo{UserID: "user_1", Elo: 900},
+				second: &entity.UserElo{UserID: "user_2", Elo: 2400},
+				score:  ScoreWin,
+			},
+			wantFirst:  &entity.UserElo{UserID: "user_1", Elo: 932},
+			wantSecond: &entity.UserElo{UserID: "user_2", Elo: 2368},
+		},
+		{
+			name:    "Draw against stronger player gains rating",
+			kFactor: 20,
+			args: args{
+				first:  &entity.UserElo{UserID: "user_1", Elo: 1000},
+				second: &entity.UserElo{UserID: "user_2", Elo: 1200},
+				score:  ScoreDraw,
+			},
+			wantFirst:  &entity.UserElo{UserID: "user_1", Elo: 1005},
+			wantSecond: &entity.UserElo{UserID: "user_2", Elo: 1195},
+		},
+		{
+			name:    "Non-positive K-factor falls back to default",
+			kFactor: 0,
+			args: args{
+				first:  &entity.UserElo{UserID: "user_1", Elo: 1000},
+				second: &entity.UserElo{UserID: "user_2", Elo: 1000},
+				score:  ScoreLose,
+			},
+			wantFirst:  &entity.UserElo{UserID: "user_1", Elo: 984},
+			wantSecond: &entity.UserElo{UserID: "user_2", Elo: 1016},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, second := NewElo(tt.kFactor).Calculate(tt.args.first, tt.args.second, tt.args.score)
+			assert.Equal(t, tt.wantFirst, first)
+			assert.Equal(t, tt.wantSecond, second)
+			// Inputs must not be modified.
+			assert.NotSame(t, tt.args.first, first)
+			assert.NotSame(t, tt.args.second, second)
+		})
+	}
+}
+
+func TestElo_CalculateMultiplayer(t *testing.T) {
+	type args struct {
+		userElos   []*entity.UserElo
+		placements []int
+	}
+	tests := []struct {
+		name    string
+		kFactor float64
+		args    args
+		want    []*entity.UserElo
+	}{
+		{
+			name:    "Two players are rated as Calculate does",
+			kFactor: 20,
+			args: args{
+				userElos: []*entity.UserElo{
+					{UserID: "user_1", Elo: 1000},
+					{UserID: "user_2", Elo: 1200},
+				},
+				placements: []int{1, 2},
+			},
+			want: []*entity.UserElo{
+				{UserID: "user_1", Elo: 1015},
+				{UserID: "user_2", Elo: 1185},
+			},
+		},
+		{
+			name:    "Free-for-all with distinct placements",
+			kFactor: 32,
+			args: args{
+				userElos: []*entity.UserElo{
+					{UserID: "user_1", Elo: 1000},
+					{UserID: "user_2", Elo: 1000},
+					{UserID: "user_3", Elo: 1000},
+				},
+				placements: []int{1, 2, 3},
+			},
+			want: []*entity.UserElo{
+				{UserID: "user_1", Elo: 1016},
+				{UserID: "user_2", Elo: 1000},
+				{UserID: "user_3", Elo: 984},
+			},
+		},
+		{
+			name:    "Free-for-all with tied placements",
+			kFactor: 32,
+			args: args{
+				userElos: []*entity.UserElo{
+					{UserID: "user_1", Elo: 1000},
+					{UserID: "user_2", Elo: 1000},
+					{UserID: "user_3", Elo: 1000},
+				},
+				placements: []int{1, 1, 2},
+			},
+			want: []*entity.UserElo{
+				{UserID: "user_1", Elo: 1008},
+				{UserID: "user_2", Elo: 1008},
+				{UserID: "user_3", Elo: 984},
+			},
+		},
+		{
+			name:    "Single player is not rated",
+			kFactor: 32,
+			args: args{
+				userElos:   []*entity.UserElo{{UserID: "user_1", Elo: 1000}},
+				placements: []int{1},
+			},
+			want: []*entity.UserElo{{UserID: "user_1", Elo: 1000}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewElo(tt.kFactor).CalculateMultiplayer(tt.args.userElos, tt.args.placements)
+			assert.Equal(t, tt.want, got)
+			// Ratings are preserved among all players.
+			var wantTotal, gotTotal int
+			for idx := range got {
+				wantTotal += tt.args.userElos[idx].Elo
+				gotTotal += got[idx].Elo
+			}
+			assert.Equal(t, wantTotal, gotTotal)
+		})
+	}
+}
+
+func TestElo_KFactor(t *testing.T) {
+	schedule := KFactorSchedule{
+		ProvisionalGames:    10,
+		ProvisionalKFactor:  64,
+		HighRatingThreshold: 2400,
+		HighRatingKFactor:   16,
+	}
+	tests := []struct {
+		name     string
+		schedule KFactorSchedule
+		userElo  *entity.UserElo
+		want     float64
+	}{
+		{
+			name:     "Placement game",
+			schedule: schedule,
+			userElo:  &entity.UserElo{Elo: 1000, GamesPlayed: 9},
+			want:     64,
+		},
+		{
+			name:     "Established user",
+			schedule: schedule,
+			userElo:  &entity.UserElo{Elo: 1000, GamesPlayed: 10},
+			want:     32,
+		},
+		{
+			name:     "Highly rated user",
+			schedule: schedule,
+			userElo:  &entity.UserElo{Elo: 2400, GamesPlayed: 10},
+			want:     16,
+		},
+		{
+			name:     "Provisional tier takes precedence",
+			schedule: schedule,
+			userElo:  &entity.UserElo{Elo: 2500, GamesPlayed: 0},
+			want:     64,
+		},
+		{
+			name:     "User rated before games were counted is established",
+			schedule: schedule,
+			userElo:  &entity.UserElo{Elo: 1000, GamesPlayed: entity.UnknownGamesPlayed},
+			want:     32,
+		},
+		{
+			name:    "Without schedule",
+			userElo: &entity.UserElo{Elo: 2500, GamesPlayed: 0},
+			want:    32,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewScheduledElo(32, tt.schedule).KFactor(tt.userElo))
+		})
+	}
+}
+
+func TestElo_Calculate_Provisional(t *testing.T) {
+	calculator := NewScheduledElo(32, KFactorSchedule{ProvisionalGames: 10, ProvisionalKFactor: 64})
+	first, second := calculator.Calculate(
+		&entity.UserElo{UserID: "user_1", Elo: 1000, GamesPlayed: 0},
+		&entity.UserElo{UserID: "user_2", Elo: 1000, GamesPlayed: 50},
+		ScoreWin,
+	)
+	// The new user moves twice as fast as the established one.
+	assert.Equal(t, &entity.UserElo{UserID: "user_1", Elo: 1032, GamesPlayed: 0}, first)
+	assert.Equal(t, &entity.UserElo{UserID: "user_2", Elo: 984, GamesPlayed: 50}, second)
+
+	newUserElos := calculator.CalculateMultiplayer([]*entity.UserElo{
+		{UserID: "user_1", Elo: 1000, GamesPlayed: 0},
+		{UserID: "user_2", Elo: 1000, GamesPlayed: 50},
+		{UserID: "user_3", Elo: 1000, GamesPlayed: 50},
+	}, []int{1, 2, 3})
+	assert.Equal(t, []*entity.UserElo{
+		{UserID: "user_1", Elo: 1032, GamesPlayed: 0},
+		{UserID: "user_2", Elo: 1000, GamesPlayed: 50},
+		{UserID: "user_3", Elo: 984, GamesPlayed: 50},
+	}, newUserElos)
+}
+
+func TestElo_WinProbability(t *testing.T) {
+	calculator := NewElo(32)
+
+	// Two players win as often as expected by ExpectedScore.
+	got := calculator.WinProbability([]*entity.UserElo{{UserID: "user_1", Elo: 1200}, {UserID: "user_2", Elo: 1000}})
+	assert.InDelta(t, ExpectedScore(1200, 1000), got[0], 0.0001)
+	assert.InDelta(t, ExpectedScore(1000, 1200), got[1], 0.0001)
+
+	got = calculator.WinProbability([]*entity.UserElo{
+		{UserID: "user_1", Elo: 1000},
+		{UserID: "user_2", Elo: 1000},
+		{UserID: "user_3", Elo: 1000},
+		{UserID: "user_4", Elo: 1000},
+	})
+	assert.InDeltaSlice(t, []float64{0.25, 0.25, 0.25, 0.25}, got, 0.0001)
+
+	// Large ratings do not overflow.
+	got = calculator.WinProbability([]*entity.UserElo{{UserID: "user_1", Elo: 400000}, {UserID: "user_2", Elo: 399600}})
+	assert.InDeltaSlice(t, []float64{0.9091, 0.0909}, got, 0.0001)
+}
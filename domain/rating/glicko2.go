@@ -0,0 +1,172 @@
+package rating
+
+import (
+	"math"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+const (
+	DefaultTau = 0.5
+
+	// glicko2Scale converts ratings and deviations between the Glicko and the Glicko-2 scale.
+	glicko2Scale = 173.7178
+	// glicko2Center is the rating mapped to 0 on the Glicko-2 scale.
+	glicko2Center = 1500
+	// glicko2Epsilon is the convergence tolerance of the volatility iteration.
+	glicko2Epsilon = 0.000001
+)
+
+// Glicko2 implements RatingCalculator with the Glicko-2 rating system, every battle is treated as a rating period.
+type Glicko2 struct {
+	tau float64
+}
+
+// NewGlicko2 creates and returns new instance of Glicko2, a non-positive tau falls back to DefaultTau.
+func NewGlicko2(tau float64) *Glicko2 {
+	if tau <= 0 {
+		tau = DefaultTau
+	}
+
+	return &Glicko2{
+		tau: tau,
+	}
+}
+
+// Calculate returns the new ratings of both players, score is the actual score of the first player.
+func (g *Glicko2) Calculate(first, second *entity.UserElo, score float64) (*entity.UserElo, *entity.UserElo) {
+	return g.update(first, []*entity.UserElo{second}, []float64{score}),
+		g.update(second, []*entity.UserElo{first}, []float64{1 - score})
+}
+
+// CalculateMultiplayer returns the new ratings of players in the same order,
+// every player is rated against all other players within the same rating period.
+func (g *Glicko2) CalculateMultiplayer(userElos []*entity.UserElo, placements []int) []*entity.UserElo {
+	newUserElos := make([]*entity.UserElo, 0, len(userElos))
+	for i, userElo := range userElos {
+		if len(userElos) < 2 {
+			newUserElos = append(newUserElos, userElo.Clone())
+			continue
+		}
+
+		opponents := make([]*entity.UserElo, 0, len(userElos)-1)
+		scores := make([]float64, 0, len(userElos)-1)
+		for j, opponent := range userElos {
+			if i == j {
+				continue
+			}
+
+			opponents = append(opponents, opponent)
+			scores = append(scores, PlacementScore(placements[i], placements[j]))
+		}
+
+		newUserElos = append(newUserElos, g.update(userElo, opponents, scores))
+	}
+
+	return newUserElos
+}
+
+// WinProbability returns the expected probability of every player to finish first in the same order,
+// the rating differences are discounted by the combined deviation of the players as the Glicko-2 expected score.
+func (g *Glicko2) WinProbability(userElos []*entity.UserElo) []float64 {
+	if len(userElos) == 0 {
+		return nil
+	}
+
+	// The deviation of a pair of players is combined as the root sum of squares,
+	// so two players are discounted by their own combined deviation.
+	var variance float64
+	for _, userElo := range userElos {
+		phi := withDefaults(userElo).Deviation / glicko2Scale
+		variance += phi * phi
+	}
+	variance *= 2 / float64(len(userElos))
+	gPhi := 1 / math.Sqrt(1+3*variance/(math.Pi*math.Pi))
+
+	strengths := make([]float64, 0, len(userElos))
+	for _, userElo := range userElos {
+		mu := (float64(userElo.Elo) - glicko2Center) / glicko2Scale
+		strengths = append(strengths, gPhi*mu)
+	}
+
+	return winProbability(strengths)
+}
+
+// update returns the new rating of player after a rating period against opponents, scores are the actual scores of player.
+func (g *Glicko2) update(player *entity.UserElo, opponents []*entity.UserElo, scores []float64) *entity.UserElo {
+	player = withDefaults(player)
+
+	mu := (float64(player.Elo) - glicko2Center) / glicko2Scale
+	phi := player.Deviation / glicko2Scale
+
+	var vInv, improvement float64
+	for idx, opponent := range opponents {
+		opponent = withDefaults(opponent)
+		muJ := (float64(opponent.Elo) - glicko2Center) / glicko2Scale
+		phiJ := opponent.Deviation / glicko2Scale
+
+		gPhiJ := 1 / math.Sqrt(1+3*phiJ*phiJ/(math.Pi*math.Pi))
+		expected := 1 / (1 + math.Exp(-gPhiJ*(mu-muJ)))
+		vInv += gPhiJ * gPhiJ * expected * (1 - expected)
+		improvement += gPhiJ * (scores[idx] - expected)
+	}
+	v := 1 / vInv
+	delta := v * improvement
+
+	sigma := g.volatility(phi, player.Volatility, v, delta)
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	newMu := mu + newPhi*newPhi*improvement
+
+	newUserElo := player.Clone()
+	newUserElo.Elo = int(math.Round(newMu*glicko2Scale + glicko2Center))
+	newUserElo.Deviation = newPhi * glicko2Scale
+	newUserElo.Volatility = sigma
+
+	return newUserElo
+}
+
+// volatility computes the new volatility with the Illinois algorithm, boundA and boundB follow A and B of the Glicko-2 paper.
+func (g *Glicko2) volatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/(g.tau*g.tau)
+	}
+
+	boundA := a
+	var boundB float64
+	if delta*delta > phi*phi+v {
+		boundB = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*g.tau) < 0 {
+			k++
+		}
+		boundB = a - k*g.tau
+	}
+
+	fBoundA, fBoundB := f(boundA), f(boundB)
+	for math.Abs(boundB-boundA) > glicko2Epsilon {
+		c := boundA + (boundA-boundB)*fBoundA/(fBoundB-fBoundA)
+		fc := f(c)
+		if fc*fBoundB <= 0 {
+			boundA, fBoundA = boundB, fBoundB
+		} else {
+			fBoundA /= 2
+		}
+		boundB, fBoundB = c, fc
+	}
+
+	return math.Exp(boundA / 2)
+}
+
+// withDefaults returns a copy of userElo with default deviation and volatility if they are missing.
+func withDefaults(userElo *entity.UserElo) *entity.UserElo {
+	userElo = userElo.Clone()
+	userElo.Migrate()
+
+	return userElo
+}
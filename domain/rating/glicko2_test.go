@@ -0,0 +1,123 @@
+package rating
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+func TestGlicko2_Calculate(t *testing.T) {
+	type args struct {
+		first  *entity.UserElo
+		second *entity.UserElo
+		score  float64
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantFirst  *entity.UserElo
+		wantSecond *entity.UserElo
+	}{
+		{
+			name: "New players - first player wins",
+			args: args{
+				first:  entity.NewUserDefaultElo("user_1"),
+				second: entity.NewUserDefaultElo("user_2"),
+				score:  ScoreWin,
+			},
+			wantFirst:  &entity.UserElo{UserID: "user_1", Elo: 1162, Deviation: 290.3190, Volatility: 0.0600},
+			wantSecond: &entity.UserElo{UserID: "user_2", Elo: 838, Deviation: 290.3190, Volatility: 0.0600},
+		},
+		{
+			name: "New players - draw",
+			args: args{
+				first:  entity.NewUserDefaultElo("user_1"),
+				second: entity.NewUserDefaultElo("user_2"),
+				score:  ScoreDraw,
+			},
+			wantFirst:  &entity.UserElo{UserID: "user_1", Elo: 1000, Deviation: 290.3190, Volatility: 0.0600},
+			wantSecond: &entity.UserElo{UserID: "user_2", Elo: 1000, Deviation: 290.3190, Volatility: 0.0600},
+		},
+		{
+			name: "Established player loses to uncertain player",
+			args: args{
+				first:  &entity.UserElo{UserID: "user_1", Elo: 1500, Deviation: 50, Volatility: 0.06},
+				second: &entity.UserElo{UserID: "user_2", Elo: 1200, Deviation: 300, Volatility: 0.06},
+				score:  ScoreLose,
+			},
+			wantFirst:  &entity.UserElo{UserID: "user_1", Elo: 1492, Deviation: 50.8758, Volatility: 0.0600},
+			wantSecond: &entity.UserElo{UserID: "user_2", Elo: 1514, Deviation: 255.6303, Volatility: 0.0600},
+		},
+		{
+			name: "Legacy players use default deviation and volatility",
+			args: args{
+				first:  &entity.UserElo{UserID: "user_1", Elo: 1000},
+				second: &entity.UserElo{UserID: "user_2", Elo: 1000},
+				score:  ScoreWin,
+			},
+			wantFirst:  &entity.UserElo{UserID: "user_1", Elo: 1162, Deviation: 290.3190, Volatility: 0.0600},
+			wantSecond: &entity.UserElo{UserID: "user_2", Elo: 838, Deviation: 290.3190, Volatility: 0.0600},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, second := NewGlicko2(DefaultTau).Calculate(tt.args.first, tt.args.second, tt.args.score)
+			for _, pair := range [][2]*entity.UserElo{{tt.wantFirst, first}, {tt.wantSecond, second}} {
+				want, got := pair[0], pair[1]
+				assert.Equal(t, want.UserID, got.UserID)
+				assert.Equal(t, want.Elo, got.Elo)
+				assert.InDelta(t, want.Deviation, got.Deviation, 0.0001)
+				assert.InDelta(t, want.Volatility, got.Volatility, 0.0001)
+			}
+		})
+	}
+}
+
+func TestGlicko2_CalculateMultiplayer(t *testing.T) {
+	// The example of the Glicko-2 paper: the player beats the first opponent and loses to the others.
+	userElos := []*entity.UserElo{
+		{UserID: "user_1", Elo: 1500, Deviation: 200, Volatility: 0.06},
+		{UserID: "user_2", Elo: 1400, Deviation: 30, Volatility: 0.06},
+		{UserID: "user_3", Elo: 1550, Deviation: 100, Volatility: 0.06},
+		{UserID: "user_4", Elo: 1700, Deviation: 300, Volatility: 0.06},
+	}
+	placements := []int{2, 3, 1, 1}
+
+	got := NewGlicko2(DefaultTau).CalculateMultiplayer(userElos, placements)
+	assert.Len(t, got, len(userElos))
+	assert.Equal(t, "user_1", got[0].UserID)
+	assert.Equal(t, 1464, got[0].Elo)
+	assert.InDelta(t, 151.5165, got[0].Deviation, 0.001)
+	assert.InDelta(t, 0.05999, got[0].Volatility, 0.0001)
+
+	// Two players are rated as Calculate does.
+	first, second := NewGlicko2(DefaultTau).Calculate(userElos[0], userElos[1], ScoreWin)
+	assert.Equal(t, []*entity.UserElo{first, second},
+		NewGlicko2(DefaultTau).CalculateMultiplayer(userElos[:2], []int{1, 2}))
+}
+
+func TestGlicko2_WinProbability(t *testing.T) {
+	calculator := NewGlicko2(DefaultTau)
+
+	got := calculator.WinProbability([]*entity.UserElo{
+		entity.NewUserDefaultElo("user_1"),
+		entity.NewUserDefaultElo("user_2"),
+	})
+	assert.InDeltaSlice(t, []float64{0.5, 0.5}, got, 0.0001)
+
+	// Uncertain ratings pull the probability toward even odds.
+	certain := calculator.WinProbability([]*entity.UserElo{
+		{UserID: "user_1", Elo: 1700, Deviation: 50, Volatility: 0.06},
+		{UserID: "user_2", Elo: 1500, Deviation: 50, Volatility: 0.06},
+	})
+	uncertain := calculator.WinProbability([]*entity.UserElo{
+		{UserID: "user_1", Elo: 1700, Deviation: 350, Volatility: 0.06},
+		{UserID: "user_2", Elo: 1500, Deviation: 350, Volatility: 0.06},
+	})
+	assert.InDelta(t, 0.7546, certain[0], 0.0001)
+	assert.InDelta(t, 0.6498, uncertain[0], 0.0001)
+	assert.InDelta(t, 1, uncertain[0]+uncertain[1], 0.0001)
+}
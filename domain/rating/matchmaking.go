@@ -0,0 +1,108 @@
+package rating
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+const (
+	DefaultMatchmakingWindow           = 100
+	DefaultMatchmakingWideningStep     = 50
+	DefaultMatchmakingWideningInterval = 10 * time.Second
+	DefaultMatchmakingMaxWindow        = 500
+	DefaultMatchmakingQueueTimeout     = 10 * time.Minute
+)
+
+// MatchmakingConfig is a group of options for the matchmaking of queued users.
+type MatchmakingConfig struct {
+	// Window is the rating difference allowed between a user and a candidate when the user joins the queue,
+	// it widens by WideningStep every WideningInterval the user waits up to MaxWindow.
+	Window           int           `mapstructure:"window"`
+	WideningStep     int           `mapstructure:"widening_step"`
+	WideningInterval time.Duration `mapstructure:"widening_interval"`
+	MaxWindow        int           `mapstructure:"max_window"`
+	// QueueTimeout is the time a user stays queued without matching, the user joins again to keep waiting.
+	QueueTimeout time.Duration `mapstructure:"queue_timeout"`
+}
+
+// Matchmaking finds the candidates of a queued user within a rating window widening over the wait time.
+type Matchmaking struct {
+	window           int
+	wideningStep     int
+	wideningInterval time.Duration
+	maxWindow        int
+	queueTimeout     time.Duration
+}
+
+// NewMatchmaking creates and returns new instance of Matchmaking, non-positive options fall back to their default.
+// A zero widening step keeps the window.
+func NewMatchmaking(cfg *MatchmakingConfig) *Matchmaking {
+	m := &Matchmaking{
+		window:           cfg.Window,
+		wideningStep:     max(cfg.WideningStep, 0),
+		wideningInterval: cfg.WideningInterval,
+		maxWindow:        cfg.MaxWindow,
+		queueTimeout:     cfg.QueueTimeout,
+	}
+	if m.window <= 0 {
+		m.window = DefaultMatchmakingWindow
+	}
+	if m.wideningInterval <= 0 {
+		m.wideningInterval = DefaultMatchmakingWideningInterval
+	}
+	if m.maxWindow <= 0 {
+		m.maxWindow = DefaultMatchmakingMaxWindow
+	}
+	if m.queueTimeout <= 0 {
+		m.queueTimeout = DefaultMatchmakingQueueTimeout
+	}
+
+	return m
+}
+
+// QueueTimeout returns the time a user stays queued.
+func (m *Matchmaking) QueueTimeout() time.Duration {
+	return m.queueTimeout
+}
+
+// Window returns the rating window of a user queued for waited, a positive base replaces the configured window.
+// The window never widens beyond the max window, nor beyond a larger base.
+func (m *Matchmaking) Window(base int, waited time.Duration) int {
+	if base <= 0 {
+		base = m.window
+	}
+
+	widened := base + m.wideningStep*int(max(waited, 0)/m.wideningInterval)
+	return min(widened, max(m.maxWindow, base))
+}
+
+// Candidates ranks the users of queued other than user, which are rated within the window of user, and returns
+// at most size of them, the closest rating first and then the longest queued.
+func (m *Matchmaking) Candidates(user *entity.QueuedUser, queued []*entity.QueuedUser, size int) []*entity.QueuedUser {
+	var candidates []*entity.QueuedUser
+	for _, candidate := range queued {
+		if candidate.UserID != user.UserID {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	slices.SortStableFunc(candidates, func(a, b *entity.QueuedUser) int {
+		return cmp.Or(
+			cmp.Compare(abs(a.Elo-user.Elo), abs(b.Elo-user.Elo)),
+			cmp.Compare(a.JoinedAt, b.JoinedAt),
+		)
+	})
+
+	return candidates[:min(size, len(candidates))]
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
@@ -0,0 +1,70 @@
+package rating
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+func TestMatchmaking_Window(t *testing.T) {
+	matchmaking := NewMatchmaking(&MatchmakingConfig{
+		Window:           100,
+		WideningStep:     50,
+		WideningInterval: 10 * time.Second,
+		MaxWindow:        300,
+	})
+
+	tests := []struct {
+		name   string
+		base   int
+		waited time.Duration
+		want   int
+	}{
+		{name: "Just joined", waited: 0, want: 100},
+		{name: "Widens every interval", waited: 25 * time.Second, want: 200},
+		{name: "Capped by the max window", waited: time.Hour, want: 300},
+		{name: "Requested window widens as well", base: 20, waited: 10 * time.Second, want: 70},
+		{name: "Requested window above the max window", base: 400, waited: time.Hour, want: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchmaking.Window(tt.base, tt.waited))
+		})
+	}
+
+	// Missing options fall back to their default.
+	matchmaking = NewMatchmaking(&MatchmakingConfig{})
+	assert.Equal(t, DefaultMatchmakingWindow, matchmaking.Window(0, 0))
+	assert.Equal(t, DefaultMatchmakingQueueTimeout, matchmaking.QueueTimeout())
+}
+
+func TestMatchmaking_Candidates(t *testing.T) {
+	matchmaking := NewMatchmaking(&MatchmakingConfig{})
+	user := &entity.QueuedUser{UserID: "user_1", Elo: 1000, JoinedAt: 100}
+	queued := []*entity.QueuedUser{
+		{UserID: "user_2", Elo: 1100, JoinedAt: 10},
+		user,
+		{UserID: "user_3", Elo: 950, JoinedAt: 20},
+		{UserID: "user_4", Elo: 1050, JoinedAt: 30},
+		{UserID: "user_5", Elo: 1200, JoinedAt: 40},
+		{UserID: "user_6", Elo: 900, JoinedAt: 50},
+	}
+
+	assert.Equal(t, []*entity.QueuedUser{
+		{UserID: "user_3", Elo: 950, JoinedAt: 20},
+		{UserID: "user_4", Elo: 1050, JoinedAt: 30},
+		{UserID: "user_2", Elo: 1100, JoinedAt: 10},
+		{UserID: "user_6", Elo: 900, JoinedAt: 50},
+		{UserID: "user_5", Elo: 1200, JoinedAt: 40},
+	}, matchmaking.Candidates(user, queued, 10))
+
+	assert.Equal(t, []*entity.QueuedUser{
+		{UserID: "user_3", Elo: 950, JoinedAt: 20},
+	}, matchmaking.Candidates(user, queued, 1))
+
+	assert.Empty(t, matchmaking.Candidates(user, []*entity.QueuedUser{user}, 10))
+}
@@ -0,0 +1,92 @@
+package rating
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+const (
+	AlgorithmElo     = "elo"
+	AlgorithmGlicko2 = "glicko2"
+)
+
+// Actual scores of a player in a battle.
+const (
+	ScoreLose = 0.0
+	ScoreDraw = 0.5
+	ScoreWin  = 1.0
+)
+
+// RatingCalculator calculates new ratings of players after a battle.
+type RatingCalculator interface {
+	// Calculate returns the new ratings of both players, score is the actual score of the first player.
+	Calculate(first, second *entity.UserElo, score float64) (*entity.UserElo, *entity.UserElo)
+	// CalculateMultiplayer returns the new ratings of players in the same order,
+	// placements holds the finishing placement of every player where 1 is the first and ties are allowed.
+	CalculateMultiplayer(userElos []*entity.UserElo, placements []int) []*entity.UserElo
+	// WinProbability returns the expected probability of every player to finish first in the same order.
+	WinProbability(userElos []*entity.UserElo) []float64
+}
+
+// PlacementScore returns the actual score of a player finishing at placement against an opponent finishing at opponentPlacement.
+func PlacementScore(placement, opponentPlacement int) float64 {
+	switch {
+	case placement < opponentPlacement:
+		return ScoreWin
+	case placement > opponentPlacement:
+		return ScoreLose
+	default:
+		return ScoreDraw
+	}
+}
+
+// winProbability returns the probability of every player to finish first with the Bradley-Terry model,
+// the probability is proportional to the exponential of the strength of the player.
+func winProbability(strengths []float64) []float64 {
+	if len(strengths) == 0 {
+		return nil
+	}
+
+	// Shifting the strengths by the strongest one keeps the exponentials finite.
+	strongest := strengths[0]
+	for _, strength := range strengths {
+		strongest = max(strongest, strength)
+	}
+
+	var total float64
+	probabilities := make([]float64, 0, len(strengths))
+	for _, strength := range strengths {
+		probability := math.Exp(strength - strongest)
+		probabilities = append(probabilities, probability)
+		total += probability
+	}
+
+	for idx := range probabilities {
+		probabilities[idx] /= total
+	}
+
+	return probabilities
+}
+
+// Config is a group of options for the rating calculator.
+type Config struct {
+	Algorithm       string          `mapstructure:"algorithm"`
+	KFactor         float64         `mapstructure:"k_factor"`
+	KFactorSchedule KFactorSchedule `mapstructure:",squash"`
+	Tau             float64         `mapstructure:"tau"`
+	TeamAggregation string          `mapstructure:"team_aggregation"`
+}
+
+// New creates and returns the RatingCalculator selected by cfg.Algorithm.
+func New(cfg *Config) (RatingCalculator, error) {
+	switch cfg.Algorithm {
+	case "", AlgorithmElo:
+		return NewScheduledElo(cfg.KFactor, cfg.KFactorSchedule), nil
+	case AlgorithmGlicko2:
+		return NewGlicko2(cfg.Tau), nil
+	default:
+		return nil, fmt.Errorf("unsupported rating algorithm: %s", cfg.Algorithm)
+	}
+}
@@ -0,0 +1,58 @@
+package rating
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *Config
+		want    RatingCalculator
+		wantErr bool
+	}{
+		{
+			name: "Default algorithm is elo",
+			cfg:  &Config{},
+			want: NewElo(DefaultKFactor),
+		},
+		{
+			name: "Elo with custom K-factor",
+			cfg:  &Config{Algorithm: AlgorithmElo, KFactor: 16},
+			want: NewElo(16),
+		},
+		{
+			name: "Elo with K-factor schedule",
+			cfg: &Config{
+				Algorithm:       AlgorithmElo,
+				KFactorSchedule: KFactorSchedule{ProvisionalGames: 10, ProvisionalKFactor: 64},
+			},
+			want: NewScheduledElo(DefaultKFactor, KFactorSchedule{ProvisionalGames: 10, ProvisionalKFactor: 64}),
+		},
+		{
+			name: "Glicko-2 with default tau",
+			cfg:  &Config{Algorithm: AlgorithmGlicko2},
+			want: NewGlicko2(DefaultTau),
+		},
+		{
+			name:    "Unsupported algorithm",
+			cfg:     &Config{Algorithm: "unknown"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
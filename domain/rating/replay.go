@@ -0,0 +1,99 @@
+package rating
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+// Replay recomputes the ratings of every user by rating the battles of the battle log again in order.
+//
+// Voided battles are skipped and decays are not replayed. When the season of the log changes,
+// the ratings are carried over to the new season by the soft reset.
+type Replay struct {
+	teamCalculator *TeamCalculator
+	softReset      *SoftReset
+	season         string
+	userElos       map[string]*entity.UserElo
+	voided         map[string]bool
+}
+
+// NewReplay creates and returns new instance of Replay.
+func NewReplay(teamCalculator *TeamCalculator, softReset *SoftReset) *Replay {
+	return &Replay{
+		teamCalculator: teamCalculator,
+		softReset:      softReset,
+		userElos:       make(map[string]*entity.UserElo),
+		voided:         make(map[string]bool),
+	}
+}
+
+// Void excludes a battle from the replay, it must be called before the battle is applied.
+func (r *Replay) Void(battleID string) {
+	r.voided[battleID] = true
+}
+
+// Apply rates the battle of entry from the replayed ratings of its members and reports whether it was rated.
+// Entries which are not battles and voided battles are skipped.
+func (r *Replay) Apply(entry *entity.BattleLog) bool {
+	if entry.Kind != entity.BattleLogKindBattle || r.voided[entry.BattleID] ||
+		len(entry.Teams) < 2 || len(entry.Teams) != len(entry.Placements) {
+		return false
+	}
+
+	r.Rollover(entry.Season)
+	teams := make([][]*entity.UserElo, 0, len(entry.Teams))
+	for _, members := range entry.Teams {
+		team := make([]*entity.UserElo, 0, len(members))
+		for _, userID := range members {
+			userElo, ok := r.userElos[userID]
+			if !ok {
+				userElo = entity.NewUserDefaultElo(userID)
+			}
+
+			team = append(team, userElo)
+		}
+
+		teams = append(teams, team)
+	}
+
+	for _, team := range r.teamCalculator.Calculate(teams, entry.Placements) {
+		for _, userElo := range team {
+			userElo.AddGame()
+			r.userElos[userElo.UserID] = userElo
+		}
+	}
+
+	return true
+}
+
+// Rollover carries the replayed ratings over to season with the soft reset, nothing changes within the same season.
+func (r *Replay) Rollover(season string) {
+	if season == r.season {
+		return
+	}
+
+	for userID, userElo := range r.userElos {
+		r.userElos[userID] = r.softReset.Reset(userElo)
+	}
+	r.season = season
+}
+
+// Season returns the season of the replayed ratings.
+func (r *Replay) Season() string {
+	return r.season
+}
+
+// UserElos returns the replayed ratings ordered by user id.
+func (r *Replay) UserElos() []*entity.UserElo {
+	userElos := make([]*entity.UserElo, 0, len(r.userElos))
+	for _, userElo := range r.userElos {
+		userElos = append(userElos, userElo)
+	}
+	slices.SortFunc(userElos, func(a, b *entity.UserElo) int {
+		return strings.Compare(a.UserID, b.UserID)
+	})
+
+	return userElos
+}
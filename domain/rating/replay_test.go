@@ -0,0 +1,59 @@
+package rating
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+func TestReplay_Apply(t *testing.T) {
+	replay := NewReplay(NewTeamCalculator(NewElo(20), AggregateAverage), NewSoftReset(0.5))
+	replay.Void("battle_2")
+
+	entries := []*entity.BattleLog{
+		{Kind: entity.BattleLogKindBattle, BattleID: "battle_1", Teams: [][]string{{"user_1"}, {"user_2"}}, Placements: []int{1, 2}},
+		{Kind: entity.BattleLogKindBattle, BattleID: "battle_2", Teams: [][]string{{"user_1"}, {"user_2"}}, Placements: []int{1, 2}},
+		{Kind: entity.BattleLogKindVoid, BattleID: "battle_2"},
+		{Kind: entity.BattleLogKindBattle, BattleID: "battle_3", Teams: [][]string{{"user_2"}, {"user_3"}}, Placements: []int{1, 1}},
+	}
+	var rated []bool
+	for _, entry := range entries {
+		rated = append(rated, replay.Apply(entry))
+	}
+
+	assert.Equal(t, []bool{true, false, false, true}, rated)
+	assert.Equal(t, []*entity.UserElo{
+		{UserID: "user_1", Elo: 1010, Deviation: entity.DefaultDeviation, Volatility: entity.DefaultVolatility, GamesPlayed: 1},
+		{UserID: "user_2", Elo: 990, Deviation: entity.DefaultDeviation, Volatility: entity.DefaultVolatility, GamesPlayed: 2},
+		{UserID: "user_3", Elo: 1000, Deviation: entity.DefaultDeviation, Volatility: entity.DefaultVolatility, GamesPlayed: 1},
+	}, replay.UserElos())
+}
+
+func TestReplay_Rollover(t *testing.T) {
+	replay := NewReplay(NewTeamCalculator(NewElo(20), AggregateAverage), NewSoftReset(0.5))
+	replay.Apply(&entity.BattleLog{
+		Kind:       entity.BattleLogKindBattle,
+		BattleID:   "battle_1",
+		Season:     "s1",
+		Teams:      [][]string{{"user_1"}, {"user_2"}},
+		Placements: []int{1, 2},
+	})
+	replay.Apply(&entity.BattleLog{
+		Kind:       entity.BattleLogKindBattle,
+		BattleID:   "battle_2",
+		Season:     "s2",
+		Teams:      [][]string{{"user_1"}, {"user_3"}},
+		Placements: []int{2, 1},
+	})
+
+	// The ratings of s1 are soft reset before the battle of s2 is rated.
+	assert.Equal(t, "s2", replay.Season())
+	userElos := replay.UserElos()
+	assert.Equal(t, []int{995, 995, 1010}, []int{userElos[0].Elo, userElos[1].Elo, userElos[2].Elo})
+
+	replay.Rollover("s3")
+	userElos = replay.UserElos()
+	assert.Equal(t, []int{997, 997, 1005}, []int{userElos[0].Elo, userElos[1].Elo, userElos[2].Elo})
+}
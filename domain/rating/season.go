@@ -0,0 +1,37 @@
+package rating
+
+import (
+	"math"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+// SeasonConfig is a group of options for ranked seasons.
+type SeasonConfig struct {
+	// ID is the current season until the first rollover, an empty id keeps the ratings stored before seasons.
+	ID          string  `mapstructure:"id"`
+	ResetFactor float64 `mapstructure:"reset_factor"`
+}
+
+// SoftReset pulls ratings toward the default rating when a new season starts.
+type SoftReset struct {
+	factor float64
+}
+
+// NewSoftReset creates and returns new instance of SoftReset, factor is clamped between 0 keeping
+// the ratings and 1 resetting them to the default.
+func NewSoftReset(factor float64) *SoftReset {
+	return &SoftReset{
+		factor: min(max(factor, 0), 1),
+	}
+}
+
+// Reset returns the rating of a user carried over to a new season,
+// the elo and the deviation are pulled toward their default values by the reset factor.
+func (r *SoftReset) Reset(userElo *entity.UserElo) *entity.UserElo {
+	newUserElo := withDefaults(userElo)
+	newUserElo.Elo = entity.DefaultElo + int(math.Round(float64(newUserElo.Elo-entity.DefaultElo)*(1-r.factor)))
+	newUserElo.Deviation += (entity.DefaultDeviation - newUserElo.Deviation) * r.factor
+
+	return newUserElo
+}
@@ -0,0 +1,55 @@
+package rating
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+func TestSoftReset_Reset(t *testing.T) {
+	tests := []struct {
+		name    string
+		factor  float64
+		userElo *entity.UserElo
+		want    *entity.UserElo
+	}{
+		{
+			name:    "Half way toward the default",
+			factor:  0.5,
+			userElo: &entity.UserElo{UserID: "user_1", Elo: 1400, Deviation: 50, Volatility: 0.05},
+			want:    &entity.UserElo{UserID: "user_1", Elo: 1200, Deviation: 200, Volatility: 0.05},
+		},
+		{
+			name:    "Below the default",
+			factor:  0.5,
+			userElo: &entity.UserElo{UserID: "user_1", Elo: 801, Deviation: 150, Volatility: 0.06},
+			want:    &entity.UserElo{UserID: "user_1", Elo: 900, Deviation: 250, Volatility: 0.06},
+		},
+		{
+			name:    "Zero factor keeps the rating",
+			factor:  0,
+			userElo: &entity.UserElo{UserID: "user_1", Elo: 1400, Deviation: 50, Volatility: 0.05},
+			want:    &entity.UserElo{UserID: "user_1", Elo: 1400, Deviation: 50, Volatility: 0.05},
+		},
+		{
+			name:    "Factor above one resets to the default",
+			factor:  2,
+			userElo: &entity.UserElo{UserID: "user_1", Elo: 1400, Deviation: 50, Volatility: 0.05},
+			want:    &entity.UserElo{UserID: "user_1", Elo: entity.DefaultElo, Deviation: entity.DefaultDeviation, Volatility: 0.05},
+		},
+		{
+			name:    "Legacy user gets the default deviation and volatility",
+			factor:  0.5,
+			userElo: &entity.UserElo{UserID: "user_1", Elo: 1200},
+			want:    &entity.UserElo{UserID: "user_1", Elo: 1100, Deviation: entity.DefaultDeviation, Volatility: entity.DefaultVolatility},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewSoftReset(tt.factor).Reset(tt.userElo))
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package rating
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+const (
+	AggregationAverage = "average"
+	AggregationMax     = "max"
+	AggregationMin     = "min"
+)
+
+// Aggregation aggregates the ratings of the members of a team into the rating of the team.
+type Aggregation func(members []*entity.UserElo) *entity.UserElo
+
+// NewAggregation returns the Aggregation selected by name, an empty name falls back to AggregationAverage.
+func NewAggregation(name string) (Aggregation, error) {
+	switch name {
+	case "", AggregationAverage:
+		return AggregateAverage, nil
+	case AggregationMax:
+		return AggregateMax, nil
+	case AggregationMin:
+		return AggregateMin, nil
+	default:
+		return nil, fmt.Errorf("unsupported team aggregation: %s", name)
+	}
+}
+
+// AggregateAverage rates a team with the average rating of its members,
+// the deviation is the root mean square of the member deviations.
+// The games played are unknown if the games of some member are unknown.
+func AggregateAverage(members []*entity.UserElo) *entity.UserElo {
+	var elo, variance, volatility, gamesPlayed float64
+	knownGames := true
+	for _, member := range members {
+		member = withDefaults(member)
+		elo += float64(member.Elo)
+		variance += member.Deviation * member.Deviation
+		volatility += member.Volatility
+		gamesPlayed += float64(member.GamesPlayed)
+		knownGames = knownGames && member.GamesPlayed != entity.UnknownGamesPlayed
+	}
+
+	count := float64(len(members))
+	teamElo := &entity.UserElo{
+		Elo:         int(math.Round(elo / count)),
+		Deviation:   math.Sqrt(variance / count),
+		Volatility:  volatility / count,
+		GamesPlayed: int(math.Round(gamesPlayed / count)),
+	}
+	if !knownGames {
+		teamElo.GamesPlayed = entity.UnknownGamesPlayed
+	}
+
+	return teamElo
+}
+
+// AggregateMax rates a team with the rating of its strongest member.
+func AggregateMax(members []*entity.UserElo) *entity.UserElo {
+	strongest := members[0]
+	for _, member := range members[1:] {
+		if member.Elo > strongest.Elo {
+			strongest = member
+		}
+	}
+
+	return withDefaults(strongest)
+}
+
+// AggregateMin rates a team with the rating of its weakest member.
+func AggregateMin(members []*entity.UserElo) *entity.UserElo {
+	weakest := members[0]
+	for _, member := range members[1:] {
+		if member.Elo < weakest.Elo {
+			weakest = member
+		}
+	}
+
+	return withDefaults(weakest)
+}
+
+// TeamCalculator calculates new ratings of team members by rating every team as a single player.
+type TeamCalculator struct {
+	calculator RatingCalculator
+	aggregate  Aggregation
+}
+
+// NewTeamCalculator creates and returns new instance of TeamCalculator.
+func NewTeamCalculator(calculator RatingCalculator, aggregate Aggregation) *TeamCalculator {
+	return &TeamCalculator{
+		calculator: calculator,
+		aggregate:  aggregate,
+	}
+}
+
+// Calculate returns the new ratings of the members of every team in the same order,
+// placements holds the finishing placement of every team where 1 is the first and ties are allowed.
+//
+// Every member gets the rating change of the team, the deviation and volatility of the members are scaled
+// as the ones of the team. A team of a single member is rated as the member itself.
+func (t *TeamCalculator) Calculate(teams [][]*entity.UserElo, placements []int) [][]*entity.UserElo {
+	teamElos := t.teamElos(teams)
+	newTeamElos := t.calculator.CalculateMultiplayer(teamElos, placements)
+	newTeams := make([][]*entity.UserElo, 0, len(teams))
+	for teamIdx, members := range teams {
+		teamElo, newTeamElo := teamElos[teamIdx], newTeamElos[teamIdx]
+		if len(members) == 1 {
+			newTeams = append(newTeams, []*entity.UserElo{newTeamElo})
+			continue
+		}
+
+		newMembers := make([]*entity.UserElo, 0, len(members))
+		for _, member := range members {
+			newMember := member.Clone()
+			newMember.Elo += newTeamElo.Elo - teamElo.Elo
+			if teamElo.Deviation > 0 && teamElo.Volatility > 0 {
+				newMember.Deviation *= newTeamElo.Deviation / teamElo.Deviation
+				newMember.Volatility *= newTeamElo.Volatility / teamElo.Volatility
+			}
+
+			newMembers = append(newMembers, newMember)
+		}
+
+		newTeams = append(newTeams, newMembers)
+	}
+
+	return newTeams
+}
+
+// WinProbability returns the expected probability of every team to finish first in the same order.
+func (t *TeamCalculator) WinProbability(teams [][]*entity.UserElo) []float64 {
+	return t.calculator.WinProbability(t.teamElos(teams))
+}
+
+// Preview is the expected outcome of a battle for a team.
+type Preview struct {
+	WinProbability float64
+	// Win, Draw and Lose hold the new ratings of the members if the team finishes first alone,
+	// if all teams tie, or if the team finishes last alone.
+	Win  []*entity.UserElo
+	Draw []*entity.UserElo
+	Lose []*entity.UserElo
+}
+
+// Preview returns the expected outcome of a battle for every team in the same order without changing any rating.
+func (t *TeamCalculator) Preview(teams [][]*entity.UserElo) []*Preview {
+	placements := make([]int, len(teams))
+	for idx := range placements {
+		placements[idx] = 1
+	}
+	draw := t.Calculate(teams, placements)
+
+	previews := make([]*Preview, 0, len(teams))
+	for teamIdx, probability := range t.WinProbability(teams) {
+		for idx := range placements {
+			placements[idx] = 2
+		}
+		placements[teamIdx] = 1
+		win := t.Calculate(teams, placements)
+
+		for idx := range placements {
+			placements[idx] = 1
+		}
+		placements[teamIdx] = 2
+		lose := t.Calculate(teams, placements)
+
+		previews = append(previews, &Preview{
+			WinProbability: probability,
+			Win:            win[teamIdx],
+			Draw:           draw[teamIdx],
+			Lose:           lose[teamIdx],
+		})
+	}
+
+	return previews
+}
+
+// teamElos returns the rating of every team, a team of a single member is rated as the member itself.
+func (t *TeamCalculator) teamElos(teams [][]*entity.UserElo) []*entity.UserElo {
+	teamElos := make([]*entity.UserElo, 0, len(teams))
+	for _, members := range teams {
+		if len(members) == 1 {
+			teamElos = append(teamElos, members[0])
+			continue
+		}
+
+		teamElos = append(teamElos, t.aggregate(members))
+	}
+
+	return teamElos
+}
@@ -0,0 +1,130 @@
+package rating
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+func TestNewAggregation(t *testing.T) {
+	members := []*entity.UserElo{
+		{UserID: "user_1", Elo: 1000, Deviation: 300, Volatility: 0.06},
+		{UserID: "user_2", Elo: 1300, Deviation: 400, Volatility: 0.08},
+	}
+	tests := []struct {
+		name        string
+		aggregation string
+		want        *entity.UserElo
+		wantErr     bool
+	}{
+		{
+			name: "Default aggregation is average",
+			want: &entity.UserElo{Elo: 1150, Deviation: 353.5534, Volatility: 0.07},
+		},
+		{
+			name:        "Strongest member",
+			aggregation: AggregationMax,
+			want:        &entity.UserElo{UserID: "user_2", Elo: 1300, Deviation: 400, Volatility: 0.08},
+		},
+		{
+			name:        "Weakest member",
+			aggregation: AggregationMin,
+			want:        &entity.UserElo{UserID: "user_1", Elo: 1000, Deviation: 300, Volatility: 0.06},
+		},
+		{
+			name:        "Unsupported aggregation",
+			aggregation: "unknown",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregate, err := NewAggregation(tt.aggregation)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			got := aggregate(members)
+			assert.Equal(t, tt.want.UserID, got.UserID)
+			assert.Equal(t, tt.want.Elo, got.Elo)
+			assert.InDelta(t, tt.want.Deviation, got.Deviation, 0.0001)
+			assert.InDelta(t, tt.want.Volatility, got.Volatility, 0.0001)
+		})
+	}
+}
+
+func TestTeamCalculator_Calculate(t *testing.T) {
+	first := &entity.UserElo{UserID: "user_1", Elo: 1500, Deviation: 50, Volatility: 0.06}
+	second := &entity.UserElo{UserID: "user_2", Elo: 1200, Deviation: 300, Volatility: 0.06}
+
+	// Teams of a single member are rated as the members themselves.
+	calculator := NewTeamCalculator(NewGlicko2(DefaultTau), AggregateAverage)
+	wantFirst, wantSecond := NewGlicko2(DefaultTau).Calculate(first, second, ScoreLose)
+	got := calculator.Calculate([][]*entity.UserElo{{first}, {second}}, []int{2, 1})
+	assert.Equal(t, [][]*entity.UserElo{{wantFirst}, {wantSecond}}, got)
+
+	// Every member gets the rating change of the team.
+	calculator = NewTeamCalculator(NewElo(32), AggregateAverage)
+	got = calculator.Calculate([][]*entity.UserElo{
+		{{UserID: "user_1", Elo: 1000}, {UserID: "user_2", Elo: 1200}},
+		{{UserID: "user_3", Elo: 1100}, {UserID: "user_4", Elo: 1100}, {UserID: "user_5", Elo: 1100}},
+	}, []int{2, 1})
+	assert.Equal(t, [][]*entity.UserElo{
+		{{UserID: "user_1", Elo: 984}, {UserID: "user_2", Elo: 1184}},
+		{{UserID: "user_3", Elo: 1116}, {UserID: "user_4", Elo: 1116}, {UserID: "user_5", Elo: 1116}},
+	}, got)
+
+	// Members of a team are scaled to the new deviation and volatility of the team.
+	calculator = NewTeamCalculator(NewGlicko2(DefaultTau), AggregateAverage)
+	got = calculator.Calculate([][]*entity.UserElo{
+		{entity.NewUserDefaultElo("user_1"), entity.NewUserDefaultElo("user_2")},
+		{entity.NewUserDefaultElo("user_3"), entity.NewUserDefaultElo("user_4")},
+	}, []int{1, 2})
+	for _, member := range got[0] {
+		assert.Equal(t, 1162, member.Elo)
+		assert.InDelta(t, 290.3190, member.Deviation, 0.0001)
+	}
+	for _, member := range got[1] {
+		assert.Equal(t, 838, member.Elo)
+		assert.InDelta(t, 290.3190, member.Deviation, 0.0001)
+	}
+}
+
+func TestTeamCalculator_Preview(t *testing.T) {
+	calculator := NewTeamCalculator(NewElo(32), AggregateAverage)
+	teams := [][]*entity.UserElo{
+		{{UserID: "user_1", Elo: 1000}, {UserID: "user_2", Elo: 1200}},
+		{{UserID: "user_3", Elo: 1100}},
+	}
+
+	got := calculator.Preview(teams)
+	assert.Len(t, got, 2)
+	assert.InDelta(t, 0.5, got[0].WinProbability, 0.0001)
+	assert.Equal(t, []*entity.UserElo{{UserID: "user_1", Elo: 1016}, {UserID: "user_2", Elo: 1216}}, got[0].Win)
+	assert.Equal(t, []*entity.UserElo{{UserID: "user_1", Elo: 1000}, {UserID: "user_2", Elo: 1200}}, got[0].Draw)
+	assert.Equal(t, []*entity.UserElo{{UserID: "user_1", Elo: 984}, {UserID: "user_2", Elo: 1184}}, got[0].Lose)
+	assert.Equal(t, []*entity.UserElo{{UserID: "user_3", Elo: 1116}}, got[1].Win)
+	assert.Equal(t, []*entity.UserElo{{UserID: "user_3", Elo: 1084}}, got[1].Lose)
+
+	// The ratings of the teams are left untouched.
+	assert.Equal(t, 1000, teams[0][0].Elo)
+	assert.Equal(t, 1100, teams[1][0].Elo)
+
+	// In a free-for-all a team wins by finishing first alone and loses by finishing last alone.
+	got = NewTeamCalculator(NewElo(32), AggregateAverage).Preview([][]*entity.UserElo{
+		{{UserID: "user_1", Elo: 1000}},
+		{{UserID: "user_2", Elo: 1000}},
+		{{UserID: "user_3", Elo: 1000}},
+	})
+	for _, preview := range got {
+		assert.InDelta(t, 1.0/3, preview.WinProbability, 0.0001)
+		assert.Equal(t, 1016, preview.Win[0].Elo)
+		assert.Equal(t, 1000, preview.Draw[0].Elo)
+		assert.Equal(t, 984, preview.Lose[0].Elo)
+	}
+}
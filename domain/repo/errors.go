@@ -0,0 +1,18 @@
+package repo
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when the requested resource does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is returned when the resource to create already exists.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrVoided is returned when the battle to void is already voided.
+	ErrVoided = errors.New("voided")
+	// ErrSeasonEnded is returned when the battle to void belongs to an ended season.
+	ErrSeasonEnded = errors.New("season ended")
+	// ErrUnnamedSeason is returned when the current season to end has no id, so it could not be addressed once ended.
+	ErrUnnamedSeason = errors.New("current season has no id")
+	// ErrConflict is returned when an update keeps conflicting with concurrent updates.
+	ErrConflict = errors.New("conflict with concurrent update")
+)
@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+// BattleFunc calculates a battle from the current elos of its users and returns it with the new elos.
+type BattleFunc func(userElos []*entity.UserElo) (*entity.Battle, []*entity.UserElo, error)
+
+// SeasonResetFunc returns the elo of a user carried over from the ended season to the new one.
+type SeasonResetFunc func(userElo *entity.UserElo) *entity.UserElo
+
+// DecayFunc returns the decayed elo of an inactive user or nil if the elo does not decay.
+type DecayFunc func(userElo *entity.UserElo) *entity.UserElo
+
+// RatingRepo provides methods for storing the ratings, battles and seasons of the users.
+//
+// Elos and the leaderboard belong to the current season, the other seasons are read by the Season methods.
+type RatingRepo interface {
+	GetUserElo(ctx context.Context, userID string) (*entity.UserElo, error)
+	// ListUserElos returns the elos of userIDs in the same order, unknown users get the default elo.
+	ListUserElos(ctx context.Context, userIDs []string) ([]*entity.UserElo, error)
+	BatchUpdateElo(ctx context.Context, elos []*entity.UserElo) error
+	// GetBattle returns the processed battle by id or ErrNotFound.
+	GetBattle(ctx context.Context, battleID string) (*entity.Battle, error)
+	// ApplyBattle atomically loads the current elos of userIDs in the same order, passes them to fn and
+	// stores the returned elos and battle, the battle is kept for the configured retention window.
+	// The users are active from the time of their rewards on.
+	// The battle is appended to the battle log. fn may be called again when the elos change concurrently, the
+	// battle it returned last is returned as is once stored. If the battle was already processed, the stored
+	// battle is returned and fn is not called.
+	ApplyBattle(ctx context.Context, battleID string, userIDs []string, fn BattleFunc) (*entity.Battle, error)
+	// VoidBattle atomically reverses the elo change of every user rewarded by a processed battle in its season and
+	// marks the battle voided at voidedAt, the void is appended to the battle log. It returns the voided battle,
+	// ErrNotFound if the battle is unknown or out of the retention window, ErrVoided if it is already voided, or
+	// ErrSeasonEnded if its season is not the current one, whose final standings are kept.
+	VoidBattle(ctx context.Context, battleID string, voidedAt int64) (*entity.Battle, error)
+	// ListBattleLog returns at most limit entries of the battle log after the entry id after, oldest first.
+	// An empty after starts from the first entry.
+	ListBattleLog(ctx context.Context, after string, limit int64) ([]*entity.BattleLog, error)
+	// StoreReplay replaces the elos and the leaderboard of a season in the replay namespace with elos.
+	StoreReplay(ctx context.Context, namespace, season string, elos []*entity.UserElo) error
+	// SwapReplay atomically replaces the elos and the leaderboard of the current season with the ones of the replay
+	// namespace. It returns ErrNotFound if the namespace holds no elos of season, or ErrConflict if season is not
+	// the current season or the battle log has grown past lastLogID, the id of its last replayed entry.
+	SwapReplay(ctx context.Context, namespace, season, lastLogID string) error
+	// JoinQueue queues a user for matchmaking at the unix timestamp joinedAt, a user queued since the unix
+	// timestamp since keeps the time they joined at. The users queued before since are removed from the queue.
+	// It returns the queued user with their elo.
+	JoinQueue(ctx context.Context, userID string, joinedAt, since int64) (*entity.QueuedUser, error)
+	// LeaveQueue removes a user from the matchmaking queue or returns ErrNotFound if the user is not queued.
+	LeaveQueue(ctx context.Context, userID string) error
+	// GetQueuedUser returns a user queued since the unix timestamp since with their elo or ErrNotFound if the user
+	// is not queued.
+	GetQueuedUser(ctx context.Context, userID string, since int64) (*entity.QueuedUser, error)
+	// ListQueuedUsers returns the users queued since the unix timestamp since and rated between minElo and maxElo
+	// inclusive with their elo, the longest queued first. The users queued before since are removed from the queue.
+	ListQueuedUsers(ctx context.Context, since int64, minElo, maxElo int) ([]*entity.QueuedUser, error)
+	// ListUserRanks returns at most limit users of the leaderboard starting from the 0-based offset.
+	ListUserRanks(ctx context.Context, offset, limit int64) ([]*entity.UserRank, error)
+	// GetUserRank returns the leaderboard position of a user or ErrNotFound if the user is not ranked yet.
+	GetUserRank(ctx context.Context, userID string) (*entity.UserRank, error)
+	// ListRatingHistory returns at most limit history entries of a user from the 0-based offset, newest first.
+	// The entries are created between the unix timestamps from and to inclusive, a non-positive bound is open.
+	ListRatingHistory(ctx context.Context, userID string, from, to int64, offset, limit int64) ([]*entity.RatingHistory, error)
+	// ListInactiveUserIDs returns at most limit users of the current season inactive since the unix timestamp before
+	// from the 0-based offset, the longest inactive first.
+	ListInactiveUserIDs(ctx context.Context, before, offset, limit int64) ([]string, error)
+	// ApplyDecay atomically decays the elo of a user inactive since before with fn and records the decay in the
+	// history at decayedAt, the inactivity of the user is then counted from period seconds later. It returns the
+	// history entry, nil without writing anything if the elo does not decay, or ErrNotFound if the user is not
+	// inactive.
+	ApplyDecay(ctx context.Context, userID string, before, period, decayedAt int64, fn DecayFunc) (*entity.RatingHistory, error)
+	// GetCurrentSeason returns the current season.
+	GetCurrentSeason(ctx context.Context) (*entity.Season, error)
+	// GetSeason returns the current or an ended season by id or ErrNotFound.
+	GetSeason(ctx context.Context, seasonID string) (*entity.Season, error)
+	// ListSeasons returns the ended seasons, latest ended first.
+	ListSeasons(ctx context.Context) ([]*entity.Season, error)
+	// RolloverSeason atomically ends the current season at endedAt and starts seasonID with the elos of every
+	// user carried over by fn, the standings of the ended season are kept. It returns the ended season,
+	// ErrAlreadyExists if seasonID is the current or an ended season, or ErrUnnamedSeason if the current season
+	// has no id.
+	RolloverSeason(ctx context.Context, seasonID string, endedAt int64, fn SeasonResetFunc) (*entity.Season, error)
+	// ListSeasonUserRanks returns at most limit users of the leaderboard of a season starting from the 0-based offset.
+	ListSeasonUserRanks(ctx context.Context, seasonID string, offset, limit int64) ([]*entity.UserRank, error)
+	// GetSeasonUserRank returns the leaderboard position of a user in a season or ErrNotFound if the user is not ranked.
+	GetSeasonUserRank(ctx context.Context, seasonID, userID string) (*entity.UserRank, error)
+}
@@ -1,12 +1,18 @@
 package cache
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 
 	"github.com/me0den/example-service/infra/config"
+	"github.com/me0den/example-service/infra/health"
 	mRedis "github.com/me0den/example-service/x/redis"
 )
 
@@ -14,11 +20,38 @@ var RedisFXModule = fx.Provide(
 	NewRedis,
 )
 
-func NewRedis(cfg *config.Config) (*redis.Client, error) {
-	client, err := mRedis.New(&cfg.Redis)
+// NewRedis connects to Redis when the ratings are stored or cached in it and returns nil otherwise,
+// the connected client is checked by the readiness probe, its commands are observed in metricsRegistry and
+// traced with tp when the tracing is enabled.
+func NewRedis(
+	cfg *config.Config,
+	logger *slog.Logger,
+	registry *health.Registry,
+	metricsRegistry *prometheus.Registry,
+	tp trace.TracerProvider,
+) (redis.UniversalClient, error) {
+	if !cfg.UsesRedis() {
+		return nil, nil
+	}
+
+	client, err := mRedis.New(&cfg.Redis, logger)
 	if err != nil {
 		return nil, fmt.Errorf("error when init redis client: %v", err)
 	}
 
+	if err := mRedis.InstrumentMetrics(client.Client(), metricsRegistry); err != nil {
+		return nil, fmt.Errorf("error when register redis metrics: %v", err)
+	}
+
+	if cfg.Tracing.Enabled {
+		if err := redisotel.InstrumentTracing(client.Client(), redisotel.WithTracerProvider(tp)); err != nil {
+			return nil, fmt.Errorf("error when instrument redis tracing: %v", err)
+		}
+	}
+
+	registry.Register("redis", func(ctx context.Context) error {
+		return client.Client().Ping(ctx).Err()
+	})
+
 	return client.Client(), nil
 }
@@ -0,0 +1,43 @@
+package calculator
+
+import (
+	"fmt"
+
+	"go.uber.org/fx"
+
+	"github.com/me0den/example-service/domain/rating"
+	"github.com/me0den/example-service/infra/config"
+)
+
+var RatingFXModule = fx.Provide(
+	NewRatingCalculator,
+	NewTeamCalculator,
+	NewSoftReset,
+	NewMatchmaking,
+)
+
+func NewRatingCalculator(cfg *config.Config) (rating.RatingCalculator, error) {
+	calculator, err := rating.New(&cfg.Rating)
+	if err != nil {
+		return nil, fmt.Errorf("error when init rating calculator: %v", err)
+	}
+
+	return calculator, nil
+}
+
+func NewTeamCalculator(cfg *config.Config, calculator rating.RatingCalculator) (*rating.TeamCalculator, error) {
+	aggregate, err := rating.NewAggregation(cfg.Rating.TeamAggregation)
+	if err != nil {
+		return nil, fmt.Errorf("error when init team calculator: %v", err)
+	}
+
+	return rating.NewTeamCalculator(calculator, aggregate), nil
+}
+
+func NewSoftReset(cfg *config.Config) *rating.SoftReset {
+	return rating.NewSoftReset(cfg.Season.ResetFactor)
+}
+
+func NewMatchmaking(cfg *config.Config) *rating.Matchmaking {
+	return rating.NewMatchmaking(&cfg.Matchmaking)
+}
@@ -2,19 +2,60 @@ package config
 
 import (
 	"errors"
+	"time"
 
 	"github.com/spf13/viper"
 	"go.uber.org/fx"
 
+	"github.com/me0den/example-service/domain/rating"
+	"github.com/me0den/example-service/x/postgres"
 	"github.com/me0den/example-service/x/redis"
+	"github.com/me0den/example-service/x/tracing"
+)
+
+// Storage drivers of the ratings.
+const (
+	StorageDriverRedis    = "redis"
+	StorageDriverPostgres = "postgres"
+	StorageDriverMemory   = "memory"
 )
 
 // Config is a group of options for the service.
 type Config struct {
 	HTTPServer struct {
-		Addr string `mapstructure:"addr"`
+		Addr         string        `mapstructure:"addr"`
+		ReadTimeout  time.Duration `mapstructure:"read_timeout"`
+		WriteTimeout time.Duration `mapstructure:"write_timeout"`
+		IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+		// DrainDelay is the time the server keeps serving once not ready on stop, so the load balancers stop
+		// routing to it before it closes its listener.
+		DrainDelay time.Duration `mapstructure:"drain_delay"`
+		// ShutdownTimeout bounds the draining of the in-flight requests on stop.
+		ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+		// AdminToken is the bearer token of the admin routes, they are rejected when it is empty.
+		AdminToken string `mapstructure:"admin_token"`
 	} `mapstructure:"http_server"`
-	Redis redis.Config `mapstructure:"redis"`
+	Log struct {
+		// Level is debug, info, warn or error, Format is json or text.
+		Level  string `mapstructure:"level"`
+		Format string `mapstructure:"format"`
+	} `mapstructure:"log"`
+	Storage struct {
+		// Driver is the storage of the ratings, StorageDriverRedis, StorageDriverPostgres or StorageDriverMemory.
+		Driver string `mapstructure:"driver"`
+		// Cache keeps the elos of the current season in Redis in front of Postgres.
+		Cache struct {
+			Enabled bool          `mapstructure:"enabled"`
+			TTL     time.Duration `mapstructure:"ttl"`
+		} `mapstructure:"cache"`
+	} `mapstructure:"storage"`
+	Redis       redis.Config             `mapstructure:"redis"`
+	Postgres    postgres.Config          `mapstructure:"postgres"`
+	Tracing     tracing.Config           `mapstructure:"tracing"`
+	Rating      rating.Config            `mapstructure:"rating"`
+	Season      rating.SeasonConfig      `mapstructure:"season"`
+	Decay       rating.DecayConfig       `mapstructure:"decay"`
+	Matchmaking rating.MatchmakingConfig `mapstructure:"matchmaking"`
 }
 
 // Load loads Config from Viper and returns them.
@@ -27,6 +68,23 @@ func Load(v *viper.Viper) (*Config, error) {
 	return cfg, nil
 }
 
+// UsesPostgres reports whether the ratings are stored in Postgres.
+func (c *Config) UsesPostgres() bool {
+	return c.Storage.Driver == StorageDriverPostgres
+}
+
+// UsesRedis reports whether the ratings are stored or cached in Redis, Redis is the default storage.
+func (c *Config) UsesRedis() bool {
+	switch c.Storage.Driver {
+	case "", StorageDriverRedis:
+		return true
+	case StorageDriverPostgres:
+		return c.Storage.Cache.Enabled
+	default:
+		return false
+	}
+}
+
 // FXModule represents a FX module for config.
 var FXModule = fx.Options(
 	fx.Provide(
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/fx"
+
+	"github.com/me0den/example-service/infra/config"
+	"github.com/me0den/example-service/infra/health"
+	mPostgres "github.com/me0den/example-service/x/postgres"
+)
+
+var PostgresFXModule = fx.Provide(
+	NewPostgres,
+)
+
+// NewPostgres connects to Postgres when the ratings are stored in it and returns nil otherwise,
+// the connected pool is checked by the readiness probe.
+func NewPostgres(
+	lc fx.Lifecycle,
+	cfg *config.Config,
+	logger *slog.Logger,
+	registry *health.Registry,
+) (*pgxpool.Pool, error) {
+	if !cfg.UsesPostgres() {
+		return nil, nil
+	}
+
+	pool, err := mPostgres.New(&cfg.Postgres, logger)
+	if err != nil {
+		return nil, fmt.Errorf("error when init postgres pool: %v", err)
+	}
+
+	registry.Register("postgres", pool.Ping)
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			pool.Close()
+			return nil
+		},
+	})
+
+	return pool, nil
+}
@@ -0,0 +1,120 @@
+package health
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+// Statuses of a Report and of its components.
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// DefaultCheckTimeout bounds every check of a readiness probe.
+const DefaultCheckTimeout = 2 * time.Second
+
+// FXModule represents a FX module for the health checks.
+var FXModule = fx.Provide(
+	NewRegistry,
+)
+
+// Check reports whether a component of the service works, a non-nil error marks it down.
+type Check func(ctx context.Context) error
+
+// ComponentStatus is the status of a registered component.
+type ComponentStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the status of the service and of its components, the service is down when a component is
+// or when it is shutting down.
+type Report struct {
+	Status       string                      `json:"status"`
+	ShuttingDown bool                        `json:"shuttingDown,omitempty"`
+	Components   map[string]*ComponentStatus `json:"components,omitempty"`
+}
+
+// Registry holds the checks registered by the backends of the service, e.g. Redis or Postgres.
+type Registry struct {
+	mu           sync.RWMutex
+	checks       map[string]Check
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+// NewRegistry creates and returns a new instance of Registry without checks.
+func NewRegistry() *Registry {
+	return &Registry{
+		checks:  map[string]Check{},
+		timeout: DefaultCheckTimeout,
+	}
+}
+
+// Register registers check as the check of the component name, it replaces a check registered under the same name.
+func (r *Registry) Register(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks[name] = check
+}
+
+// SetShuttingDown marks the service as shutting down, so it is no longer ready while it drains its requests.
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// Check runs every registered check concurrently and returns the report of the components.
+func (r *Registry) Check(ctx context.Context) *Report {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.checks))
+	for name := range r.checks {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	checks := make([]Check, 0, len(names))
+	for _, name := range names {
+		checks = append(checks, r.checks[name])
+	}
+	r.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	statuses := make([]*ComponentStatus, len(checks))
+	var wg sync.WaitGroup
+	for idx, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[idx] = &ComponentStatus{Status: StatusUp}
+			if err := check(ctx); err != nil {
+				statuses[idx] = &ComponentStatus{Status: StatusDown, Error: err.Error()}
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{
+		Status:       StatusUp,
+		ShuttingDown: r.shuttingDown.Load(),
+		Components:   make(map[string]*ComponentStatus, len(names)),
+	}
+	if report.ShuttingDown {
+		report.Status = StatusDown
+	}
+	for idx, name := range names {
+		report.Components[name] = statuses[idx]
+		if statuses[idx].Status == StatusDown {
+			report.Status = StatusDown
+		}
+	}
+
+	return report
+}
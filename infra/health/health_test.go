@@ -0,0 +1,46 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Check(t *testing.T) {
+	ctx := context.Background()
+	r := NewRegistry()
+	assert.Equal(t, &Report{Status: StatusUp, Components: map[string]*ComponentStatus{}}, r.Check(ctx))
+
+	r.Register("redis", func(context.Context) error { return nil })
+	r.Register("postgres", func(context.Context) error { return errors.New("connection refused") })
+	assert.Equal(t, &Report{
+		Status: StatusDown,
+		Components: map[string]*ComponentStatus{
+			"redis":    {Status: StatusUp},
+			"postgres": {Status: StatusDown, Error: "connection refused"},
+		},
+	}, r.Check(ctx))
+
+	// The service is no longer ready once it is shutting down, even when its components are.
+	r.Register("postgres", func(context.Context) error { return nil })
+	r.SetShuttingDown()
+	report := r.Check(ctx)
+	assert.Equal(t, StatusDown, report.Status)
+	assert.True(t, report.ShuttingDown)
+	assert.Equal(t, StatusUp, report.Components["postgres"].Status)
+}
+
+func TestRegistry_Check_Timeout(t *testing.T) {
+	r := NewRegistry()
+	r.timeout = 0
+	r.Register("redis", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := r.Check(context.Background())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Components["redis"].Error)
+}
@@ -0,0 +1,108 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+
+	"github.com/me0den/example-service/infra/config"
+)
+
+// Formats of the log lines.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// requestIDKey is the log attribute of the request id.
+const requestIDKey = "request_id"
+
+// FXModule represents a FX module for the logger.
+var FXModule = fx.Provide(
+	NewLogger,
+)
+
+// FXEventLogger logs the events of the application lifecycle with the logger, at debug level unless they failed.
+var FXEventLogger = fx.WithLogger(func(logger *slog.Logger) fxevent.Logger {
+	eventLogger := &fxevent.SlogLogger{Logger: logger}
+	eventLogger.UseLogLevel(slog.LevelDebug)
+
+	return eventLogger
+})
+
+// NewLogger creates the logger of the configured level and format writing to stderr and sets it as the default
+// logger, so the package-level slog functions and the standard log package write through it as well.
+func NewLogger(cfg *config.Config) (*slog.Logger, error) {
+	logger, err := New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.SetDefault(logger)
+	return logger, nil
+}
+
+// New creates a logger of level and format writing to w, the empty level is info and the empty format is
+// FormatJSON. The request id of the context of a record is added to it.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if level != "" {
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+
+	return slog.New(&contextHandler{Handler: handler}), nil
+}
+
+type requestIDContextKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request id, which is added to every record logged
+// with the returned context.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request id carried by ctx or the empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// contextHandler adds the request id of the context of a record to it.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String(requestIDKey, requestID))
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
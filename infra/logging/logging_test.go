@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "warn", "")
+	require.NoError(t, err)
+
+	ctx := ContextWithRequestID(context.Background(), "req_1")
+	logger.InfoContext(ctx, "filtered")
+	logger.With("component", "worker").WarnContext(ctx, "decay failed", "user_id", "user_1")
+	logger.Warn("no request")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	// The request id of the context is added to the lines logged with it only.
+	line := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+	assert.Equal(t, "decay failed", line["msg"])
+	assert.Equal(t, "req_1", line["request_id"])
+	assert.Equal(t, "worker", line["component"])
+	assert.Equal(t, "user_1", line["user_id"])
+	assert.NotContains(t, lines[1], "request_id")
+}
+
+func TestNew_Invalid(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "verbose", FormatJSON)
+	assert.Error(t, err)
+
+	_, err = New(&bytes.Buffer{}, "info", "xml")
+	assert.Error(t, err)
+
+	logger, err := New(&bytes.Buffer{}, "DEBUG", FormatText)
+	require.NoError(t, err)
+	assert.True(t, logger.Enabled(context.Background(), slog.LevelDebug))
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unmatchedRoute is the route label of the requests matching no route.
+const unmatchedRoute = "unmatched"
+
+// HTTPMetrics observes the requests of the http server.
+type HTTPMetrics struct {
+	duration *prometheus.HistogramVec
+}
+
+// NewHTTPMetrics creates and returns new instance of HTTPMetrics registered in reg.
+func NewHTTPMetrics(reg *prometheus.Registry) (*HTTPMetrics, error) {
+	m := &HTTPMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Latency of the HTTP requests by route and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+	}
+	if err := reg.Register(m.duration); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Middleware returns the echo middleware observing every request by its route rather than its path,
+// so the path params do not multiply the series.
+func (m *HTTPMetrics) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			route := c.Path()
+			if route == "" {
+				route = unmatchedRoute
+			}
+
+			// The error is written after the middleware, so its status is the one the client gets.
+			status := c.Response().Status
+			if err != nil {
+				status = http.StatusInternalServerError
+				var httpErr *echo.HTTPError
+				if errors.As(err, &httpErr) {
+					status = httpErr.Code
+				}
+			}
+
+			m.duration.WithLabelValues(c.Request().Method, route, strconv.Itoa(status)).
+				Observe(time.Since(start).Seconds())
+
+			return err
+		}
+	}
+}
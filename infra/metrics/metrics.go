@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.uber.org/fx"
+)
+
+// FXModule represents a FX module for the Prometheus metrics.
+var FXModule = fx.Provide(
+	NewRegistry,
+	NewHTTPMetrics,
+	NewRatingMetrics,
+)
+
+// NewRegistry creates and returns the registry of the metrics served on /metrics with the Go runtime and
+// process metrics registered.
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return reg
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+func TestHTTPMetrics_Middleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewHTTPMetrics(reg)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(m.Middleware())
+	e.GET("/v1/users/:user_id/elo", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	e.GET("/v1/leaderboard", func(c echo.Context) error { return errors.New("redis is down") })
+	for _, target := range []string{"/v1/users/user_1/elo", "/v1/users/user_2/elo", "/v1/leaderboard", "/unknown"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	// The requests are counted by route rather than by path.
+	assert.Equal(t, map[string]uint64{
+		"GET /v1/leaderboard 500":        1,
+		"GET /v1/users/:user_id/elo 200": 2,
+		"GET unmatched 404":              1,
+	}, sampleCounts(t, m.duration))
+}
+
+func TestRatingMetrics_ObserveBattle(t *testing.T) {
+	m, err := NewRatingMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	m.ObserveBattle(&entity.Battle{
+		Placements: []int{1, 2},
+		Rewards:    []*entity.Reward{{OldElo: 1000, NewElo: 1016}, {OldElo: 1000, NewElo: 984}},
+	})
+	m.ObserveBattle(&entity.Battle{
+		Placements: []int{1, 1, 3},
+		Rewards:    []*entity.Reward{{OldElo: 1000, NewElo: 1000}},
+	})
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.rewards))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.battles.WithLabelValues(OutcomeWin)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.battles.WithLabelValues(OutcomeDraw)))
+	assert.Equal(t, map[string]uint64{"": 3}, sampleCounts(t, m.eloDelta))
+
+	// A nil RatingMetrics records nothing.
+	var nilMetrics *RatingMetrics
+	assert.NotPanics(t, func() { nilMetrics.ObserveBattle(&entity.Battle{}) })
+}
+
+// sampleCounts returns the sample count of every histogram of collector by its label values joined by spaces.
+func sampleCounts(t *testing.T, collector prometheus.Collector) map[string]uint64 {
+	ch := make(chan prometheus.Metric, 16)
+	collector.Collect(ch)
+	close(ch)
+
+	counts := map[string]uint64{}
+	for metric := range ch {
+		pb := &dto.Metric{}
+		require.NoError(t, metric.Write(pb))
+		var values []string
+		for _, label := range pb.GetLabel() {
+			values = append(values, label.GetValue())
+		}
+		counts[strings.Join(values, " ")] = pb.GetHistogram().GetSampleCount()
+	}
+
+	return counts
+}
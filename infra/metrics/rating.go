@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"slices"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/me0den/example-service/domain/entity"
+)
+
+// Outcomes of a rewarded battle.
+const (
+	OutcomeWin  = "win"
+	OutcomeDraw = "draw"
+)
+
+// RatingMetrics counts the rewarded battles and observes the rating changes of their users.
+//
+// A nil RatingMetrics records nothing.
+type RatingMetrics struct {
+	rewards  prometheus.Counter
+	battles  *prometheus.CounterVec
+	eloDelta prometheus.Histogram
+}
+
+// NewRatingMetrics creates and returns new instance of RatingMetrics registered in reg.
+func NewRatingMetrics(reg *prometheus.Registry) (*RatingMetrics, error) {
+	m := &RatingMetrics{
+		rewards: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "rating_rewards_created_total",
+			Help: "Rewards created for the users of the rewarded battles.",
+		}),
+		battles: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rating_battles_rewarded_total",
+			Help: "Rewarded battles by outcome, win when a single team is placed first and draw otherwise.",
+		}, []string{"outcome"}),
+		eloDelta: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "rating_elo_delta",
+			Help:    "Elo change of the rewarded users.",
+			Buckets: []float64{-64, -32, -16, -8, -4, -2, 0, 2, 4, 8, 16, 32, 64},
+		}),
+	}
+
+	for _, collector := range []prometheus.Collector{m.rewards, m.battles, m.eloDelta} {
+		if err := reg.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// ObserveBattle records a newly rewarded battle.
+func (m *RatingMetrics) ObserveBattle(battle *entity.Battle) {
+	if m == nil {
+		return
+	}
+
+	m.battles.WithLabelValues(battleOutcome(battle.Placements)).Inc()
+	for _, reward := range battle.Rewards {
+		m.rewards.Inc()
+		m.eloDelta.Observe(float64(reward.NewElo - reward.OldElo))
+	}
+}
+
+// battleOutcome returns OutcomeWin when a single team has the best placement and OutcomeDraw otherwise.
+func battleOutcome(placements []int) string {
+	if len(placements) == 0 {
+		return OutcomeDraw
+	}
+
+	first := slices.Min(placements)
+	firsts := 0
+	for _, placement := range placements {
+		if placement == first {
+			firsts++
+		}
+	}
+
+	if firsts > 1 {
+		return OutcomeDraw
+	}
+
+	return OutcomeWin
+}
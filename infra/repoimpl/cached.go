@@ -0,0 +1,288 @@
+package repoimpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/repo"
+)
+
+const (
+	cacheKeyPrefix = "cache"
+	// DefaultCacheTTL bounds the staleness of an entry read concurrently with its invalidation.
+	DefaultCacheTTL = 5 * time.Minute
+)
+
+// CachedRepo is a repo.RatingRepo keeping the current season and the elos of its users in Redis in front of
+// another repo.RatingRepo, the cached entries are invalidated by the writes through CachedRepo.
+//
+// The cache is best effort: Redis errors fall back to the underlying repo.
+type CachedRepo struct {
+	repo.RatingRepo
+	client  redis.UniversalClient
+	ttl     time.Duration
+	hashTag string
+}
+
+// NewCachedRepo creates and returns a new instance of CachedRepo, a non-positive ttl falls back to DefaultCacheTTL.
+// The cached keys are put under hashTag like the keys of RedisRepo.
+func NewCachedRepo(ratingRepo repo.RatingRepo, client redis.UniversalClient, ttl time.Duration, hashTag string) *CachedRepo {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	return &CachedRepo{
+		RatingRepo: ratingRepo,
+		client:     client,
+		ttl:        ttl,
+		hashTag:    hashTag,
+	}
+}
+
+func (r *CachedRepo) GetUserElo(ctx context.Context, userID string) (*entity.UserElo, error) {
+	userElos, err := r.ListUserElos(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+
+	return userElos[0], nil
+}
+
+func (r *CachedRepo) ListUserElos(ctx context.Context, userIDs []string) ([]*entity.UserElo, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	season, err := r.GetCurrentSeason(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		keys = append(keys, r.key(cachedUserEloKey(season.ID, userID)))
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return r.RatingRepo.ListUserElos(ctx, userIDs)
+	}
+
+	userElos := make([]*entity.UserElo, len(userIDs))
+	var missingIDs []string
+	for idx, value := range values {
+		data, ok := value.(string)
+		if ok {
+			userElo := &entity.UserElo{}
+			if json.Unmarshal([]byte(data), userElo) == nil {
+				userElos[idx] = userElo
+				continue
+			}
+		}
+
+		missingIDs = append(missingIDs, userIDs[idx])
+	}
+
+	if len(missingIDs) == 0 {
+		return userElos, nil
+	}
+
+	missing, err := r.RatingRepo.ListUserElos(ctx, missingIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := r.client.Pipeline()
+	missingIdx := 0
+	for idx := range userElos {
+		if userElos[idx] != nil {
+			continue
+		}
+
+		userElos[idx] = missing[missingIdx]
+		missingIdx++
+		if eloData, err := json.Marshal(userElos[idx]); err == nil {
+			pipe.Set(ctx, keys[idx], eloData, r.ttl)
+		}
+	}
+	_, _ = pipe.Exec(ctx)
+
+	return userElos, nil
+}
+
+func (r *CachedRepo) BatchUpdateElo(ctx context.Context, elos []*entity.UserElo) error {
+	season, err := r.GetCurrentSeason(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := r.RatingRepo.BatchUpdateElo(ctx, elos); err != nil {
+		return err
+	}
+
+	userIDs := make([]string, 0, len(elos))
+	for _, elo := range elos {
+		userIDs = append(userIDs, elo.UserID)
+	}
+
+	r.invalidateUserElos(ctx, season.ID, userIDs)
+	return nil
+}
+
+func (r *CachedRepo) ApplyBattle(
+	ctx context.Context,
+	battleID string,
+	userIDs []string,
+	fn repo.BattleFunc,
+) (*entity.Battle, error) {
+	battle, err := r.RatingRepo.ApplyBattle(ctx, battleID, userIDs, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidateBattle(ctx, battle)
+	return battle, nil
+}
+
+func (r *CachedRepo) VoidBattle(ctx context.Context, battleID string, voidedAt int64) (*entity.Battle, error) {
+	battle, err := r.RatingRepo.VoidBattle(ctx, battleID, voidedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidateBattle(ctx, battle)
+	return battle, nil
+}
+
+func (r *CachedRepo) ApplyDecay(
+	ctx context.Context,
+	userID string,
+	before, period, decayedAt int64,
+	fn repo.DecayFunc,
+) (*entity.RatingHistory, error) {
+	entry, err := r.RatingRepo.ApplyDecay(ctx, userID, before, period, decayedAt, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry != nil {
+		r.invalidateUserElos(ctx, entry.Season, []string{userID})
+	}
+
+	return entry, nil
+}
+
+func (r *CachedRepo) GetCurrentSeason(ctx context.Context) (*entity.Season, error) {
+	seasonID, err := r.client.Get(ctx, r.key(cachedSeasonKey())).Result()
+	if err == nil {
+		return &entity.Season{ID: seasonID}, nil
+	}
+
+	season, err := r.RatingRepo.GetCurrentSeason(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = r.client.Set(ctx, r.key(cachedSeasonKey()), season.ID, r.ttl).Err()
+	return season, nil
+}
+
+func (r *CachedRepo) RolloverSeason(
+	ctx context.Context,
+	seasonID string,
+	endedAt int64,
+	fn repo.SeasonResetFunc,
+) (*entity.Season, error) {
+	endedSeason, err := r.RatingRepo.RolloverSeason(ctx, seasonID, endedAt, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	// The elos of the ended season are no longer read once the current season is read again.
+	_ = r.client.Del(ctx, r.key(cachedSeasonKey())).Err()
+	return endedSeason, nil
+}
+
+func (r *CachedRepo) SwapReplay(ctx context.Context, namespace, season, lastLogID string) error {
+	if err := r.RatingRepo.SwapReplay(ctx, namespace, season, lastLogID); err != nil {
+		return err
+	}
+
+	// Every elo of the season is replaced, so every cached one is dropped.
+	pattern := escapeKeyPattern(r.key(cachedUserEloKey(season, ""))) + "*"
+	if cluster, ok := r.client.(*redis.ClusterClient); ok {
+		_ = cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return unlinkKeys(ctx, client, pattern)
+		})
+		return nil
+	}
+
+	_ = unlinkKeys(ctx, r.client, pattern)
+	return nil
+}
+
+// invalidateBattle drops the cached elos of the users rewarded by battle in its season.
+func (r *CachedRepo) invalidateBattle(ctx context.Context, battle *entity.Battle) {
+	userIDs := make([]string, 0, len(battle.Rewards))
+	for _, reward := range battle.Rewards {
+		userIDs = append(userIDs, reward.UserID)
+	}
+
+	r.invalidateUserElos(ctx, battle.Season, userIDs)
+}
+
+// invalidateUserElos drops the cached elos of userIDs in a season.
+func (r *CachedRepo) invalidateUserElos(ctx context.Context, season string, userIDs []string) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		keys = append(keys, r.key(cachedUserEloKey(season, userID)))
+	}
+
+	// An entry failing to be dropped stays stale until it expires.
+	_ = r.client.Del(ctx, keys...).Err()
+}
+
+// key returns key under the hash tag of the repo.
+func (r *CachedRepo) key(key string) string {
+	return hashTagKey(r.hashTag, key)
+}
+
+// unlinkKeys unlinks the keys of the node of client matching pattern.
+func unlinkKeys(ctx context.Context, client redis.Cmdable, pattern string) error {
+	iter := client.Scan(ctx, 0, pattern, 1000).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+
+	if err := iter.Err(); err != nil || len(keys) == 0 {
+		return err
+	}
+
+	return client.Unlink(ctx, keys...).Err()
+}
+
+// cachedSeasonKey returns the key of the cached current season.
+func cachedSeasonKey() string {
+	return fmt.Sprintf("%s:%s", cacheKeyPrefix, currentSeasonKey)
+}
+
+// cachedUserEloKey returns the key of the cached elo of a user in a season.
+func cachedUserEloKey(season, userID string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", cacheKeyPrefix, userEloKeyPrefix, season, userID)
+}
+
+// escapeKeyPattern escapes the glob characters of key, so it matches itself in a SCAN pattern.
+func escapeKeyPattern(key string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`).Replace(key)
+}
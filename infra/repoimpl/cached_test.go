@@ -0,0 +1,92 @@
+package repoimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me0den/example-service/app/api/v1/v1impl/mock"
+	"github.com/me0den/example-service/domain/entity"
+)
+
+func newTestCachedRepo(t *testing.T) (*CachedRepo, *mock.RatingRepo, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	mockRepo := mock.NewRatingRepo(t)
+
+	return NewCachedRepo(mockRepo, client, 0, ""), mockRepo, server
+}
+
+func TestCachedRepo_ListUserElos(t *testing.T) {
+	ctx := context.Background()
+	r, mockRepo, _ := newTestCachedRepo(t)
+	user1 := &entity.UserElo{UserID: "user_1", Elo: 1200, Deviation: 80, Volatility: 0.05, GamesPlayed: 20}
+	user2 := entity.NewUserDefaultElo("user_2")
+
+	mockRepo.On("GetCurrentSeason", tmock.Anything).Return(&entity.Season{ID: "s1"}, nil).Once()
+	mockRepo.On("ListUserElos", tmock.Anything, []string{"user_1"}).Return([]*entity.UserElo{user1}, nil).Once()
+	userElos, err := r.ListUserElos(ctx, []string{"user_1"})
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.UserElo{user1}, userElos)
+
+	// The cached elo and season are not read again, only the missing elo is.
+	mockRepo.On("ListUserElos", tmock.Anything, []string{"user_2"}).Return([]*entity.UserElo{user2}, nil).Once()
+	userElos, err = r.ListUserElos(ctx, []string{"user_2", "user_1"})
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.UserElo{user2, user1}, userElos)
+
+	userElo, err := r.GetUserElo(ctx, "user_2")
+	require.NoError(t, err)
+	assert.Equal(t, user2, userElo)
+}
+
+func TestCachedRepo_Invalidation(t *testing.T) {
+	ctx := context.Background()
+	r, mockRepo, server := newTestCachedRepo(t)
+	battle := &entity.Battle{
+		ID:      "battle_1",
+		Season:  "s1",
+		Rewards: []*entity.Reward{{UserID: "user_1", OldElo: 1000, NewElo: 1016}},
+	}
+
+	require.NoError(t, server.Set(cachedSeasonKey(), "s1"))
+	require.NoError(t, server.Set(cachedUserEloKey("s1", "user_1"), `{"userID":"user_1","elo":1000}`))
+	require.NoError(t, server.Set(cachedUserEloKey("s1", "user_2"), `{"userID":"user_2","elo":1000}`))
+
+	mockRepo.On("ApplyBattle", tmock.Anything, "battle_1", []string{"user_1"}, tmock.Anything).Return(battle, nil).Once()
+	_, err := r.ApplyBattle(ctx, "battle_1", []string{"user_1"}, addEloFunc("battle_1", 16))
+	require.NoError(t, err)
+	assert.False(t, server.Exists(cachedUserEloKey("s1", "user_1")))
+	assert.True(t, server.Exists(cachedUserEloKey("s1", "user_2")))
+
+	mockRepo.On("SwapReplay", tmock.Anything, "ns", "s1", "42").Return(nil).Once()
+	require.NoError(t, r.SwapReplay(ctx, "ns", "s1", "42"))
+	assert.False(t, server.Exists(cachedUserEloKey("s1", "user_2")))
+	assert.True(t, server.Exists(cachedSeasonKey()))
+
+	mockRepo.On("RolloverSeason", tmock.Anything, "s2", int64(100), tmock.Anything).
+		Return(&entity.Season{ID: "s1", EndedAt: 100}, nil).Once()
+	_, err = r.RolloverSeason(ctx, "s2", 100, func(userElo *entity.UserElo) *entity.UserElo { return userElo })
+	require.NoError(t, err)
+	assert.False(t, server.Exists(cachedSeasonKey()))
+}
+
+func TestCachedRepo_RedisDown(t *testing.T) {
+	ctx := context.Background()
+	r, mockRepo, server := newTestCachedRepo(t)
+	server.Close()
+
+	// The elos are read from the underlying repo when Redis fails.
+	mockRepo.On("GetCurrentSeason", tmock.Anything).Return(&entity.Season{ID: "s1"}, nil).Once()
+	mockRepo.On("ListUserElos", tmock.Anything, []string{"user_1"}).
+		Return([]*entity.UserElo{entity.NewUserDefaultElo("user_1")}, nil).Once()
+	userElos, err := r.ListUserElos(ctx, []string{"user_1"})
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.UserElo{entity.NewUserDefaultElo("user_1")}, userElos)
+}
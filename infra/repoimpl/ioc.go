@@ -1,9 +1,74 @@
 package repoimpl
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
+
+	"github.com/me0den/example-service/domain/repo"
+	"github.com/me0den/example-service/infra/config"
 )
 
 var FXModule = fx.Provide(
-	NewRedisDBRepo,
+	NewRatingRepo,
 )
+
+// NewRatingRepo creates the repo.RatingRepo of the configured storage driver, the storage is initialized first.
+// The Postgres storage is migrated before and is cached in Redis when enabled. Every call to the repo is traced
+// when the tracing is enabled.
+func NewRatingRepo(
+	cfg *config.Config,
+	client redis.UniversalClient,
+	pool *pgxpool.Pool,
+	tp trace.TracerProvider,
+) (repo.RatingRepo, error) {
+	ratingRepo, err := newStorageRepo(cfg, client, pool)
+	if err != nil || !cfg.Tracing.Enabled {
+		return ratingRepo, err
+	}
+
+	return NewTracedRepo(ratingRepo, tp), nil
+}
+
+// newStorageRepo creates the repo.RatingRepo of the configured storage driver.
+func newStorageRepo(
+	cfg *config.Config,
+	client redis.UniversalClient,
+	pool *pgxpool.Pool,
+) (repo.RatingRepo, error) {
+	switch cfg.Storage.Driver {
+	case "", config.StorageDriverRedis:
+		redisRepo := NewRedisRepo(client, cfg)
+		if err := redisRepo.Init(context.Background()); err != nil {
+			return nil, fmt.Errorf("error when init redis repo: %v", err)
+		}
+
+		return redisRepo, nil
+	case config.StorageDriverPostgres:
+		ctx := context.Background()
+		if cfg.Postgres.Migrate {
+			if err := Migrate(ctx, pool); err != nil {
+				return nil, fmt.Errorf("error when migrate postgres: %v", err)
+			}
+		}
+
+		postgresRepo := NewPostgresRepo(pool, cfg)
+		if err := postgresRepo.Init(ctx); err != nil {
+			return nil, fmt.Errorf("error when init postgres repo: %v", err)
+		}
+
+		if !cfg.Storage.Cache.Enabled {
+			return postgresRepo, nil
+		}
+
+		return NewCachedRepo(postgresRepo, client, cfg.Storage.Cache.TTL, redisHashTag(cfg)), nil
+	case config.StorageDriverMemory:
+		return NewMemoryRepo(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
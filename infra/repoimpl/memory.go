@@ -0,0 +1,617 @@
+package repoimpl
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/repo"
+	"github.com/me0den/example-service/infra/config"
+)
+
+// MemoryRepo implements repo.RatingRepo in memory for local development and tests, the data is lost on exit.
+//
+// Every method holds a single lock, so the multi-row updates are atomic. Processed battles are kept for good.
+type MemoryRepo struct {
+	mu sync.Mutex
+	// season is the current season, seasons holds the end time of the ended seasons.
+	season  string
+	seasons map[string]int64
+	// elos and inactivity are indexed by season then user.
+	elos       map[string]map[string]*entity.UserElo
+	inactivity map[string]map[string]int64
+	history    map[string][]*entity.RatingHistory
+	// battles holds the processed battles encoded, so callers never share them with the repo.
+	battles   map[string][]byte
+	battleLog []*entity.BattleLog
+	// replays holds the replayed elos indexed by namespace then season.
+	replays map[string]map[string]map[string]*entity.UserElo
+	queue   map[string]int64
+}
+
+// NewMemoryRepo creates and returns a new instance of MemoryRepo starting in the configured season.
+func NewMemoryRepo(
+	cfg *config.Config,
+) *MemoryRepo {
+	return &MemoryRepo{
+		season:     cfg.Season.ID,
+		seasons:    make(map[string]int64),
+		elos:       make(map[string]map[string]*entity.UserElo),
+		inactivity: make(map[string]map[string]int64),
+		history:    make(map[string][]*entity.RatingHistory),
+		battles:    make(map[string][]byte),
+		replays:    make(map[string]map[string]map[string]*entity.UserElo),
+		queue:      make(map[string]int64),
+	}
+}
+
+func (r *MemoryRepo) GetUserElo(_ context.Context, userID string) (*entity.UserElo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.userElos(r.season, []string{userID})[0], nil
+}
+
+func (r *MemoryRepo) ListUserElos(_ context.Context, userIDs []string) ([]*entity.UserElo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	return r.userElos(r.season, userIDs), nil
+}
+
+func (r *MemoryRepo) BatchUpdateElo(_ context.Context, elos []*entity.UserElo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.setUserElos(r.season, elos)
+	return nil
+}
+
+func (r *MemoryRepo) GetBattle(_ context.Context, battleID string) (*entity.Battle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.getBattle(battleID)
+}
+
+func (r *MemoryRepo) ApplyBattle(
+	_ context.Context,
+	battleID string,
+	userIDs []string,
+	fn repo.BattleFunc,
+) (*entity.Battle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.battles[battleID]; ok {
+		return r.getBattle(battleID)
+	}
+
+	newBattle, newUserElos, err := fn(r.userElos(r.season, userIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	newBattle.Season = r.season
+	if err := r.setBattle(newBattle); err != nil {
+		return nil, err
+	}
+
+	r.setUserElos(r.season, newUserElos)
+	history := newBattle.History()
+	for _, entry := range history {
+		r.seasonInactivity(r.season)[entry.UserID] = entry.CreatedAt
+	}
+
+	r.addRatingHistory(history)
+	r.addBattleLog(newBattle.Log())
+	return newBattle, nil
+}
+
+func (r *MemoryRepo) VoidBattle(_ context.Context, battleID string, voidedAt int64) (*entity.Battle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	storedBattle, err := r.getBattle(battleID)
+	if err != nil {
+		return nil, err
+	}
+	if storedBattle.IsVoided() {
+		return nil, repo.ErrVoided
+	}
+
+	// A battle recorded before seasons were stored on it belongs to the current season.
+	if storedBattle.Season == "" {
+		storedBattle.Season = r.season
+	}
+	if storedBattle.Season != r.season {
+		return nil, repo.ErrSeasonEnded
+	}
+
+	userIDs := make([]string, 0, len(storedBattle.Rewards))
+	for _, reward := range storedBattle.Rewards {
+		userIDs = append(userIDs, reward.UserID)
+	}
+
+	newUserElos := storedBattle.Void(r.userElos(storedBattle.Season, userIDs), voidedAt)
+	if err := r.setBattle(storedBattle); err != nil {
+		return nil, err
+	}
+
+	r.setUserElos(storedBattle.Season, newUserElos)
+	r.addRatingHistory(storedBattle.ReversalHistory())
+	r.addBattleLog(storedBattle.VoidLog())
+	return storedBattle, nil
+}
+
+func (r *MemoryRepo) ListBattleLog(_ context.Context, after string, limit int64) ([]*entity.BattleLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	// Entry ids are their 1-based position in the log.
+	var start int
+	if after != "" {
+		afterID, err := strconv.Atoi(after)
+		if err != nil {
+			return nil, fmt.Errorf("invalid battle log id %q: %w", after, err)
+		}
+
+		start = min(max(afterID, 0), len(r.battleLog))
+	}
+
+	end := min(start+int(limit), len(r.battleLog))
+	entries := make([]*entity.BattleLog, 0, end-start)
+	for _, entry := range r.battleLog[start:end] {
+		clone := *entry
+		entries = append(entries, &clone)
+	}
+
+	return entries, nil
+}
+
+func (r *MemoryRepo) StoreReplay(_ context.Context, namespace, season string, elos []*entity.UserElo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.replays[namespace] == nil {
+		r.replays[namespace] = make(map[string]map[string]*entity.UserElo)
+	}
+
+	replayed := make(map[string]*entity.UserElo, len(elos))
+	for _, elo := range elos {
+		replayed[elo.UserID] = elo.Clone()
+	}
+
+	r.replays[namespace][season] = replayed
+	return nil
+}
+
+func (r *MemoryRepo) SwapReplay(_ context.Context, namespace, season, lastLogID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.season != season {
+		return repo.ErrConflict
+	}
+
+	// Battles accepted or voided after the replay would be lost by the swap.
+	if len(r.battleLog) > 0 && r.battleLog[len(r.battleLog)-1].ID != lastLogID {
+		return repo.ErrConflict
+	}
+
+	replayed, ok := r.replays[namespace][season]
+	if !ok {
+		return repo.ErrNotFound
+	}
+
+	r.elos[season] = replayed
+	delete(r.replays[namespace], season)
+	return nil
+}
+
+func (r *MemoryRepo) JoinQueue(_ context.Context, userID string, joinedAt, since int64) (*entity.QueuedUser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dropQueuedBefore(since)
+	if _, ok := r.queue[userID]; !ok {
+		r.queue[userID] = joinedAt
+	}
+
+	return &entity.QueuedUser{
+		UserID:   userID,
+		Elo:      r.userElos(r.season, []string{userID})[0].Elo,
+		JoinedAt: r.queue[userID],
+	}, nil
+}
+
+func (r *MemoryRepo) LeaveQueue(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.queue[userID]; !ok {
+		return repo.ErrNotFound
+	}
+
+	delete(r.queue, userID)
+	return nil
+}
+
+func (r *MemoryRepo) GetQueuedUser(_ context.Context, userID string, since int64) (*entity.QueuedUser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	joinedAt, ok := r.queue[userID]
+	if !ok || joinedAt < since {
+		return nil, repo.ErrNotFound
+	}
+
+	return &entity.QueuedUser{
+		UserID:   userID,
+		Elo:      r.userElos(r.season, []string{userID})[0].Elo,
+		JoinedAt: joinedAt,
+	}, nil
+}
+
+func (r *MemoryRepo) ListQueuedUsers(_ context.Context, since int64, minElo, maxElo int) ([]*entity.QueuedUser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dropQueuedBefore(since)
+	var queued []*entity.QueuedUser
+	for userID, joinedAt := range r.queue {
+		elo := r.userElos(r.season, []string{userID})[0].Elo
+		if elo < minElo || elo > maxElo {
+			continue
+		}
+
+		queued = append(queued, &entity.QueuedUser{
+			UserID:   userID,
+			Elo:      elo,
+			JoinedAt: joinedAt,
+		})
+	}
+
+	slices.SortFunc(queued, func(a, b *entity.QueuedUser) int {
+		return cmp.Or(cmp.Compare(a.JoinedAt, b.JoinedAt), cmp.Compare(a.UserID, b.UserID))
+	})
+
+	return queued, nil
+}
+
+func (r *MemoryRepo) ListUserRanks(ctx context.Context, offset, limit int64) ([]*entity.UserRank, error) {
+	r.mu.Lock()
+	season := r.season
+	r.mu.Unlock()
+
+	return r.ListSeasonUserRanks(ctx, season, offset, limit)
+}
+
+func (r *MemoryRepo) ListSeasonUserRanks(_ context.Context, seasonID string, offset, limit int64) ([]*entity.UserRank, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	userRanks := r.userRanks(seasonID)
+	start := min(max(offset, 0), int64(len(userRanks)))
+	end := min(start+limit, int64(len(userRanks)))
+
+	return userRanks[start:end], nil
+}
+
+func (r *MemoryRepo) GetUserRank(ctx context.Context, userID string) (*entity.UserRank, error) {
+	r.mu.Lock()
+	season := r.season
+	r.mu.Unlock()
+
+	return r.GetSeasonUserRank(ctx, season, userID)
+}
+
+func (r *MemoryRepo) GetSeasonUserRank(_ context.Context, seasonID, userID string) (*entity.UserRank, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, userRank := range r.userRanks(seasonID) {
+		if userRank.UserID == userID {
+			return userRank, nil
+		}
+	}
+
+	return nil, repo.ErrNotFound
+}
+
+func (r *MemoryRepo) ListRatingHistory(
+	_ context.Context,
+	userID string,
+	from, to int64,
+	offset, limit int64,
+) ([]*entity.RatingHistory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	var history []*entity.RatingHistory
+	entries := r.history[userID]
+	for idx := len(entries) - 1; idx >= 0; idx-- {
+		entry := entries[idx]
+		if (from > 0 && entry.CreatedAt < from) || (to > 0 && entry.CreatedAt > to) {
+			continue
+		}
+
+		if offset > 0 {
+			offset--
+			continue
+		}
+
+		clone := *entry
+		history = append(history, &clone)
+		if int64(len(history)) == limit {
+			break
+		}
+	}
+
+	return history, nil
+}
+
+func (r *MemoryRepo) ListInactiveUserIDs(_ context.Context, before, offset, limit int64) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	inactivity := r.inactivity[r.season]
+	var userIDs []string
+	for userID, inactiveSince := range inactivity {
+		if inactiveSince <= before {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	slices.SortFunc(userIDs, func(a, b string) int {
+		return cmp.Or(cmp.Compare(inactivity[a], inactivity[b]), cmp.Compare(a, b))
+	})
+
+	start := min(max(offset, 0), int64(len(userIDs)))
+	end := min(start+limit, int64(len(userIDs)))
+
+	return userIDs[start:end], nil
+}
+
+func (r *MemoryRepo) ApplyDecay(
+	_ context.Context,
+	userID string,
+	before, period, decayedAt int64,
+	fn repo.DecayFunc,
+) (*entity.RatingHistory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inactivity := r.seasonInactivity(r.season)
+	inactiveSince, ok := inactivity[userID]
+	// A battle since the user was listed makes the user active again.
+	if !ok || inactiveSince > before {
+		return nil, repo.ErrNotFound
+	}
+
+	userElo := r.userElos(r.season, []string{userID})[0]
+	newUserElo := fn(userElo)
+	if newUserElo == nil {
+		return nil, nil
+	}
+
+	inactivity[userID] = inactiveSince + period
+
+	entry := &entity.RatingHistory{
+		Season:    r.season,
+		UserID:    userID,
+		Reason:    entity.HistoryReasonDecay,
+		OldElo:    userElo.Elo,
+		NewElo:    newUserElo.Elo,
+		CreatedAt: decayedAt,
+	}
+	r.setUserElos(r.season, []*entity.UserElo{newUserElo})
+	r.addRatingHistory([]*entity.RatingHistory{entry})
+
+	return entry, nil
+}
+
+func (r *MemoryRepo) GetCurrentSeason(_ context.Context) (*entity.Season, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &entity.Season{ID: r.season}, nil
+}
+
+func (r *MemoryRepo) GetSeason(_ context.Context, seasonID string) (*entity.Season, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if seasonID == r.season {
+		return &entity.Season{ID: seasonID}, nil
+	}
+
+	endedAt, ok := r.seasons[seasonID]
+	if !ok {
+		return nil, repo.ErrNotFound
+	}
+
+	return &entity.Season{ID: seasonID, EndedAt: endedAt}, nil
+}
+
+func (r *MemoryRepo) ListSeasons(_ context.Context) ([]*entity.Season, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seasons := make([]*entity.Season, 0, len(r.seasons))
+	for seasonID, endedAt := range r.seasons {
+		seasons = append(seasons, &entity.Season{ID: seasonID, EndedAt: endedAt})
+	}
+
+	slices.SortFunc(seasons, func(a, b *entity.Season) int {
+		return cmp.Or(cmp.Compare(b.EndedAt, a.EndedAt), cmp.Compare(b.ID, a.ID))
+	})
+
+	return seasons, nil
+}
+
+func (r *MemoryRepo) RolloverSeason(
+	_ context.Context,
+	seasonID string,
+	endedAt int64,
+	fn repo.SeasonResetFunc,
+) (*entity.Season, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.season == "" {
+		return nil, repo.ErrUnnamedSeason
+	}
+
+	if _, ok := r.seasons[seasonID]; ok || seasonID == r.season {
+		return nil, repo.ErrAlreadyExists
+	}
+
+	// The elos of the ended season stay untouched as its final standings.
+	newUserElos := make([]*entity.UserElo, 0, len(r.elos[r.season]))
+	for _, userElo := range r.elos[r.season] {
+		newUserElos = append(newUserElos, fn(userElo.Clone()))
+	}
+
+	r.setUserElos(seasonID, newUserElos)
+	// The users keep decaying from their last battle rather than from the rollover.
+	r.inactivity[seasonID] = maps.Clone(r.seasonInactivity(r.season))
+	endedSeason := &entity.Season{ID: r.season, EndedAt: endedAt}
+	r.seasons[r.season] = endedAt
+	r.season = seasonID
+
+	return endedSeason, nil
+}
+
+// dropQueuedBefore removes the users queued before the unix timestamp since from the matchmaking queue.
+func (r *MemoryRepo) dropQueuedBefore(since int64) {
+	for userID, joinedAt := range r.queue {
+		if joinedAt < since {
+			delete(r.queue, userID)
+		}
+	}
+}
+
+// userElos returns copies of the elos of userIDs in a season in the same order, unknown users get the default elo.
+func (r *MemoryRepo) userElos(season string, userIDs []string) []*entity.UserElo {
+	userElos := make([]*entity.UserElo, 0, len(userIDs))
+	for _, userID := range userIDs {
+		userElo, ok := r.elos[season][userID]
+		if !ok {
+			userElos = append(userElos, entity.NewUserDefaultElo(userID))
+			continue
+		}
+
+		userElos = append(userElos, userElo.Clone())
+	}
+
+	return userElos
+}
+
+// setUserElos stores copies of elos in a season.
+func (r *MemoryRepo) setUserElos(season string, elos []*entity.UserElo) {
+	if r.elos[season] == nil {
+		r.elos[season] = make(map[string]*entity.UserElo)
+	}
+
+	for _, elo := range elos {
+		r.elos[season][elo.UserID] = elo.Clone()
+	}
+}
+
+// userRanks returns the leaderboard of a season, the users with the same elo are ordered by descending user id
+// as RedisRepo does.
+func (r *MemoryRepo) userRanks(season string) []*entity.UserRank {
+	userRanks := make([]*entity.UserRank, 0, len(r.elos[season]))
+	for userID, userElo := range r.elos[season] {
+		userRanks = append(userRanks, &entity.UserRank{UserID: userID, Elo: userElo.Elo})
+	}
+
+	slices.SortFunc(userRanks, func(a, b *entity.UserRank) int {
+		return cmp.Or(cmp.Compare(b.Elo, a.Elo), cmp.Compare(b.UserID, a.UserID))
+	})
+
+	for idx, userRank := range userRanks {
+		userRank.Rank = int64(idx) + 1
+	}
+
+	return userRanks
+}
+
+// seasonInactivity returns the inactivity of the users of a season.
+func (r *MemoryRepo) seasonInactivity(season string) map[string]int64 {
+	if r.inactivity[season] == nil {
+		r.inactivity[season] = make(map[string]int64)
+	}
+
+	return r.inactivity[season]
+}
+
+// addRatingHistory appends the history entries, every user history is ordered by creation time.
+func (r *MemoryRepo) addRatingHistory(history []*entity.RatingHistory) {
+	for _, entry := range history {
+		clone := *entry
+		entries := r.history[entry.UserID]
+		idx, _ := slices.BinarySearchFunc(entries, entry.CreatedAt+1, func(e *entity.RatingHistory, createdAt int64) int {
+			return cmp.Compare(e.CreatedAt, createdAt)
+		})
+		r.history[entry.UserID] = slices.Insert(entries, idx, &clone)
+	}
+}
+
+// addBattleLog appends entry to the battle log.
+func (r *MemoryRepo) addBattleLog(entry *entity.BattleLog) {
+	clone := *entry
+	clone.ID = strconv.Itoa(len(r.battleLog) + 1)
+	r.battleLog = append(r.battleLog, &clone)
+}
+
+func (r *MemoryRepo) getBattle(battleID string) (*entity.Battle, error) {
+	data, ok := r.battles[battleID]
+	if !ok {
+		return nil, repo.ErrNotFound
+	}
+
+	battle := &entity.Battle{}
+	if err := json.Unmarshal(data, battle); err != nil {
+		return nil, err
+	}
+
+	return battle, nil
+}
+
+func (r *MemoryRepo) setBattle(battle *entity.Battle) error {
+	battleData, err := json.Marshal(battle)
+	if err != nil {
+		return err
+	}
+
+	r.battles[battle.ID] = battleData
+	return nil
+}
@@ -0,0 +1,254 @@
+package repoimpl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/repo"
+	"github.com/me0den/example-service/infra/config"
+)
+
+func newTestMemoryRepo() *MemoryRepo {
+	cfg := &config.Config{}
+	cfg.Season.ID = "s1"
+
+	return NewMemoryRepo(cfg)
+}
+
+func TestMemoryRepo_ApplyBattle(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMemoryRepo()
+
+	battle, err := r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"}, addEloFunc("battle_1", 10))
+	require.NoError(t, err)
+	assert.Equal(t, "s1", battle.Season)
+
+	// Changing the returned battle does not change the stored one.
+	battle.Rewards[0].NewElo = 0
+	replayed, err := r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"},
+		func([]*entity.UserElo) (*entity.Battle, []*entity.UserElo, error) {
+			t.Fatal("battle must not be calculated again")
+			return nil, nil, nil
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo+10, replayed.Rewards[0].NewElo)
+
+	userElos, err := r.ListUserElos(ctx, []string{"user_2", "user_3"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo+10, userElos[0].Elo)
+	assert.Equal(t, entity.NewUserDefaultElo("user_3"), userElos[1])
+
+	entries, err := r.ListBattleLog(ctx, "", 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "1", entries[0].ID)
+	assert.Equal(t, "battle_1", entries[0].BattleID)
+}
+
+func TestMemoryRepo_ApplyBattle_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMemoryRepo()
+
+	const battles = 50
+	var wg sync.WaitGroup
+	for i := range battles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			battleID := fmt.Sprintf("battle_%d", i)
+			_, err := r.ApplyBattle(ctx, battleID, []string{"user_1", fmt.Sprintf("opponent_%d", i)}, addEloFunc(battleID, 1))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	userElo, err := r.GetUserElo(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo+battles, userElo.Elo)
+}
+
+func TestMemoryRepo_VoidBattle(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMemoryRepo()
+
+	_, err := r.VoidBattle(ctx, "battle_x", 100)
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+
+	_, err = r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"}, addEloFunc("battle_1", 10))
+	require.NoError(t, err)
+	battle, err := r.VoidBattle(ctx, "battle_1", 100)
+	require.NoError(t, err)
+	assert.True(t, battle.IsVoided())
+
+	userElo, err := r.GetUserElo(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo, userElo.Elo)
+
+	history, err := r.ListRatingHistory(ctx, "user_1", 0, 0, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, entity.HistoryReasonVoid, history[0].Reason)
+	assert.Equal(t, entity.HistoryReasonBattle, history[1].Reason)
+
+	_, err = r.VoidBattle(ctx, "battle_1", 200)
+	assert.ErrorIs(t, err, repo.ErrVoided)
+}
+
+func TestMemoryRepo_Leaderboard(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMemoryRepo()
+	require.NoError(t, r.BatchUpdateElo(ctx, []*entity.UserElo{
+		{UserID: "user_1", Elo: 1200},
+		{UserID: "user_2", Elo: 900},
+		{UserID: "user_3", Elo: 1200},
+	}))
+
+	userRanks, err := r.ListUserRanks(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.UserRank{
+		{UserID: "user_1", Elo: 1200, Rank: 2},
+		{UserID: "user_2", Elo: 900, Rank: 3},
+	}, userRanks)
+
+	userRank, err := r.GetUserRank(ctx, "user_3")
+	require.NoError(t, err)
+	assert.Equal(t, &entity.UserRank{UserID: "user_3", Elo: 1200, Rank: 1}, userRank)
+
+	_, err = r.GetUserRank(ctx, "unknown_user")
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+}
+
+func TestMemoryRepo_RolloverSeason(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMemoryRepo()
+	require.NoError(t, r.BatchUpdateElo(ctx, []*entity.UserElo{{UserID: "user_1", Elo: 1200}}))
+	r.inactivity["s1"] = map[string]int64{"user_1": 50}
+
+	ended, err := r.RolloverSeason(ctx, "s2", 100, func(userElo *entity.UserElo) *entity.UserElo {
+		userElo.Elo = entity.DefaultElo
+		return userElo
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &entity.Season{ID: "s1", EndedAt: 100}, ended)
+
+	_, err = r.RolloverSeason(ctx, "s1", 200, nil)
+	assert.ErrorIs(t, err, repo.ErrAlreadyExists)
+
+	userElo, err := r.GetUserElo(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo, userElo.Elo)
+
+	// The inactivity of the users is carried over.
+	userIDs, err := r.ListInactiveUserIDs(ctx, 60, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_1"}, userIDs)
+
+	// The ended season keeps its final standings.
+	userRank, err := r.GetSeasonUserRank(ctx, "s1", "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, 1200, userRank.Elo)
+
+	seasons, err := r.ListSeasons(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.Season{{ID: "s1", EndedAt: 100}}, seasons)
+}
+
+func TestMemoryRepo_ApplyDecay(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMemoryRepo()
+	_, err := r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"}, addEloFunc("battle_1", 10))
+	require.NoError(t, err)
+
+	userIDs, err := r.ListInactiveUserIDs(ctx, 0, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_1", "user_2"}, userIDs)
+
+	decay := func(userElo *entity.UserElo) *entity.UserElo {
+		newUserElo := userElo.Clone()
+		newUserElo.Elo -= 5
+		return newUserElo
+	}
+	entry, err := r.ApplyDecay(ctx, "user_1", 0, 100, 50, decay)
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo+5, entry.NewElo)
+
+	// The inactivity of the user is counted from a period later.
+	_, err = r.ApplyDecay(ctx, "user_1", 0, 100, 50, decay)
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+	userIDs, err = r.ListInactiveUserIDs(ctx, 0, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_2"}, userIDs)
+
+	// An elo not decaying writes nothing, the user stays inactive.
+	entry, err = r.ApplyDecay(ctx, "user_2", 0, 100, 50, func(*entity.UserElo) *entity.UserElo { return nil })
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+	userIDs, err = r.ListInactiveUserIDs(ctx, 0, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_2"}, userIDs)
+	userIDs, err = r.ListInactiveUserIDs(ctx, 0, 1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, userIDs)
+}
+
+func TestMemoryRepo_SwapReplay(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMemoryRepo()
+	_, err := r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"}, addEloFunc("battle_1", 10))
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, r.SwapReplay(ctx, "ns", "s1", "1"), repo.ErrNotFound)
+
+	require.NoError(t, r.StoreReplay(ctx, "ns", "s1", []*entity.UserElo{{UserID: "user_1", Elo: 1020}}))
+	assert.ErrorIs(t, r.SwapReplay(ctx, "ns", "s1", ""), repo.ErrConflict)
+	assert.ErrorIs(t, r.SwapReplay(ctx, "ns", "s2", "1"), repo.ErrConflict)
+	require.NoError(t, r.SwapReplay(ctx, "ns", "s1", "1"))
+
+	userElos, err := r.ListUserElos(ctx, []string{"user_1", "user_2"})
+	require.NoError(t, err)
+	assert.Equal(t, 1020, userElos[0].Elo)
+	assert.Equal(t, entity.NewUserDefaultElo("user_2"), userElos[1])
+}
+
+func TestMemoryRepo_MatchmakingQueue(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMemoryRepo()
+
+	queued, err := r.JoinQueue(ctx, "user_1", 100, 0)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.QueuedUser{UserID: "user_1", Elo: entity.DefaultElo, JoinedAt: 100}, queued)
+
+	// A queued user keeps the time they joined at.
+	queued, err = r.JoinQueue(ctx, "user_1", 150, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), queued.JoinedAt)
+
+	_, err = r.JoinQueue(ctx, "user_2", 120, 0)
+	require.NoError(t, err)
+
+	queued, err = r.GetQueuedUser(ctx, "user_1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.QueuedUser{UserID: "user_1", Elo: entity.DefaultElo, JoinedAt: 100}, queued)
+
+	// The users queued before since are removed.
+	users, err := r.ListQueuedUsers(ctx, 110, entity.DefaultElo, entity.DefaultElo)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.QueuedUser{{UserID: "user_2", Elo: entity.DefaultElo, JoinedAt: 120}}, users)
+	_, err = r.GetQueuedUser(ctx, "user_1", 110)
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+
+	// The users rated out of the band are not listed.
+	users, err = r.ListQueuedUsers(ctx, 110, entity.DefaultElo+1, entity.DefaultElo+100)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	require.NoError(t, r.LeaveQueue(ctx, "user_2"))
+	assert.ErrorIs(t, r.LeaveQueue(ctx, "user_2"), repo.ErrNotFound)
+}
@@ -0,0 +1,108 @@
+package repoimpl
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// migrationLockID is the advisory lock serializing the migrations of concurrently starting instances.
+const migrationLockID = 4280457126
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// migration is a schema change of the Postgres storage, migrations are applied by ascending version.
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// Migrate applies the pending migrations of the Postgres storage in a single transaction, the applied versions
+// are recorded in the schema_migrations table.
+func Migrate(ctx context.Context, db pgxPool) error {
+	migrations, err := loadMigrations(migrationFS)
+	if err != nil {
+		return err
+	}
+
+	return inTx(ctx, db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    INTEGER PRIMARY KEY,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, `SELECT version FROM schema_migrations`)
+		if err != nil {
+			return err
+		}
+
+		applied, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if slices.Contains(applied, m.version) {
+				continue
+			}
+
+			if _, err := tx.Exec(ctx, m.sql); err != nil {
+				return fmt.Errorf("error when apply migration %s: %w", m.name, err)
+			}
+
+			if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, m.version); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// loadMigrations returns the migrations of fsys named <version>_<description>.sql, ordered by version.
+func loadMigrations(fsys fs.FS) ([]migration, error) {
+	names, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(names))
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(path.Base(name), "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version of %s: %w", name, err)
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, migration{version: version, name: path.Base(name), sql: string(data)})
+	}
+
+	slices.SortFunc(migrations, func(a, b migration) int { return a.version - b.version })
+	for idx := 1; idx < len(migrations); idx++ {
+		if migrations[idx].version == migrations[idx-1].version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[idx].version)
+		}
+	}
+
+	return migrations, nil
+}
@@ -0,0 +1,79 @@
+-- Seasons, the current season is the one not ended yet.
+CREATE TABLE seasons (
+    id       TEXT PRIMARY KEY,
+    ended_at BIGINT
+);
+
+CREATE UNIQUE INDEX seasons_current_idx ON seasons ((ended_at IS NULL)) WHERE ended_at IS NULL;
+
+-- Elos of the users of every season, the leaderboard orders them by descending elo.
+CREATE TABLE user_elos (
+    season       TEXT             NOT NULL,
+    user_id      TEXT             NOT NULL,
+    elo          INTEGER          NOT NULL,
+    deviation    DOUBLE PRECISION NOT NULL,
+    volatility   DOUBLE PRECISION NOT NULL,
+    games_played INTEGER          NOT NULL,
+    PRIMARY KEY (season, user_id)
+);
+
+CREATE INDEX user_elos_rank_idx ON user_elos (season, elo DESC, user_id DESC);
+
+-- Time the inactivity of every user of a season is counted from.
+CREATE TABLE user_inactivity (
+    season         TEXT   NOT NULL,
+    user_id        TEXT   NOT NULL,
+    inactive_since BIGINT NOT NULL,
+    PRIMARY KEY (season, user_id)
+);
+
+CREATE INDEX user_inactivity_since_idx ON user_inactivity (season, inactive_since);
+
+CREATE TABLE rating_history (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    TEXT   NOT NULL,
+    created_at BIGINT NOT NULL,
+    entry      JSONB  NOT NULL
+);
+
+CREATE INDEX rating_history_user_idx ON rating_history (user_id, created_at DESC, id DESC);
+
+-- Processed battles, they are kept to replay retried rewards and to void them.
+CREATE TABLE battles (
+    id     TEXT PRIMARY KEY,
+    battle JSONB NOT NULL
+);
+
+-- Append-only log of the accepted and voided battles.
+CREATE TABLE battle_log (
+    id    BIGINT PRIMARY KEY,
+    entry JSONB NOT NULL
+);
+
+-- Last id of the battle log. Appending to the log locks the row until commit, so ids are assigned in commit
+-- order and a reader of the log never skips an entry committed later with a smaller id.
+CREATE TABLE battle_log_seq (
+    singleton BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (singleton),
+    last_id   BIGINT  NOT NULL
+);
+
+INSERT INTO battle_log_seq (last_id) VALUES (0);
+
+-- Elos recomputed from the battle log until they are swapped in.
+CREATE TABLE replay_user_elos (
+    namespace    TEXT             NOT NULL,
+    season       TEXT             NOT NULL,
+    user_id      TEXT             NOT NULL,
+    elo          INTEGER          NOT NULL,
+    deviation    DOUBLE PRECISION NOT NULL,
+    volatility   DOUBLE PRECISION NOT NULL,
+    games_played INTEGER          NOT NULL,
+    PRIMARY KEY (namespace, season, user_id)
+);
+
+CREATE TABLE matchmaking_queue (
+    user_id   TEXT PRIMARY KEY,
+    joined_at BIGINT NOT NULL
+);
+
+CREATE INDEX matchmaking_queue_joined_at_idx ON matchmaking_queue (joined_at);
@@ -0,0 +1,854 @@
+package repoimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/repo"
+	"github.com/me0den/example-service/infra/config"
+)
+
+const userEloColumns = "user_id, elo, deviation, volatility, games_played"
+
+// Locks taken on the row of the current season. Every write of the current season shares the lock, so the
+// writes replacing the whole season wait for them by taking it exclusively.
+const (
+	seasonNoLock     = ""
+	seasonLockShare  = " FOR SHARE"
+	seasonLockUpdate = " FOR UPDATE"
+)
+
+// pgxQuerier is the subset of pgx.Tx and *pgxpool.Pool used by the queries of PostgresRepo.
+type pgxQuerier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// pgxPool is the subset of *pgxpool.Pool used by PostgresRepo.
+type pgxPool interface {
+	pgxQuerier
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// PostgresRepo implements repo.RatingRepo on Postgres, the multi-row updates run in a single transaction.
+//
+// Unlike RedisRepo, processed battles are kept beyond the retention window.
+type PostgresRepo struct {
+	db pgxPool
+	// season is stored as the current season by Init when there is none.
+	season string
+}
+
+// NewPostgresRepo creates and returns a new instance of PostgresRepo.
+func NewPostgresRepo(
+	db pgxPool,
+	cfg *config.Config,
+) *PostgresRepo {
+	return &PostgresRepo{
+		db:     db,
+		season: cfg.Season.ID,
+	}
+}
+
+// Init stores the configured season as the current season if there is none yet, it must run after Migrate.
+func (r *PostgresRepo) Init(ctx context.Context) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO seasons (id)
+SELECT $1 WHERE NOT EXISTS (SELECT 1 FROM seasons WHERE ended_at IS NULL)
+ON CONFLICT (id) DO NOTHING`, r.season)
+
+	return err
+}
+
+func (r *PostgresRepo) GetUserElo(ctx context.Context, userID string) (*entity.UserElo, error) {
+	userElos, err := r.ListUserElos(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+
+	return userElos[0], nil
+}
+
+func (r *PostgresRepo) ListUserElos(ctx context.Context, userIDs []string) ([]*entity.UserElo, error) {
+	season, err := currentSeason(ctx, r.db, seasonNoLock)
+	if err != nil {
+		return nil, err
+	}
+
+	return queryUserElos(ctx, r.db, season, userIDs, "")
+}
+
+func (r *PostgresRepo) BatchUpdateElo(ctx context.Context, elos []*entity.UserElo) error {
+	return inTx(ctx, r.db, func(tx pgx.Tx) error {
+		season, err := currentSeason(ctx, tx, seasonLockShare)
+		if err != nil {
+			return err
+		}
+
+		batch := &pgx.Batch{}
+		queueUserElos(batch, season, elos)
+		return tx.SendBatch(ctx, batch).Close()
+	})
+}
+
+func (r *PostgresRepo) GetBattle(ctx context.Context, battleID string) (*entity.Battle, error) {
+	return getPostgresBattle(ctx, r.db, battleID, "")
+}
+
+func (r *PostgresRepo) ApplyBattle(
+	ctx context.Context,
+	battleID string,
+	userIDs []string,
+	fn repo.BattleFunc,
+) (*entity.Battle, error) {
+	var battle *entity.Battle
+	err := inTx(ctx, r.db, func(tx pgx.Tx) error {
+		season, err := currentSeason(ctx, tx, seasonLockShare)
+		if err != nil {
+			return err
+		}
+
+		// Concurrent retries of the battle wait for the first one, then find the stored battle.
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, battleID); err != nil {
+			return err
+		}
+
+		storedBattle, err := getPostgresBattle(ctx, tx, battleID, "")
+		if err == nil {
+			battle = storedBattle
+			return nil
+		}
+		if !errors.Is(err, repo.ErrNotFound) {
+			return err
+		}
+
+		userElos, err := lockUserElos(ctx, tx, season, userIDs)
+		if err != nil {
+			return err
+		}
+
+		newBattle, newUserElos, err := fn(userElos)
+		if err != nil {
+			return err
+		}
+
+		newBattle.Season = season
+		batch := &pgx.Batch{}
+		queueUserElos(batch, season, newUserElos)
+		history := newBattle.History()
+		for _, entry := range history {
+			batch.Queue(`INSERT INTO user_inactivity (season, user_id, inactive_since) VALUES ($1, $2, $3)
+ON CONFLICT (season, user_id) DO UPDATE SET inactive_since = EXCLUDED.inactive_since`, season, entry.UserID, entry.CreatedAt)
+		}
+
+		if err := queueRatingHistory(batch, history); err != nil {
+			return err
+		}
+
+		battleData, err := json.Marshal(newBattle)
+		if err != nil {
+			return err
+		}
+
+		batch.Queue(`INSERT INTO battles (id, battle) VALUES ($1, $2)`, newBattle.ID, battleData)
+		if err := queueBattleLog(batch, newBattle.Log()); err != nil {
+			return err
+		}
+
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return err
+		}
+
+		battle = newBattle
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return battle, nil
+}
+
+func (r *PostgresRepo) VoidBattle(ctx context.Context, battleID string, voidedAt int64) (*entity.Battle, error) {
+	var battle *entity.Battle
+	err := inTx(ctx, r.db, func(tx pgx.Tx) error {
+		currentSeasonID, err := currentSeason(ctx, tx, seasonLockShare)
+		if err != nil {
+			return err
+		}
+
+		storedBattle, err := getPostgresBattle(ctx, tx, battleID, " FOR UPDATE")
+		if err != nil {
+			return err
+		}
+		if storedBattle.IsVoided() {
+			return repo.ErrVoided
+		}
+
+		// A battle recorded before seasons were stored on it belongs to the current season.
+		season := storedBattle.Season
+		if season == "" {
+			season = currentSeasonID
+		}
+		if season != currentSeasonID {
+			return repo.ErrSeasonEnded
+		}
+
+		userIDs := make([]string, 0, len(storedBattle.Rewards))
+		for _, reward := range storedBattle.Rewards {
+			userIDs = append(userIDs, reward.UserID)
+		}
+
+		userElos, err := lockUserElos(ctx, tx, season, userIDs)
+		if err != nil {
+			return err
+		}
+
+		storedBattle.Season = season
+		newUserElos := storedBattle.Void(userElos, voidedAt)
+		batch := &pgx.Batch{}
+		queueUserElos(batch, season, newUserElos)
+		if err := queueRatingHistory(batch, storedBattle.ReversalHistory()); err != nil {
+			return err
+		}
+
+		battleData, err := json.Marshal(storedBattle)
+		if err != nil {
+			return err
+		}
+
+		batch.Queue(`UPDATE battles SET battle = $2 WHERE id = $1`, storedBattle.ID, battleData)
+		if err := queueBattleLog(batch, storedBattle.VoidLog()); err != nil {
+			return err
+		}
+
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return err
+		}
+
+		battle = storedBattle
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return battle, nil
+}
+
+func (r *PostgresRepo) ListUserRanks(ctx context.Context, offset, limit int64) ([]*entity.UserRank, error) {
+	season, err := currentSeason(ctx, r.db, seasonNoLock)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.ListSeasonUserRanks(ctx, season, offset, limit)
+}
+
+// ListSeasonUserRanks orders the users with the same elo by descending user id, as RedisRepo does.
+func (r *PostgresRepo) ListSeasonUserRanks(ctx context.Context, seasonID string, offset, limit int64) ([]*entity.UserRank, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT user_id, elo FROM user_elos WHERE season = $1
+ORDER BY elo DESC, user_id DESC OFFSET $2 LIMIT $3`, seasonID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := offset
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.UserRank, error) {
+		idx++
+		userRank := &entity.UserRank{Rank: idx}
+		return userRank, row.Scan(&userRank.UserID, &userRank.Elo)
+	})
+}
+
+func (r *PostgresRepo) GetUserRank(ctx context.Context, userID string) (*entity.UserRank, error) {
+	season, err := currentSeason(ctx, r.db, seasonNoLock)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetSeasonUserRank(ctx, season, userID)
+}
+
+func (r *PostgresRepo) GetSeasonUserRank(ctx context.Context, seasonID, userID string) (*entity.UserRank, error) {
+	userRank := &entity.UserRank{UserID: userID}
+	err := r.db.QueryRow(ctx, `SELECT e.elo, (
+	SELECT count(*) FROM user_elos o WHERE o.season = e.season AND (o.elo, o.user_id) > (e.elo, e.user_id)
+) + 1
+FROM user_elos e WHERE e.season = $1 AND e.user_id = $2`, seasonID, userID).Scan(&userRank.Elo, &userRank.Rank)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repo.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return userRank, nil
+}
+
+func (r *PostgresRepo) ListRatingHistory(
+	ctx context.Context,
+	userID string,
+	from, to int64,
+	offset, limit int64,
+) ([]*entity.RatingHistory, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT entry FROM rating_history
+WHERE user_id = $1 AND ($2::BIGINT <= 0 OR created_at >= $2) AND ($3::BIGINT <= 0 OR created_at <= $3)
+ORDER BY created_at DESC, id DESC OFFSET $4 LIMIT $5`, userID, from, to, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.RatingHistory, error) {
+		entry := &entity.RatingHistory{}
+		return entry, row.Scan(entry)
+	})
+}
+
+func (r *PostgresRepo) ListInactiveUserIDs(ctx context.Context, before, offset, limit int64) ([]string, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	season, err := currentSeason(ctx, r.db, seasonNoLock)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT user_id FROM user_inactivity WHERE season = $1 AND inactive_since <= $2
+ORDER BY inactive_since, user_id OFFSET $3 LIMIT $4`, season, before, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (r *PostgresRepo) ApplyDecay(
+	ctx context.Context,
+	userID string,
+	before, period, decayedAt int64,
+	fn repo.DecayFunc,
+) (*entity.RatingHistory, error) {
+	var entry *entity.RatingHistory
+	err := inTx(ctx, r.db, func(tx pgx.Tx) error {
+		season, err := currentSeason(ctx, tx, seasonLockShare)
+		if err != nil {
+			return err
+		}
+
+		var inactiveSince int64
+		err = tx.QueryRow(ctx, `SELECT inactive_since FROM user_inactivity WHERE season = $1 AND user_id = $2 FOR UPDATE`,
+			season, userID).Scan(&inactiveSince)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repo.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// A battle since the user was listed makes the user active again.
+		if inactiveSince > before {
+			return repo.ErrNotFound
+		}
+
+		userElos, err := lockUserElos(ctx, tx, season, []string{userID})
+		if err != nil {
+			return err
+		}
+
+		newUserElo := fn(userElos[0])
+		if newUserElo == nil {
+			return nil
+		}
+
+		entry = &entity.RatingHistory{
+			Season:    season,
+			UserID:    userID,
+			Reason:    entity.HistoryReasonDecay,
+			OldElo:    userElos[0].Elo,
+			NewElo:    newUserElo.Elo,
+			CreatedAt: decayedAt,
+		}
+		batch := &pgx.Batch{}
+		batch.Queue(`UPDATE user_inactivity SET inactive_since = $3 WHERE season = $1 AND user_id = $2`,
+			season, userID, inactiveSince+period)
+		queueUserElos(batch, season, []*entity.UserElo{newUserElo})
+		if err := queueRatingHistory(batch, []*entity.RatingHistory{entry}); err != nil {
+			return err
+		}
+
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *PostgresRepo) GetCurrentSeason(ctx context.Context) (*entity.Season, error) {
+	season, err := currentSeason(ctx, r.db, seasonNoLock)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Season{ID: season}, nil
+}
+
+func (r *PostgresRepo) GetSeason(ctx context.Context, seasonID string) (*entity.Season, error) {
+	season := &entity.Season{}
+	var endedAt *int64
+	err := r.db.QueryRow(ctx, `SELECT id, ended_at FROM seasons WHERE id = $1`, seasonID).Scan(&season.ID, &endedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repo.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if endedAt != nil {
+		season.EndedAt = *endedAt
+	}
+
+	return season, nil
+}
+
+func (r *PostgresRepo) ListSeasons(ctx context.Context) ([]*entity.Season, error) {
+	rows, err := r.db.Query(ctx, `SELECT id, ended_at FROM seasons WHERE ended_at IS NOT NULL ORDER BY ended_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.Season, error) {
+		season := &entity.Season{}
+		return season, row.Scan(&season.ID, &season.EndedAt)
+	})
+}
+
+func (r *PostgresRepo) RolloverSeason(
+	ctx context.Context,
+	seasonID string,
+	endedAt int64,
+	fn repo.SeasonResetFunc,
+) (*entity.Season, error) {
+	var endedSeason *entity.Season
+	err := inTx(ctx, r.db, func(tx pgx.Tx) error {
+		season, err := currentSeason(ctx, tx, seasonLockUpdate)
+		if err != nil {
+			return err
+		}
+
+		if season == "" {
+			return repo.ErrUnnamedSeason
+		}
+
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM seasons WHERE id = $1)`, seasonID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return repo.ErrAlreadyExists
+		}
+
+		rows, err := tx.Query(ctx, `SELECT `+userEloColumns+` FROM user_elos WHERE season = $1`, season)
+		if err != nil {
+			return err
+		}
+
+		userElos, err := pgx.CollectRows(rows, scanUserElo)
+		if err != nil {
+			return err
+		}
+
+		newUserElos := make([]*entity.UserElo, 0, len(userElos))
+		for _, userElo := range userElos {
+			newUserElos = append(newUserElos, fn(userElo))
+		}
+
+		// The elos of the ended season stay untouched as its final standings.
+		if _, err := tx.Exec(ctx, `UPDATE seasons SET ended_at = $2 WHERE id = $1`, season, endedAt); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `INSERT INTO seasons (id) VALUES ($1)`, seasonID); err != nil {
+			return err
+		}
+
+		if err := copyUserElos(ctx, tx, "user_elos", []string{"season"}, []any{seasonID}, newUserElos); err != nil {
+			return err
+		}
+
+		// The users keep decaying from their last battle rather than from the rollover.
+		if _, err := tx.Exec(ctx, `INSERT INTO user_inactivity (season, user_id, inactive_since)
+SELECT $2, user_id, inactive_since FROM user_inactivity WHERE season = $1`, season, seasonID); err != nil {
+			return err
+		}
+
+		endedSeason = &entity.Season{ID: season, EndedAt: endedAt}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return endedSeason, nil
+}
+
+func (r *PostgresRepo) ListBattleLog(ctx context.Context, after string, limit int64) ([]*entity.BattleLog, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	var afterID int64
+	if after != "" {
+		var err error
+		if afterID, err = strconv.ParseInt(after, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid battle log id %q: %w", after, err)
+		}
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT id, entry FROM battle_log WHERE id > $1 ORDER BY id LIMIT $2`, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.BattleLog, error) {
+		var id int64
+		entry := &entity.BattleLog{}
+		if err := row.Scan(&id, entry); err != nil {
+			return nil, err
+		}
+
+		entry.ID = strconv.FormatInt(id, 10)
+		return entry, nil
+	})
+}
+
+func (r *PostgresRepo) StoreReplay(ctx context.Context, namespace, season string, elos []*entity.UserElo) error {
+	return inTx(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM replay_user_elos WHERE namespace = $1 AND season = $2`, namespace, season); err != nil {
+			return err
+		}
+
+		return copyUserElos(ctx, tx, "replay_user_elos", []string{"namespace", "season"}, []any{namespace, season}, elos)
+	})
+}
+
+func (r *PostgresRepo) SwapReplay(ctx context.Context, namespace, season, lastLogID string) error {
+	return inTx(ctx, r.db, func(tx pgx.Tx) error {
+		// The exclusive lock of the season keeps battles from being accepted or voided until the swap commits.
+		currentSeasonID, err := currentSeason(ctx, tx, seasonLockUpdate)
+		if err != nil {
+			return err
+		}
+
+		if currentSeasonID != season {
+			return repo.ErrConflict
+		}
+
+		// Battles accepted or voided after the replay would be lost by the swap. The ids of the log are assigned
+		// in commit order, so the last one is the last entry the replay could have read.
+		var lastID int64
+		if err := tx.QueryRow(ctx, `SELECT last_id FROM battle_log_seq`).Scan(&lastID); err != nil {
+			return err
+		}
+
+		if lastID != 0 && strconv.FormatInt(lastID, 10) != lastLogID {
+			return repo.ErrConflict
+		}
+
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM replay_user_elos WHERE namespace = $1 AND season = $2)`,
+			namespace, season).Scan(&exists); err != nil {
+			return err
+		}
+
+		if !exists {
+			return repo.ErrNotFound
+		}
+
+		batch := &pgx.Batch{}
+		batch.Queue(`DELETE FROM user_elos WHERE season = $1`, season)
+		batch.Queue(`INSERT INTO user_elos (season, `+userEloColumns+`)
+SELECT season, `+userEloColumns+` FROM replay_user_elos WHERE namespace = $1 AND season = $2`, namespace, season)
+		batch.Queue(`DELETE FROM replay_user_elos WHERE namespace = $1 AND season = $2`, namespace, season)
+		return tx.SendBatch(ctx, batch).Close()
+	})
+}
+
+func (r *PostgresRepo) JoinQueue(ctx context.Context, userID string, joinedAt, since int64) (*entity.QueuedUser, error) {
+	if err := r.dropQueuedBefore(ctx, since); err != nil {
+		return nil, err
+	}
+
+	queuedUser := &entity.QueuedUser{UserID: userID}
+	if err := r.db.QueryRow(ctx, `INSERT INTO matchmaking_queue (user_id, joined_at) VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET joined_at = matchmaking_queue.joined_at
+RETURNING joined_at`, userID, joinedAt).Scan(&queuedUser.JoinedAt); err != nil {
+		return nil, err
+	}
+
+	queued, err := r.withElos(ctx, []*entity.QueuedUser{queuedUser})
+	if err != nil {
+		return nil, err
+	}
+
+	return queued[0], nil
+}
+
+func (r *PostgresRepo) LeaveQueue(ctx context.Context, userID string) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM matchmaking_queue WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repo.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresRepo) GetQueuedUser(ctx context.Context, userID string, since int64) (*entity.QueuedUser, error) {
+	queuedUser := &entity.QueuedUser{UserID: userID}
+	err := r.db.QueryRow(ctx, `SELECT joined_at FROM matchmaking_queue WHERE user_id = $1 AND joined_at >= $2`,
+		userID, since).Scan(&queuedUser.JoinedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repo.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	queued, err := r.withElos(ctx, []*entity.QueuedUser{queuedUser})
+	if err != nil {
+		return nil, err
+	}
+
+	return queued[0], nil
+}
+
+// ListQueuedUsers joins the queue with the elos of the rating band, which is a range of the leaderboard index
+// user_elos_rank_idx. The unrated queued users have the default elo.
+func (r *PostgresRepo) ListQueuedUsers(ctx context.Context, since int64, minElo, maxElo int) ([]*entity.QueuedUser, error) {
+	if err := r.dropQueuedBefore(ctx, since); err != nil {
+		return nil, err
+	}
+
+	season, err := currentSeason(ctx, r.db, seasonNoLock)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT q.user_id, e.elo, q.joined_at FROM matchmaking_queue q
+JOIN user_elos e ON e.season = $1 AND e.user_id = q.user_id
+WHERE e.elo BETWEEN $2 AND $3
+UNION ALL
+SELECT q.user_id, $4::INTEGER, q.joined_at FROM matchmaking_queue q
+WHERE $4::INTEGER BETWEEN $2 AND $3
+AND NOT EXISTS (SELECT 1 FROM user_elos e WHERE e.season = $1 AND e.user_id = q.user_id)
+ORDER BY joined_at, user_id`, season, minElo, maxElo, entity.DefaultElo)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.QueuedUser, error) {
+		queuedUser := &entity.QueuedUser{}
+		return queuedUser, row.Scan(&queuedUser.UserID, &queuedUser.Elo, &queuedUser.JoinedAt)
+	})
+}
+
+// dropQueuedBefore removes the users queued before the unix timestamp since from the matchmaking queue.
+func (r *PostgresRepo) dropQueuedBefore(ctx context.Context, since int64) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM matchmaking_queue WHERE joined_at < $1`, since)
+	return err
+}
+
+// withElos sets the elo in the current season of the queued users.
+func (r *PostgresRepo) withElos(ctx context.Context, queued []*entity.QueuedUser) ([]*entity.QueuedUser, error) {
+	userIDs := make([]string, 0, len(queued))
+	for _, queuedUser := range queued {
+		userIDs = append(userIDs, queuedUser.UserID)
+	}
+
+	userElos, err := r.ListUserElos(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, userElo := range userElos {
+		queued[idx].Elo = userElo.Elo
+	}
+
+	return queued, nil
+}
+
+// inTx runs fn in a transaction, it is committed if fn succeeds and rolled back otherwise.
+func inTx(ctx context.Context, db pgxPool, fn func(tx pgx.Tx) error) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// currentSeason returns the id of the current season and locks its row with lock until the end of the transaction.
+func currentSeason(ctx context.Context, q pgxQuerier, lock string) (string, error) {
+	var season string
+	err := q.QueryRow(ctx, `SELECT id FROM seasons WHERE ended_at IS NULL`+lock).Scan(&season)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", errors.New("no current season, the postgres repo is not initialized")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return season, nil
+}
+
+// lockUserElos returns the elos of userIDs in a season in the same order and locks them until the end of the
+// transaction. Unknown users are stored with the default elo first, so concurrent transactions wait for each other.
+func lockUserElos(ctx context.Context, tx pgx.Tx, season string, userIDs []string) ([]*entity.UserElo, error) {
+	// The rows are locked in the same order by every transaction, so they do not deadlock.
+	sortedIDs := slices.Compact(slices.Sorted(slices.Values(userIDs)))
+	if _, err := tx.Exec(ctx, `INSERT INTO user_elos (season, `+userEloColumns+`)
+SELECT $1, user_id, $3, $4, $5, 0 FROM unnest($2::TEXT[]) AS user_id ORDER BY user_id
+ON CONFLICT (season, user_id) DO NOTHING`,
+		season, sortedIDs, entity.DefaultElo, entity.DefaultDeviation, entity.DefaultVolatility); err != nil {
+		return nil, err
+	}
+
+	return queryUserElos(ctx, tx, season, userIDs, " ORDER BY user_id FOR UPDATE")
+}
+
+// queryUserElos returns the elos of userIDs in a season in the same order, unknown users get the default elo.
+// The stored elos are selected with suffix appended to the query.
+func queryUserElos(ctx context.Context, q pgxQuerier, season string, userIDs []string, suffix string) ([]*entity.UserElo, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := q.Query(ctx, `SELECT `+userEloColumns+` FROM user_elos WHERE season = $1 AND user_id = ANY($2)`+suffix,
+		season, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := pgx.CollectRows(rows, scanUserElo)
+	if err != nil {
+		return nil, err
+	}
+
+	storedByID := make(map[string]*entity.UserElo, len(stored))
+	for _, userElo := range stored {
+		storedByID[userElo.UserID] = userElo
+	}
+
+	userElos := make([]*entity.UserElo, 0, len(userIDs))
+	for _, userID := range userIDs {
+		userElo, ok := storedByID[userID]
+		if !ok {
+			userElo = entity.NewUserDefaultElo(userID)
+		}
+
+		userElos = append(userElos, userElo.Clone())
+	}
+
+	return userElos, nil
+}
+
+// scanUserElo scans a row of userEloColumns.
+func scanUserElo(row pgx.CollectableRow) (*entity.UserElo, error) {
+	userElo := &entity.UserElo{}
+	err := row.Scan(&userElo.UserID, &userElo.Elo, &userElo.Deviation, &userElo.Volatility, &userElo.GamesPlayed)
+
+	return userElo, err
+}
+
+// queueUserElos queues the upsert of elos in a season on batch.
+func queueUserElos(batch *pgx.Batch, season string, elos []*entity.UserElo) {
+	for _, elo := range elos {
+		batch.Queue(`INSERT INTO user_elos (season, `+userEloColumns+`) VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (season, user_id) DO UPDATE SET elo = EXCLUDED.elo, deviation = EXCLUDED.deviation,
+	volatility = EXCLUDED.volatility, games_played = EXCLUDED.games_played`,
+			season, elo.UserID, elo.Elo, elo.Deviation, elo.Volatility, elo.GamesPlayed)
+	}
+}
+
+// copyUserElos bulk inserts elos into table, every row starts with the values of keys in keyColumns.
+func copyUserElos(ctx context.Context, tx pgx.Tx, table string, keyColumns []string, keys []any, elos []*entity.UserElo) error {
+	columns := append(slices.Clone(keyColumns), "user_id", "elo", "deviation", "volatility", "games_played")
+	_, err := tx.CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromSlice(len(elos), func(idx int) ([]any, error) {
+		elo := elos[idx]
+		return append(slices.Clone(keys), elo.UserID, elo.Elo, elo.Deviation, elo.Volatility, elo.GamesPlayed), nil
+	}))
+
+	return err
+}
+
+// queueRatingHistory queues the insert of the history entries on batch.
+func queueRatingHistory(batch *pgx.Batch, history []*entity.RatingHistory) error {
+	for _, entry := range history {
+		entryData, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+
+		batch.Queue(`INSERT INTO rating_history (user_id, created_at, entry) VALUES ($1, $2, $3)`,
+			entry.UserID, entry.CreatedAt, entryData)
+	}
+
+	return nil
+}
+
+// queueBattleLog queues the append of entry to the battle log on batch. The id of the entry is taken from
+// battle_log_seq, whose row stays locked until commit, so it must be the last statement of the transaction.
+// The lock serialises every battle and void against each other whatever users they touch, trading their
+// concurrency for a log read in commit order; queued last, it is held only for the commit round trip.
+func queueBattleLog(batch *pgx.Batch, entry *entity.BattleLog) error {
+	entryData, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	batch.Queue(`WITH seq AS (UPDATE battle_log_seq SET last_id = last_id + 1 RETURNING last_id)
+INSERT INTO battle_log (id, entry) SELECT last_id, $1 FROM seq`, entryData)
+	return nil
+}
+
+// getPostgresBattle returns the processed battle by id or repo.ErrNotFound, the row is selected with lock.
+func getPostgresBattle(ctx context.Context, q pgxQuerier, battleID, lock string) (*entity.Battle, error) {
+	battle := &entity.Battle{}
+	err := q.QueryRow(ctx, `SELECT battle FROM battles WHERE id = $1`+lock, battleID).Scan(battle)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repo.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return battle, nil
+}
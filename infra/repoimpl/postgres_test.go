@@ -0,0 +1,350 @@
+package repoimpl
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/repo"
+	"github.com/me0den/example-service/infra/config"
+)
+
+func newTestPostgresRepo(t *testing.T) (*PostgresRepo, pgxmock.PgxPoolIface) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.Close()
+	})
+
+	return NewPostgresRepo(mock, &config.Config{}), mock
+}
+
+// expectCurrentSeason expects the current season to be read with lock.
+func expectCurrentSeason(mock pgxmock.PgxPoolIface, season, lock string) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM seasons WHERE ended_at IS NULL` + lock)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(season))
+}
+
+func userEloRows() *pgxmock.Rows {
+	return pgxmock.NewRows([]string{"user_id", "elo", "deviation", "volatility", "games_played"})
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFS)
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	assert.Equal(t, 1, migrations[0].version)
+	assert.Contains(t, migrations[0].sql, "CREATE TABLE user_elos")
+
+	migrations, err = loadMigrations(fstest.MapFS{
+		"migrations/0010_later.sql": {Data: []byte("SELECT 10")},
+		"migrations/0002_next.sql":  {Data: []byte("SELECT 2")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []migration{
+		{version: 2, name: "0002_next.sql", sql: "SELECT 2"},
+		{version: 10, name: "0010_later.sql", sql: "SELECT 10"},
+	}, migrations)
+
+	_, err = loadMigrations(fstest.MapFS{"migrations/init.sql": {Data: []byte("SELECT 1")}})
+	assert.Error(t, err)
+
+	_, err = loadMigrations(fstest.MapFS{
+		"migrations/0001_a.sql": {Data: []byte("SELECT 1")},
+		"migrations/001_b.sql":  {Data: []byte("SELECT 1")},
+	})
+	assert.Error(t, err)
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	_, mock := newTestPostgresRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock($1)`)).
+		WithArgs(migrationLockID).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).
+		WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT version FROM schema_migrations`)).
+		WillReturnRows(pgxmock.NewRows([]string{"version"}))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE seasons`)).
+		WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO schema_migrations (version) VALUES ($1)`)).
+		WithArgs(1).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	require.NoError(t, Migrate(ctx, mock))
+
+	// The applied migrations are skipped.
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock($1)`)).
+		WithArgs(migrationLockID).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).
+		WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT version FROM schema_migrations`)).
+		WillReturnRows(pgxmock.NewRows([]string{"version"}).AddRow(1))
+	mock.ExpectCommit()
+	require.NoError(t, Migrate(ctx, mock))
+}
+
+func TestPostgresRepo_ListUserElos(t *testing.T) {
+	ctx := context.Background()
+	r, mock := newTestPostgresRepo(t)
+
+	expectCurrentSeason(mock, "s1", seasonNoLock)
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM user_elos WHERE season = $1 AND user_id = ANY($2)`)).
+		WithArgs("s1", []string{"user_2", "unknown_user", "user_1"}).
+		WillReturnRows(userEloRows().
+			AddRow("user_1", 1200, 80.0, 0.05, 20).
+			AddRow("user_2", 900, 120.0, 0.06, 3))
+
+	userElos, err := r.ListUserElos(ctx, []string{"user_2", "unknown_user", "user_1"})
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.UserElo{
+		{UserID: "user_2", Elo: 900, Deviation: 120, Volatility: 0.06, GamesPlayed: 3},
+		entity.NewUserDefaultElo("unknown_user"),
+		{UserID: "user_1", Elo: 1200, Deviation: 80, Volatility: 0.05, GamesPlayed: 20},
+	}, userElos)
+}
+
+func TestPostgresRepo_BatchUpdateElo(t *testing.T) {
+	ctx := context.Background()
+	r, mock := newTestPostgresRepo(t)
+	elos := []*entity.UserElo{
+		{UserID: "user_1", Elo: 1100, Deviation: 300, Volatility: 0.06, GamesPlayed: 1},
+		{UserID: "user_2", Elo: 900, Deviation: 300, Volatility: 0.06, GamesPlayed: 1},
+	}
+
+	mock.ExpectBegin()
+	expectCurrentSeason(mock, "s1", seasonLockShare)
+	batch := mock.ExpectBatch()
+	for _, elo := range elos {
+		batch.ExpectExec(regexp.QuoteMeta(`INSERT INTO user_elos`)).
+			WithArgs("s1", elo.UserID, elo.Elo, elo.Deviation, elo.Volatility, elo.GamesPlayed).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	}
+	mock.ExpectCommit()
+	require.NoError(t, r.BatchUpdateElo(ctx, elos))
+
+	// A failed update leaves every elo untouched.
+	mock.ExpectBegin()
+	expectCurrentSeason(mock, "s1", seasonLockShare)
+	batch = mock.ExpectBatch()
+	batch.ExpectExec(regexp.QuoteMeta(`INSERT INTO user_elos`)).
+		WithArgs("s1", "user_1", 1100, 300.0, 0.06, 1).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	batch.ExpectExec(regexp.QuoteMeta(`INSERT INTO user_elos`)).
+		WithArgs("s1", "user_2", 900, 300.0, 0.06, 1).
+		WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+	assert.ErrorIs(t, r.BatchUpdateElo(ctx, elos), assert.AnError)
+}
+
+func TestPostgresRepo_ApplyBattle(t *testing.T) {
+	ctx := context.Background()
+	r, mock := newTestPostgresRepo(t)
+
+	mock.ExpectBegin()
+	expectCurrentSeason(mock, "s1", seasonLockShare)
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`)).
+		WithArgs("battle_1").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT battle FROM battles WHERE id = $1`)).
+		WithArgs("battle_1").WillReturnRows(pgxmock.NewRows([]string{"battle"}))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO user_elos`)).
+		WithArgs("s1", []string{"user_1", "user_2"}, entity.DefaultElo, entity.DefaultDeviation, entity.DefaultVolatility).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY user_id FOR UPDATE`)).
+		WithArgs("s1", []string{"user_2", "user_1"}).
+		WillReturnRows(userEloRows().
+			AddRow("user_1", 1000, 350.0, 0.06, 0).
+			AddRow("user_2", 1200, 80.0, 0.05, 20))
+	batch := mock.ExpectBatch()
+	batch.ExpectExec(regexp.QuoteMeta(`INSERT INTO user_elos`)).
+		WithArgs("s1", "user_2", 1210, 80.0, 0.05, 20).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	batch.ExpectExec(regexp.QuoteMeta(`INSERT INTO user_elos`)).
+		WithArgs("s1", "user_1", 1010, 350.0, 0.06, 0).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	for _, userID := range []string{"user_2", "user_1"} {
+		batch.ExpectExec(regexp.QuoteMeta(`INSERT INTO user_inactivity`)).
+			WithArgs("s1", userID, int64(0)).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	}
+	for _, userID := range []string{"user_2", "user_1"} {
+		batch.ExpectExec(regexp.QuoteMeta(`INSERT INTO rating_history`)).
+			WithArgs(userID, int64(0), pgxmock.AnyArg()).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	}
+	batch.ExpectExec(regexp.QuoteMeta(`INSERT INTO battles`)).
+		WithArgs("battle_1", pgxmock.AnyArg()).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	// The log entry is appended last, its id is taken from the sequence row locked until commit.
+	batch.ExpectExec(regexp.QuoteMeta(`UPDATE battle_log_seq SET last_id = last_id + 1 RETURNING last_id)
+INSERT INTO battle_log (id, entry)`)).
+		WithArgs(pgxmock.AnyArg()).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	battle, err := r.ApplyBattle(ctx, "battle_1", []string{"user_2", "user_1"}, addEloFunc("battle_1", 10))
+	require.NoError(t, err)
+	assert.Equal(t, "s1", battle.Season)
+	assert.Equal(t, []*entity.Reward{
+		{UserID: "user_2", OldElo: 1200, NewElo: 1210},
+		{UserID: "user_1", OldElo: 1000, NewElo: 1010},
+	}, battle.Rewards)
+
+	// A retried battle returns the stored battle without calling fn.
+	mock.ExpectBegin()
+	expectCurrentSeason(mock, "s1", seasonLockShare)
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`)).
+		WithArgs("battle_1").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT battle FROM battles WHERE id = $1`)).
+		WithArgs("battle_1").WillReturnRows(pgxmock.NewRows([]string{"battle"}).AddRow(*battle))
+	mock.ExpectCommit()
+
+	replayed, err := r.ApplyBattle(ctx, "battle_1", []string{"user_2", "user_1"},
+		func([]*entity.UserElo) (*entity.Battle, []*entity.UserElo, error) {
+			t.Fatal("fn must not be called for a processed battle")
+			return nil, nil, nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, battle, replayed)
+}
+
+func TestPostgresRepo_VoidBattle(t *testing.T) {
+	ctx := context.Background()
+	r, mock := newTestPostgresRepo(t)
+
+	mock.ExpectBegin()
+	expectCurrentSeason(mock, "s1", seasonLockShare)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT battle FROM battles WHERE id = $1 FOR UPDATE`)).
+		WithArgs("battle_1").
+		WillReturnRows(pgxmock.NewRows([]string{"battle"}).AddRow(entity.Battle{ID: "battle_1", VoidedAt: 100}))
+	mock.ExpectRollback()
+
+	_, err := r.VoidBattle(ctx, "battle_1", 200)
+	assert.ErrorIs(t, err, repo.ErrVoided)
+
+	mock.ExpectBegin()
+	expectCurrentSeason(mock, "s1", seasonLockShare)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT battle FROM battles WHERE id = $1 FOR UPDATE`)).
+		WithArgs("unknown_battle").WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err = r.VoidBattle(ctx, "unknown_battle", 200)
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+}
+
+func TestPostgresRepo_GetSeasonUserRank(t *testing.T) {
+	ctx := context.Background()
+	r, mock := newTestPostgresRepo(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM user_elos e WHERE e.season = $1 AND e.user_id = $2`)).
+		WithArgs("s1", "user_1").
+		WillReturnRows(pgxmock.NewRows([]string{"elo", "rank"}).AddRow(1200, int64(3)))
+	userRank, err := r.GetSeasonUserRank(ctx, "s1", "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, &entity.UserRank{UserID: "user_1", Elo: 1200, Rank: 3}, userRank)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM user_elos e WHERE e.season = $1 AND e.user_id = $2`)).
+		WithArgs("s1", "unknown_user").
+		WillReturnRows(pgxmock.NewRows([]string{"elo", "rank"}))
+	_, err = r.GetSeasonUserRank(ctx, "s1", "unknown_user")
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+}
+
+func TestPostgresRepo_ListBattleLog(t *testing.T) {
+	ctx := context.Background()
+	r, mock := newTestPostgresRepo(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, entry FROM battle_log WHERE id > $1 ORDER BY id LIMIT $2`)).
+		WithArgs(int64(41), int64(2)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "entry"}).
+			AddRow(int64(42), entity.BattleLog{Kind: entity.BattleLogKindBattle, BattleID: "battle_1"}).
+			AddRow(int64(43), entity.BattleLog{Kind: entity.BattleLogKindVoid, BattleID: "battle_1"}))
+
+	entries, err := r.ListBattleLog(ctx, "41", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.BattleLog{
+		{ID: "42", Kind: entity.BattleLogKindBattle, BattleID: "battle_1"},
+		{ID: "43", Kind: entity.BattleLogKindVoid, BattleID: "battle_1"},
+	}, entries)
+
+	_, err = r.ListBattleLog(ctx, "1700000000000-0", 2)
+	assert.Error(t, err)
+}
+
+func TestPostgresRepo_SwapReplay(t *testing.T) {
+	ctx := context.Background()
+	r, mock := newTestPostgresRepo(t)
+
+	// A battle was logged after the replay.
+	mock.ExpectBegin()
+	expectCurrentSeason(mock, "s1", seasonLockUpdate)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT last_id FROM battle_log_seq`)).
+		WillReturnRows(pgxmock.NewRows([]string{"last_id"}).AddRow(int64(43)))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, r.SwapReplay(ctx, "ns", "s1", "42"), repo.ErrConflict)
+
+	mock.ExpectBegin()
+	expectCurrentSeason(mock, "s2", seasonLockUpdate)
+	mock.ExpectRollback()
+	assert.ErrorIs(t, r.SwapReplay(ctx, "ns", "s1", "42"), repo.ErrConflict)
+
+	mock.ExpectBegin()
+	expectCurrentSeason(mock, "s1", seasonLockUpdate)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT last_id FROM battle_log_seq`)).
+		WillReturnRows(pgxmock.NewRows([]string{"last_id"}).AddRow(int64(42)))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM replay_user_elos`)).
+		WithArgs("ns", "s1").WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+	batch := mock.ExpectBatch()
+	batch.ExpectExec(regexp.QuoteMeta(`DELETE FROM user_elos WHERE season = $1`)).
+		WithArgs("s1").WillReturnResult(pgxmock.NewResult("DELETE", 2))
+	batch.ExpectExec(regexp.QuoteMeta(`INSERT INTO user_elos`)).
+		WithArgs("ns", "s1").WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	batch.ExpectExec(regexp.QuoteMeta(`DELETE FROM replay_user_elos`)).
+		WithArgs("ns", "s1").WillReturnResult(pgxmock.NewResult("DELETE", 2))
+	mock.ExpectCommit()
+	assert.NoError(t, r.SwapReplay(ctx, "ns", "s1", "42"))
+}
+
+func TestPostgresRepo_LeaveQueue(t *testing.T) {
+	ctx := context.Background()
+	r, mock := newTestPostgresRepo(t)
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM matchmaking_queue WHERE user_id = $1`)).
+		WithArgs("user_1").WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	assert.NoError(t, r.LeaveQueue(ctx, "user_1"))
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM matchmaking_queue WHERE user_id = $1`)).
+		WithArgs("user_1").WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	assert.ErrorIs(t, r.LeaveQueue(ctx, "user_1"), repo.ErrNotFound)
+}
+
+func TestPostgresRepo_ListQueuedUsers(t *testing.T) {
+	ctx := context.Background()
+	r, mock := newTestPostgresRepo(t)
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM matchmaking_queue WHERE joined_at < $1`)).
+		WithArgs(int64(50)).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	expectCurrentSeason(mock, "s1", "")
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT q.user_id, e.elo, q.joined_at FROM matchmaking_queue q
+JOIN user_elos e ON e.season = $1 AND e.user_id = q.user_id
+WHERE e.elo BETWEEN $2 AND $3`)).
+		WithArgs("s1", 950, 1050, entity.DefaultElo).
+		WillReturnRows(pgxmock.NewRows([]string{"user_id", "elo", "joined_at"}).
+			AddRow("user_1", 1010, int64(100)).
+			AddRow("user_2", entity.DefaultElo, int64(120)))
+
+	queued, err := r.ListQueuedUsers(ctx, 50, 950, 1050)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.QueuedUser{
+		{UserID: "user_1", Elo: 1010, JoinedAt: 100},
+		{UserID: "user_2", Elo: entity.DefaultElo, JoinedAt: 120},
+	}, queued)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
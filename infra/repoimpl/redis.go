@@ -1,43 +1,207 @@
 package repoimpl
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 
 	"github.com/me0den/example-service/domain/entity"
 	"github.com/me0den/example-service/domain/repo"
+	"github.com/me0den/example-service/infra/config"
+	xredis "github.com/me0den/example-service/x/redis"
 )
 
 const (
-	userEloKey = "user-elo"
+	userEloKeyPrefix     = "user-elo"
+	userEloRankKeyPrefix = "user-elo-rank"
+	// userEloInactivityKeyPrefix scores every user with the time their inactivity is counted from.
+	userEloInactivityKeyPrefix = "user-elo-inactivity"
+	userEloHistoryKeyPrefix    = "user-elo-history"
+	// userEloHistoryDataKeyPrefix holds the entries of the rating history of a user by their member in the history.
+	userEloHistoryDataKeyPrefix = "user-elo-history-data"
+	battleKeyPrefix             = "battle"
+	// battleLogKey is the stream of the accepted and voided battles.
+	battleLogKey = "battle-log"
+	// replayKeyPrefix namespaces the ratings recomputed from the battle log until they are swapped in.
+	replayKeyPrefix  = "replay"
+	currentSeasonKey = "season"
+	seasonsKey       = "seasons"
+	// matchmakingQueueKey scores every queued user with the time they joined the queue.
+	matchmakingQueueKey = "matchmaking-queue"
+
+	// defaultClusterHashTag keeps the keys on one slot in cluster mode when no hash tag is configured.
+	defaultClusterHashTag = "rating"
+
+	// rankIndexBatchSize is the number of elos indexed at once in the leaderboard by Init.
+	rankIndexBatchSize = 1000
+
+	defaultTxMaxRetries = 100
+	// defaultHistoryMaxLen is the number of the latest rating history entries kept per user.
+	defaultHistoryMaxLen = 1000
+	// txRetryBackoff is the upper bound of the random delay before retrying a conflicted transaction.
+	txRetryBackoff = 5 * time.Millisecond
 )
 
+// migrateUserEloScript replaces a hash field only if it still holds the value that was read,
+// so migrating a legacy entry never overwrites a concurrent update.
+var migrateUserEloScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], ARGV[1]) == ARGV[2] then
+	return redis.call("HSET", KEYS[1], ARGV[1], ARGV[3])
+end
+return 0
+`)
+
+// queuedInBandScript returns the users of the queue at KEYS[1] rated between ARGV[1] and ARGV[2] inclusive in the
+// leaderboard at KEYS[2], a user missing from the leaderboard has the default elo ARGV[3]. It walks the rating band
+// of the leaderboard when it is smaller than the queue and no unrated user is in it, and the queue otherwise.
+// The users are returned flat as user id, elo and joined at.
+var queuedInBandScript = redis.NewScript(`
+local minElo, maxElo, defaultElo = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+local queued = {}
+local unratedInBand = defaultElo >= minElo and defaultElo <= maxElo
+if not unratedInBand and redis.call("ZCOUNT", KEYS[2], minElo, maxElo) < redis.call("ZCARD", KEYS[1]) then
+	local band = redis.call("ZRANGEBYSCORE", KEYS[2], minElo, maxElo, "WITHSCORES")
+	for i = 1, #band, 2 do
+		local joinedAt = redis.call("ZSCORE", KEYS[1], band[i])
+		if joinedAt then
+			table.insert(queued, band[i])
+			table.insert(queued, band[i + 1])
+			table.insert(queued, joinedAt)
+		end
+	end
+	return queued
+end
+
+local members = redis.call("ZRANGE", KEYS[1], 0, -1, "WITHSCORES")
+for i = 1, #members, 2 do
+	local elo = redis.call("ZSCORE", KEYS[2], members[i]) or ARGV[3]
+	if tonumber(elo) >= minElo and tonumber(elo) <= maxElo then
+		table.insert(queued, members[i])
+		table.insert(queued, elo)
+		table.insert(queued, members[i + 1])
+	end
+end
+return queued
+`)
+
 type RedisRepo struct {
-	client *redis.Client
+	client       redis.UniversalClient
+	ttl          time.Duration
+	hashTag      string
+	txMaxRetries int
+	// historyMaxLen is the number of the latest rating history entries kept per user.
+	historyMaxLen int64
+	// season is the current season until the first rollover.
+	season string
 }
 
-// NewRedisDBRepo creates and returns a new instance of repo.RedisRepo.
-func NewRedisDBRepo(
-	client *redis.Client,
-) repo.RedisRepo {
+// NewRedisRepo creates and returns a new instance of RedisRepo.
+func NewRedisRepo(
+	client redis.UniversalClient,
+	cfg *config.Config,
+) *RedisRepo {
+	txMaxRetries := cfg.Redis.TxMaxRetries
+	if txMaxRetries <= 0 {
+		txMaxRetries = defaultTxMaxRetries
+	}
+
+	historyMaxLen := cfg.Redis.HistoryMaxLen
+	if historyMaxLen <= 0 {
+		historyMaxLen = defaultHistoryMaxLen
+	}
+
 	return &RedisRepo{
-		client: client,
+		client:        client,
+		ttl:           cfg.Redis.TTL,
+		hashTag:       redisHashTag(cfg),
+		txMaxRetries:  txMaxRetries,
+		historyMaxLen: historyMaxLen,
+		season:        cfg.Season.ID,
+	}
+}
+
+// Init indexes the elos of the current season stored before the leaderboard in it, so every rated user is ranked.
+func (r *RedisRepo) Init(ctx context.Context) error {
+	season, err := r.currentSeason(ctx, r.client)
+	if err != nil {
+		return err
+	}
+
+	eloKey := r.key(userEloKey(season))
+	rankKey := r.key(userEloRankKey(season))
+	pipe := r.client.Pipeline()
+	hlenCmd := pipe.HLen(ctx, eloKey)
+	zcardCmd := pipe.ZCard(ctx, rankKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	if zcardCmd.Val() >= hlenCmd.Val() {
+		return nil
+	}
+
+	var cursor uint64
+	for {
+		values, nextCursor, err := r.client.HScan(ctx, eloKey, cursor, "", rankIndexBatchSize).Result()
+		if err != nil {
+			return err
+		}
+
+		members := make([]redis.Z, 0, len(values)/2)
+		for idx := 0; idx+1 < len(values); idx += 2 {
+			userElo, err := decodeUserElo(values[idx], values[idx+1])
+			if err != nil {
+				return err
+			}
+
+			members = append(members, redis.Z{Score: float64(userElo.Elo), Member: userElo.UserID})
+		}
+
+		// An indexed user keeps the entry written with their latest elo.
+		if len(members) > 0 {
+			if err := r.client.ZAddNX(ctx, rankKey, members...).Err(); err != nil {
+				return err
+			}
+		}
+
+		if nextCursor == 0 {
+			return nil
+		}
+		cursor = nextCursor
 	}
 }
 
 func (r *RedisRepo) GetUserElo(ctx context.Context, userID string) (*entity.UserElo, error) {
-	data, err := r.client.HGet(ctx, userEloKey, userID).Result()
+	season, err := r.currentSeason(ctx, r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := r.client.HGet(ctx, r.key(userEloKey(season)), userID).Result()
 	if err != nil && !errors.Is(err, redis.Nil) {
 		return nil, err
 	}
 
-	// Default elo: 1000
-	userElo := &entity.UserElo{UserID: userID, Elo: 1000}
-	if !errors.Is(err, redis.Nil) {
-		if err := json.Unmarshal([]byte(data), userElo); err != nil {
+	if errors.Is(err, redis.Nil) {
+		return entity.NewUserDefaultElo(userID), nil
+	}
+
+	userElo, err := decodeUserElo(userID, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if userElo.IsLegacy() {
+		if err := r.migrateUserElo(ctx, season, data, userElo); err != nil {
 			return nil, err
 		}
 	}
@@ -45,20 +209,1043 @@ func (r *RedisRepo) GetUserElo(ctx context.Context, userID string) (*entity.User
 	return userElo, nil
 }
 
+// migrateUserElo rewrites a legacy user elo entry with default rating deviation and volatility.
+func (r *RedisRepo) migrateUserElo(ctx context.Context, season, data string, userElo *entity.UserElo) error {
+	userElo.Migrate()
+	eloData, err := json.Marshal(userElo)
+	if err != nil {
+		return err
+	}
+
+	return migrateUserEloScript.Run(ctx, r.client, []string{r.key(userEloKey(season))}, userElo.UserID, data, eloData).Err()
+}
+
+func (r *RedisRepo) ListUserElos(ctx context.Context, userIDs []string) ([]*entity.UserElo, error) {
+	season, err := r.currentSeason(ctx, r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.listUserElos(ctx, r.client, season, userIDs)
+}
+
 func (r *RedisRepo) BatchUpdateElo(ctx context.Context, elos []*entity.UserElo) error {
+	season, err := r.currentSeason(ctx, r.client)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.client.Pipeline()
+	if err := r.setUserElos(ctx, pipe, season, elos); err != nil {
+		return err
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RedisRepo) GetBattle(ctx context.Context, battleID string) (*entity.Battle, error) {
+	return r.getBattle(ctx, r.client, battleID)
+}
+
+func (r *RedisRepo) ApplyBattle(
+	ctx context.Context,
+	battleID string,
+	userIDs []string,
+	fn repo.BattleFunc,
+) (*entity.Battle, error) {
+	var battle *entity.Battle
+	applyBattle := func() error {
+		storedBattle, err := r.getBattle(ctx, r.client, battleID)
+		if err == nil {
+			battle = storedBattle
+			return nil
+		}
+		if !errors.Is(err, repo.ErrNotFound) {
+			return err
+		}
+
+		season, seasonData, err := r.readSeason(ctx, r.client)
+		if err != nil {
+			return err
+		}
+
+		userElos, userEloData, err := r.readUserElos(ctx, r.client, season, userIDs)
+		if err != nil {
+			return err
+		}
+
+		newBattle, newUserElos, err := fn(userElos)
+		if err != nil {
+			return err
+		}
+
+		// Only the season, the battle and the elos of its users are guarded, so battles of other users never conflict.
+		w := &guardedWrite{}
+		w.guardValue(r.key(currentSeasonKey), seasonData)
+		w.guardValue(r.key(battleKey(battleID)), "")
+		r.guardUserElos(w, season, userIDs, userEloData)
+
+		newBattle.Season = season
+		if err := r.setUserElos(ctx, w, season, newUserElos); err != nil {
+			return err
+		}
+
+		history := newBattle.History()
+		for _, entry := range history {
+			w.ZAdd(ctx, r.key(userEloInactivityKey(season)), redis.Z{Score: float64(entry.CreatedAt), Member: entry.UserID})
+		}
+
+		if err := r.addRatingHistory(ctx, w, history); err != nil {
+			return err
+		}
+
+		if err := r.addBattleLog(ctx, w, newBattle.Log()); err != nil {
+			return err
+		}
+
+		if err := r.setBattle(ctx, w, newBattle, r.ttl); err != nil {
+			return err
+		}
+
+		if err := w.exec(ctx, r.client); err != nil {
+			return err
+		}
+
+		battle = newBattle
+		return nil
+	}
+
+	if err := r.retry(ctx, applyBattle); err != nil {
+		return nil, err
+	}
+
+	return battle, nil
+}
+
+func (r *RedisRepo) VoidBattle(ctx context.Context, battleID string, voidedAt int64) (*entity.Battle, error) {
+	var battle *entity.Battle
+	voidBattle := func() error {
+		storedBattle, battleData, err := r.readBattle(ctx, r.client, battleID)
+		if err != nil {
+			return err
+		}
+		if storedBattle.IsVoided() {
+			return repo.ErrVoided
+		}
+
+		currentSeason, seasonData, err := r.readSeason(ctx, r.client)
+		if err != nil {
+			return err
+		}
+
+		// A battle recorded before seasons were stored on it belongs to the current season.
+		season := storedBattle.Season
+		if season == "" {
+			season = currentSeason
+		}
+		if season != currentSeason {
+			return repo.ErrSeasonEnded
+		}
+
+		userIDs := make([]string, 0, len(storedBattle.Rewards))
+		for _, reward := range storedBattle.Rewards {
+			userIDs = append(userIDs, reward.UserID)
+		}
+
+		userElos, userEloData, err := r.readUserElos(ctx, r.client, season, userIDs)
+		if err != nil {
+			return err
+		}
+
+		w := &guardedWrite{}
+		w.guardValue(r.key(currentSeasonKey), seasonData)
+		w.guardValue(r.key(battleKey(battleID)), battleData)
+		r.guardUserElos(w, season, userIDs, userEloData)
+
+		storedBattle.Season = season
+		newUserElos := storedBattle.Void(userElos, voidedAt)
+		if err := r.setUserElos(ctx, w, season, newUserElos); err != nil {
+			return err
+		}
+
+		if err := r.addRatingHistory(ctx, w, storedBattle.ReversalHistory()); err != nil {
+			return err
+		}
+
+		if err := r.addBattleLog(ctx, w, storedBattle.VoidLog()); err != nil {
+			return err
+		}
+
+		// The voided battle stays within the retention window of the processed battle.
+		if err := r.setBattle(ctx, w, storedBattle, redis.KeepTTL); err != nil {
+			return err
+		}
+
+		if err := w.exec(ctx, r.client); err != nil {
+			return err
+		}
+
+		battle = storedBattle
+		return nil
+	}
+
+	if err := r.retry(ctx, voidBattle); err != nil {
+		return nil, err
+	}
+
+	return battle, nil
+}
+
+func (r *RedisRepo) ListUserRanks(ctx context.Context, offset, limit int64) ([]*entity.UserRank, error) {
+	season, err := r.currentSeason(ctx, r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.ListSeasonUserRanks(ctx, season, offset, limit)
+}
+
+func (r *RedisRepo) ListSeasonUserRanks(ctx context.Context, seasonID string, offset, limit int64) ([]*entity.UserRank, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	members, err := r.client.ZRevRangeWithScores(ctx, r.key(userEloRankKey(seasonID)), offset, offset+limit-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	userRanks := make([]*entity.UserRank, 0, len(members))
+	for idx, member := range members {
+		userID, _ := member.Member.(string)
+		userRanks = append(userRanks, &entity.UserRank{
+			UserID: userID,
+			Elo:    int(member.Score),
+			Rank:   offset + int64(idx) + 1,
+		})
+	}
+
+	return userRanks, nil
+}
+
+func (r *RedisRepo) GetUserRank(ctx context.Context, userID string) (*entity.UserRank, error) {
+	season, err := r.currentSeason(ctx, r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetSeasonUserRank(ctx, season, userID)
+}
+
+func (r *RedisRepo) GetSeasonUserRank(ctx context.Context, seasonID, userID string) (*entity.UserRank, error) {
 	pipe := r.client.Pipeline()
+	rankCmd := pipe.ZRevRank(ctx, r.key(userEloRankKey(seasonID)), userID)
+	scoreCmd := pipe.ZScore(ctx, r.key(userEloRankKey(seasonID)), userID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, repo.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &entity.UserRank{
+		UserID: userID,
+		Elo:    int(scoreCmd.Val()),
+		Rank:   rankCmd.Val() + 1,
+	}, nil
+}
+
+func (r *RedisRepo) ListRatingHistory(
+	ctx context.Context,
+	userID string,
+	from, to int64,
+	offset, limit int64,
+) ([]*entity.RatingHistory, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	scoreRange := &redis.ZRangeBy{
+		Min:    "-inf",
+		Max:    "+inf",
+		Offset: offset,
+		Count:  limit,
+	}
+	if from > 0 {
+		scoreRange.Min = strconv.FormatInt(from, 10)
+	}
+	if to > 0 {
+		scoreRange.Max = strconv.FormatInt(to, 10)
+	}
+
+	members, err := r.client.ZRevRangeByScore(ctx, r.key(userEloHistoryKey(userID)), scoreRange).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	values, err := r.client.HMGet(ctx, r.key(userEloHistoryDataKey(userID)), members...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*entity.RatingHistory, 0, len(members))
+	for idx, member := range members {
+		data, ok := values[idx].(string)
+		if !ok {
+			// An entry recorded before the entries were stored apart is its own member,
+			// any other member was trimmed since it was listed.
+			if !strings.HasPrefix(member, "{") {
+				continue
+			}
+			data = member
+		}
+
+		entry := &entity.RatingHistory{}
+		if err := json.Unmarshal([]byte(data), entry); err != nil {
+			return nil, err
+		}
+
+		history = append(history, entry)
+	}
+
+	return history, nil
+}
+
+func (r *RedisRepo) ListInactiveUserIDs(ctx context.Context, before, offset, limit int64) ([]string, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	season, err := r.currentSeason(ctx, r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.client.ZRangeByScore(ctx, r.key(userEloInactivityKey(season)), &redis.ZRangeBy{
+		Min:    "-inf",
+		Max:    strconv.FormatInt(before, 10),
+		Offset: offset,
+		Count:  limit,
+	}).Result()
+}
+
+func (r *RedisRepo) ApplyDecay(
+	ctx context.Context,
+	userID string,
+	before, period, decayedAt int64,
+	fn repo.DecayFunc,
+) (*entity.RatingHistory, error) {
+	var entry *entity.RatingHistory
+	applyDecay := func() error {
+		season, seasonData, err := r.readSeason(ctx, r.client)
+		if err != nil {
+			return err
+		}
+
+		inactiveSince, err := r.client.ZScore(ctx, r.key(userEloInactivityKey(season)), userID).Result()
+		if errors.Is(err, redis.Nil) {
+			return repo.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// A battle since the user was listed makes the user active again.
+		if int64(inactiveSince) > before {
+			return repo.ErrNotFound
+		}
+
+		userElos, userEloData, err := r.readUserElos(ctx, r.client, season, []string{userID})
+		if err != nil {
+			return err
+		}
+
+		w := &guardedWrite{}
+		w.guardValue(r.key(currentSeasonKey), seasonData)
+		w.guardScore(r.key(userEloInactivityKey(season)), userID, inactiveSince)
+		r.guardUserElos(w, season, []string{userID}, userEloData)
+
+		newUserElo := fn(userElos[0])
+		entry = nil
+		if newUserElo == nil {
+			return nil
+		}
+
+		entry = &entity.RatingHistory{
+			Season:    season,
+			UserID:    userID,
+			Reason:    entity.HistoryReasonDecay,
+			OldElo:    userElos[0].Elo,
+			NewElo:    newUserElo.Elo,
+			CreatedAt: decayedAt,
+		}
+		w.ZAdd(ctx, r.key(userEloInactivityKey(season)), redis.Z{Score: inactiveSince + float64(period), Member: userID})
+		if err := r.setUserElos(ctx, w, season, []*entity.UserElo{newUserElo}); err != nil {
+			return err
+		}
+
+		// A decay is identified by the inactivity it consumes, a user decays several times in one pass.
+		member := fmt.Sprintf("%s:%s:%s", entity.HistoryReasonDecay, season, formatScore(inactiveSince))
+		if err := r.addRatingHistoryEntry(ctx, w, member, entry); err != nil {
+			return err
+		}
+
+		return w.exec(ctx, r.client)
+	}
+
+	if err := r.retry(ctx, applyDecay); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *RedisRepo) GetCurrentSeason(ctx context.Context) (*entity.Season, error) {
+	season, err := r.currentSeason(ctx, r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Season{ID: season}, nil
+}
+
+func (r *RedisRepo) GetSeason(ctx context.Context, seasonID string) (*entity.Season, error) {
+	season, err := r.currentSeason(ctx, r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	if seasonID == season {
+		return &entity.Season{ID: season}, nil
+	}
+
+	endedAt, err := r.client.ZScore(ctx, r.key(seasonsKey), seasonID).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, repo.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Season{ID: seasonID, EndedAt: int64(endedAt)}, nil
+}
+
+func (r *RedisRepo) ListSeasons(ctx context.Context) ([]*entity.Season, error) {
+	members, err := r.client.ZRevRangeWithScores(ctx, r.key(seasonsKey), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	seasons := make([]*entity.Season, 0, len(members))
+	for _, member := range members {
+		seasonID, _ := member.Member.(string)
+		seasons = append(seasons, &entity.Season{
+			ID:      seasonID,
+			EndedAt: int64(member.Score),
+		})
+	}
+
+	return seasons, nil
+}
+
+// RolloverSeason carries the elos over in chunks guarded only by the current season, so battles during the rollover
+// of a large season never make it retry. A battle applied to a user already carried over before the season switches
+// counts in the final standings of the ended season only.
+func (r *RedisRepo) RolloverSeason(
+	ctx context.Context,
+	seasonID string,
+	endedAt int64,
+	fn repo.SeasonResetFunc,
+) (*entity.Season, error) {
+	var endedSeason *entity.Season
+	rollover := func() error {
+		season, seasonData, err := r.readSeason(ctx, r.client)
+		if err != nil {
+			return err
+		}
+
+		if season == "" {
+			return repo.ErrUnnamedSeason
+		}
+
+		if seasonID == season {
+			return repo.ErrAlreadyExists
+		}
+
+		err = r.client.ZScore(ctx, r.key(seasonsKey), seasonID).Err()
+		if err == nil {
+			return repo.ErrAlreadyExists
+		}
+		if !errors.Is(err, redis.Nil) {
+			return err
+		}
+
+		// The elos left by an interrupted rollover to seasonID are dropped before carrying over the current ones.
+		w := &guardedWrite{}
+		w.guardValue(r.key(currentSeasonKey), seasonData)
+		w.Del(ctx, r.key(userEloKey(seasonID)))
+		w.Del(ctx, r.key(userEloRankKey(seasonID)))
+		if err := w.exec(ctx, r.client); err != nil {
+			return err
+		}
+
+		// The elos and the leaderboard of the ended season stay untouched as its final standings.
+		var cursor uint64
+		for {
+			values, nextCursor, err := r.client.HScan(ctx, r.key(userEloKey(season)), cursor, "", rankIndexBatchSize).Result()
+			if err != nil {
+				return err
+			}
+
+			newUserElos := make([]*entity.UserElo, 0, len(values)/2)
+			for idx := 0; idx+1 < len(values); idx += 2 {
+				userElo, err := decodeUserElo(values[idx], values[idx+1])
+				if err != nil {
+					return err
+				}
+
+				userElo.Migrate()
+				newUserElos = append(newUserElos, fn(userElo))
+			}
+
+			w := &guardedWrite{}
+			w.guardValue(r.key(currentSeasonKey), seasonData)
+			if err := r.setUserElos(ctx, w, seasonID, newUserElos); err != nil {
+				return err
+			}
+
+			if err := w.exec(ctx, r.client); err != nil {
+				return err
+			}
+
+			if nextCursor == 0 {
+				break
+			}
+			cursor = nextCursor
+		}
+
+		txf := func(tx *redis.Tx) error {
+			_, currentSeasonData, err := r.readSeason(ctx, tx)
+			if err != nil {
+				return err
+			}
+
+			if currentSeasonData != seasonData {
+				return redis.TxFailedErr
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				// The users keep decaying from their last battle rather than from the rollover.
+				pipe.ZUnionStore(ctx, r.key(userEloInactivityKey(seasonID)), &redis.ZStore{
+					Keys: []string{r.key(userEloInactivityKey(season))},
+				})
+
+				pipe.ZAdd(ctx, r.key(seasonsKey), redis.Z{Score: float64(endedAt), Member: season})
+				pipe.Set(ctx, r.key(currentSeasonKey), seasonID, 0)
+				return nil
+			})
+			return err
+		}
+
+		// A rollover racing with another one starts over and finds the season ended or seasonID started.
+		if err := r.client.Watch(ctx, txf, r.key(currentSeasonKey)); err != nil {
+			return err
+		}
+
+		endedSeason = &entity.Season{ID: season, EndedAt: endedAt}
+		return nil
+	}
+
+	if err := r.retry(ctx, rollover); err != nil {
+		return nil, err
+	}
+
+	return endedSeason, nil
+}
+
+func (r *RedisRepo) ListBattleLog(ctx context.Context, after string, limit int64) ([]*entity.BattleLog, error) {
+	start := "-"
+	if after != "" {
+		start = "(" + after
+	}
+
+	messages, err := r.client.XRangeN(ctx, r.key(battleLogKey), start, "+", limit).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*entity.BattleLog, 0, len(messages))
+	for _, message := range messages {
+		data, _ := message.Values["data"].(string)
+		entry := &entity.BattleLog{}
+		if err := json.Unmarshal([]byte(data), entry); err != nil {
+			return nil, err
+		}
+
+		entry.ID = message.ID
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (r *RedisRepo) StoreReplay(ctx context.Context, namespace, season string, elos []*entity.UserElo) error {
+	eloKey := r.key(replayKey(namespace, userEloKey(season)))
+	rankKey := r.key(replayKey(namespace, userEloRankKey(season)))
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, eloKey, rankKey)
+		return r.putUserElos(ctx, pipe, eloKey, rankKey, elos)
+	})
+
+	return err
+}
+
+func (r *RedisRepo) SwapReplay(ctx context.Context, namespace, season, lastLogID string) error {
+	eloKey := r.key(replayKey(namespace, userEloKey(season)))
+	rankKey := r.key(replayKey(namespace, userEloRankKey(season)))
+	txf := func(tx *redis.Tx) error {
+		currentSeason, err := r.currentSeason(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		if currentSeason != season {
+			return repo.ErrConflict
+		}
+
+		// Battles accepted or voided after the replay would be lost by the swap.
+		messages, err := tx.XRevRangeN(ctx, r.key(battleLogKey), "+", "-", 1).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(messages) > 0 && messages[0].ID != lastLogID {
+			return repo.ErrConflict
+		}
+
+		exists, err := tx.Exists(ctx, eloKey).Result()
+		if err != nil {
+			return err
+		}
+
+		if exists == 0 {
+			return repo.ErrNotFound
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Rename(ctx, eloKey, r.key(userEloKey(season)))
+			pipe.Rename(ctx, rankKey, r.key(userEloRankKey(season)))
+			return nil
+		})
+		return err
+	}
+
+	// A failed watch means the log or the season moved on, the replay is outdated and is not retried.
+	err := r.client.Watch(ctx, txf, r.key(currentSeasonKey), r.key(battleLogKey))
+	if errors.Is(err, redis.TxFailedErr) {
+		return repo.ErrConflict
+	}
+
+	return err
+}
+
+func (r *RedisRepo) JoinQueue(ctx context.Context, userID string, joinedAt, since int64) (*entity.QueuedUser, error) {
+	if err := r.dropQueuedBefore(ctx, since); err != nil {
+		return nil, err
+	}
+
+	if err := r.client.ZAddNX(ctx, r.key(matchmakingQueueKey), redis.Z{Score: float64(joinedAt), Member: userID}).Err(); err != nil {
+		return nil, err
+	}
+
+	queuedAt, err := r.client.ZScore(ctx, r.key(matchmakingQueueKey), userID).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	queued, err := r.withElos(ctx, []redis.Z{{Score: queuedAt, Member: userID}})
+	if err != nil {
+		return nil, err
+	}
+
+	return queued[0], nil
+}
+
+func (r *RedisRepo) LeaveQueue(ctx context.Context, userID string) error {
+	removed, err := r.client.ZRem(ctx, r.key(matchmakingQueueKey), userID).Result()
+	if err != nil {
+		return err
+	}
+
+	if removed == 0 {
+		return repo.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *RedisRepo) GetQueuedUser(ctx context.Context, userID string, since int64) (*entity.QueuedUser, error) {
+	joinedAt, err := r.client.ZScore(ctx, r.key(matchmakingQueueKey), userID).Result()
+	if errors.Is(err, redis.Nil) || (err == nil && int64(joinedAt) < since) {
+		return nil, repo.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	queued, err := r.withElos(ctx, []redis.Z{{Score: joinedAt, Member: userID}})
+	if err != nil {
+		return nil, err
+	}
+
+	return queued[0], nil
+}
+
+// ListQueuedUsers intersects the queue with the rating band of the leaderboard, the elos of the users out of the
+// band are never read.
+func (r *RedisRepo) ListQueuedUsers(ctx context.Context, since int64, minElo, maxElo int) ([]*entity.QueuedUser, error) {
+	if err := r.dropQueuedBefore(ctx, since); err != nil {
+		return nil, err
+	}
+
+	season, err := r.currentSeason(ctx, r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{r.key(matchmakingQueueKey), r.key(userEloRankKey(season))}
+	values, err := queuedInBandScript.Run(ctx, r.client, keys, minElo, maxElo, entity.DefaultElo).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	queued := make([]*entity.QueuedUser, 0, len(values)/3)
+	for idx := 0; idx+2 < len(values); idx += 3 {
+		elo, err := strconv.ParseFloat(values[idx+1], 64)
+		if err != nil {
+			return nil, err
+		}
+
+		joinedAt, err := strconv.ParseFloat(values[idx+2], 64)
+		if err != nil {
+			return nil, err
+		}
+
+		queued = append(queued, &entity.QueuedUser{
+			UserID:   values[idx],
+			Elo:      int(elo),
+			JoinedAt: int64(joinedAt),
+		})
+	}
+
+	slices.SortFunc(queued, func(a, b *entity.QueuedUser) int {
+		return cmp.Or(cmp.Compare(a.JoinedAt, b.JoinedAt), cmp.Compare(a.UserID, b.UserID))
+	})
+
+	return queued, nil
+}
+
+// dropQueuedBefore removes the users queued before the unix timestamp since from the matchmaking queue.
+func (r *RedisRepo) dropQueuedBefore(ctx context.Context, since int64) error {
+	return r.client.ZRemRangeByScore(ctx, r.key(matchmakingQueueKey), "-inf", "("+strconv.FormatInt(since, 10)).Err()
+}
+
+// withElos returns the queued users of the queue members with their elo in the current season.
+func (r *RedisRepo) withElos(ctx context.Context, members []redis.Z) ([]*entity.QueuedUser, error) {
+	season, err := r.currentSeason(ctx, r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		userID, _ := member.Member.(string)
+		userIDs = append(userIDs, userID)
+	}
+
+	userElos, err := r.listUserElos(ctx, r.client, season, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	queued := make([]*entity.QueuedUser, 0, len(members))
+	for idx, member := range members {
+		queued = append(queued, &entity.QueuedUser{
+			UserID:   userIDs[idx],
+			Elo:      userElos[idx].Elo,
+			JoinedAt: int64(member.Score),
+		})
+	}
+
+	return queued, nil
+}
+
+// currentSeason returns the id of the current season, it is the configured one until the first rollover.
+func (r *RedisRepo) currentSeason(ctx context.Context, cmd redis.Cmdable) (string, error) {
+	season, _, err := r.readSeason(ctx, cmd)
+	return season, err
+}
+
+// readSeason returns the id of the current season and the stored value it is read from, which is empty until the first rollover.
+func (r *RedisRepo) readSeason(ctx context.Context, cmd redis.Cmdable) (string, string, error) {
+	season, err := cmd.Get(ctx, r.key(currentSeasonKey)).Result()
+	if errors.Is(err, redis.Nil) {
+		return r.season, "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return season, season, nil
+}
+
+// retry runs fn and retries it while it fails with redis.TxFailedErr, it returns repo.ErrConflict once the retries are exhausted.
+func (r *RedisRepo) retry(ctx context.Context, fn func() error) error {
+	for range r.txMaxRetries {
+		err := fn()
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(rand.N(txRetryBackoff)):
+		}
+	}
+
+	return repo.ErrConflict
+}
+
+// listUserElos returns the elos of userIDs in a season in the same order, unknown users get the default elo.
+func (r *RedisRepo) listUserElos(ctx context.Context, cmd redis.Cmdable, season string, userIDs []string) ([]*entity.UserElo, error) {
+	userElos, _, err := r.readUserElos(ctx, cmd, season, userIDs)
+	return userElos, err
+}
+
+// readUserElos returns the elos of userIDs in a season as listUserElos does and the stored values they are read from,
+// which are empty for unknown users.
+func (r *RedisRepo) readUserElos(
+	ctx context.Context,
+	cmd redis.Cmdable,
+	season string,
+	userIDs []string,
+) ([]*entity.UserElo, []string, error) {
+	if len(userIDs) == 0 {
+		return nil, nil, nil
+	}
+
+	values, err := cmd.HMGet(ctx, r.key(userEloKey(season)), userIDs...).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userElos := make([]*entity.UserElo, 0, len(userIDs))
+	userEloData := make([]string, 0, len(userIDs))
+	for idx, value := range values {
+		data, ok := value.(string)
+		userEloData = append(userEloData, data)
+		if !ok {
+			userElos = append(userElos, entity.NewUserDefaultElo(userIDs[idx]))
+			continue
+		}
+
+		userElo, err := decodeUserElo(userIDs[idx], data)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		userElo.Migrate()
+		userElos = append(userElos, userElo)
+	}
+
+	return userElos, userEloData, nil
+}
+
+// guardUserElos expects the elos of userIDs in a season to still hold the stored values they are read from.
+func (r *RedisRepo) guardUserElos(w *guardedWrite, season string, userIDs, userEloData []string) {
+	for idx, userID := range userIDs {
+		w.guardField(r.key(userEloKey(season)), userID, userEloData[idx])
+	}
+}
+
+// setUserElos queues the update of elos in a season and their leaderboard index on pipe.
+func (r *RedisRepo) setUserElos(ctx context.Context, pipe redisWriter, season string, elos []*entity.UserElo) error {
+	return r.putUserElos(ctx, pipe, r.key(userEloKey(season)), r.key(userEloRankKey(season)), elos)
+}
+
+// putUserElos queues the update of elos in eloKey and their leaderboard index in rankKey on pipe.
+func (r *RedisRepo) putUserElos(ctx context.Context, pipe redisWriter, eloKey, rankKey string, elos []*entity.UserElo) error {
 	for _, elo := range elos {
 		eloData, err := json.Marshal(elo)
 		if err != nil {
 			return err
 		}
 
-		pipe.HSet(ctx, userEloKey, elo.UserID, eloData)
+		pipe.HSet(ctx, eloKey, elo.UserID, eloData)
+		pipe.ZAdd(ctx, rankKey, redis.Z{Score: float64(elo.Elo), Member: elo.UserID})
 	}
 
-	if _, err := pipe.Exec(ctx); err != nil {
+	return nil
+}
+
+// addRatingHistory queues the history entries of a battle on w, an entry is identified by its reason and its battle.
+func (r *RedisRepo) addRatingHistory(ctx context.Context, w *guardedWrite, history []*entity.RatingHistory) error {
+	for _, entry := range history {
+		if err := r.addRatingHistoryEntry(ctx, w, entry.Reason+":"+entry.BattleID, entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addRatingHistoryEntry queues the history entry identified by member on w, every user history is ordered by
+// creation time and trimmed to the latest historyMaxLen entries.
+func (r *RedisRepo) addRatingHistoryEntry(ctx context.Context, w *guardedWrite, member string, entry *entity.RatingHistory) error {
+	entryData, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	historyKey := r.key(userEloHistoryKey(entry.UserID))
+	dataKey := r.key(userEloHistoryDataKey(entry.UserID))
+	w.HSet(ctx, dataKey, member, entryData)
+	w.ZAdd(ctx, historyKey, redis.Z{Score: float64(entry.CreatedAt), Member: member})
+	w.ZTrim(historyKey, dataKey, r.historyMaxLen)
+	return nil
+}
+
+// addBattleLog queues the append of entry to the battle log on pipe.
+func (r *RedisRepo) addBattleLog(ctx context.Context, pipe redisWriter, entry *entity.BattleLog) error {
+	entryData, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	pipe.XAdd(ctx, &redis.XAddArgs{Stream: r.key(battleLogKey), Values: map[string]any{"data": entryData}})
+	return nil
+}
+
+func (r *RedisRepo) getBattle(ctx context.Context, cmd redis.Cmdable, battleID string) (*entity.Battle, error) {
+	battle, _, err := r.readBattle(ctx, cmd, battleID)
+	return battle, err
+}
+
+// readBattle returns a processed battle and the stored value it is read from.
+func (r *RedisRepo) readBattle(ctx context.Context, cmd redis.Cmdable, battleID string) (*entity.Battle, string, error) {
+	data, err := cmd.Get(ctx, r.key(battleKey(battleID))).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, "", repo.ErrNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	battle := &entity.Battle{}
+	if err := json.Unmarshal([]byte(data), battle); err != nil {
+		return nil, "", err
+	}
+
+	return battle, data, nil
+}
+
+// setBattle queues the record of a processed battle on pipe, it expires after ttl or keeps its expiration with redis.KeepTTL.
+func (r *RedisRepo) setBattle(ctx context.Context, pipe redisWriter, battle *entity.Battle, ttl time.Duration) error {
+	battleData, err := json.Marshal(battle)
+	if err != nil {
 		return err
 	}
 
+	pipe.Set(ctx, r.key(battleKey(battle.ID)), battleData, ttl)
 	return nil
 }
+
+// decodeUserElo decodes a stored user elo, an entry stored before games were counted has unknown games played.
+func decodeUserElo(userID, data string) (*entity.UserElo, error) {
+	userElo := &entity.UserElo{UserID: userID, GamesPlayed: entity.UnknownGamesPlayed}
+	if err := json.Unmarshal([]byte(data), userElo); err != nil {
+		return nil, err
+	}
+
+	return userElo, nil
+}
+
+// redisHashTag returns the configured hash tag of the keys, it defaults to defaultClusterHashTag in cluster mode
+// because the keys of a transaction or of a guarded write must be on one slot. A battle writes keys of its users,
+// of the season and of the battle log at once, so a single tag is shared by every key rather than one per season
+// or per user.
+func redisHashTag(cfg *config.Config) string {
+	if cfg.Redis.HashTag == "" && cfg.Redis.Mode == xredis.ModeCluster {
+		return defaultClusterHashTag
+	}
+
+	return cfg.Redis.HashTag
+}
+
+// hashTagKey prefixes key with hashTag in braces, the empty hash tag keeps the key stored before hash tags.
+func hashTagKey(hashTag, key string) string {
+	if hashTag == "" {
+		return key
+	}
+
+	return fmt.Sprintf("{%s}:%s", hashTag, key)
+}
+
+// key returns key under the hash tag of the repo.
+func (r *RedisRepo) key(key string) string {
+	return hashTagKey(r.hashTag, key)
+}
+
+// userEloKey returns the key of the elos of a season.
+func userEloKey(season string) string {
+	return seasonKey(userEloKeyPrefix, season)
+}
+
+// userEloRankKey returns the key of the leaderboard of a season.
+func userEloRankKey(season string) string {
+	return seasonKey(userEloRankKeyPrefix, season)
+}
+
+// userEloInactivityKey returns the key of the inactivity of the users of a season.
+func userEloInactivityKey(season string) string {
+	return seasonKey(userEloInactivityKeyPrefix, season)
+}
+
+// seasonKey returns the key of a season with prefix, the empty season keeps the key stored before seasons.
+func seasonKey(prefix, season string) string {
+	if season == "" {
+		return prefix
+	}
+
+	return fmt.Sprintf("%s:%s", prefix, season)
+}
+
+// userEloHistoryKey returns the key of the rating history of a user.
+func userEloHistoryKey(userID string) string {
+	return fmt.Sprintf("%s:%s", userEloHistoryKeyPrefix, userID)
+}
+
+// userEloHistoryDataKey returns the key of the entries of the rating history of a user.
+func userEloHistoryDataKey(userID string) string {
+	return fmt.Sprintf("%s:%s", userEloHistoryDataKeyPrefix, userID)
+}
+
+// battleKey returns the key of a processed battle.
+func battleKey(battleID string) string {
+	return fmt.Sprintf("%s:%s", battleKeyPrefix, battleID)
+}
+
+// replayKey returns key in the replay namespace.
+func replayKey(namespace, key string) string {
+	return fmt.Sprintf("%s:%s:%s", replayKeyPrefix, namespace, key)
+}
@@ -0,0 +1,217 @@
+package repoimpl
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	guardValue = "get"
+	guardField = "hget"
+	guardScore = "zscore"
+)
+
+// guardedWriteScript applies the queued writes only if every guarded value still holds what was read.
+//
+// ARGV[1] is the number of guards. A guard is its kind, the index of its key in KEYS, a field or member
+// and the expected value, the empty value expects none. The guards are followed by the writes, a write
+// is the number of its command args, the command, the index of its key in KEYS and the other args. The ZTRIM
+// command trims a sorted set and the hash of its payloads, its args are the index of the hash in KEYS and the length kept.
+var guardedWriteScript = redis.NewScript(`
+local function read(kind, key, field)
+	if kind == "get" then
+		return redis.call("GET", key)
+	elseif kind == "hget" then
+		return redis.call("HGET", key, field)
+	end
+	return redis.call("ZSCORE", key, field)
+end
+
+local i = 2
+for _ = 1, tonumber(ARGV[1]) do
+	local kind, expected = ARGV[i], ARGV[i + 3]
+	local value = read(kind, KEYS[tonumber(ARGV[i + 1])], ARGV[i + 2])
+	if kind == "zscore" then
+		if tonumber(value) ~= tonumber(expected) then
+			return 0
+		end
+	elseif (value or "") ~= expected then
+		return 0
+	end
+	i = i + 4
+end
+
+-- trim keeps the maxLen highest members of the sorted set at key and removes the fields of the others from the hash at dataKey.
+local function trim(key, dataKey, maxLen)
+	local removed = redis.call("ZRANGE", key, 0, -maxLen - 1)
+	if #removed == 0 then
+		return
+	end
+
+	for _, member in ipairs(removed) do
+		redis.call("HDEL", dataKey, member)
+	end
+	redis.call("ZREMRANGEBYRANK", key, 0, -maxLen - 1)
+end
+
+while i <= #ARGV do
+	local argc = tonumber(ARGV[i])
+	local args = {ARGV[i + 1], KEYS[tonumber(ARGV[i + 2])]}
+	for j = 3, argc do
+		args[j] = ARGV[i + j]
+	end
+
+	if args[1] == "ZTRIM" then
+		trim(args[2], KEYS[tonumber(args[3])], tonumber(args[4]))
+	else
+		redis.call(unpack(args))
+	end
+	i = i + argc + 1
+end
+return 1
+`)
+
+// redisWriter queues the writes of the repo, it is implemented by redis.Pipeliner and guardedWrite.
+type redisWriter interface {
+	HSet(ctx context.Context, key string, values ...any) *redis.IntCmd
+	ZAdd(ctx context.Context, key string, members ...redis.Z) *redis.IntCmd
+	XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd
+	Set(ctx context.Context, key string, value any, expiration time.Duration) *redis.StatusCmd
+}
+
+// guardedWrite queues writes that are applied atomically by guardedWriteScript only if the guarded
+// values are unchanged. Unlike a WATCH on a whole hash, it conflicts only with writes of the same fields.
+type guardedWrite struct {
+	keys    []string
+	guards  []any
+	nguards int
+	writes  []any
+}
+
+// guardValue expects key to hold value, the empty value expects the key to be missing.
+func (w *guardedWrite) guardValue(key, value string) {
+	w.guard(guardValue, key, "", value)
+}
+
+// guardField expects the field of the hash at key to hold value, the empty value expects the field to be missing.
+func (w *guardedWrite) guardField(key, field, value string) {
+	w.guard(guardField, key, field, value)
+}
+
+// guardScore expects member of the sorted set at key to have score.
+func (w *guardedWrite) guardScore(key, member string, score float64) {
+	w.guard(guardScore, key, member, formatScore(score))
+}
+
+func (w *guardedWrite) guard(kind, key, field, value string) {
+	w.guards = append(w.guards, kind, w.keyIndex(key), field, value)
+	w.nguards++
+}
+
+func (w *guardedWrite) HSet(ctx context.Context, key string, values ...any) *redis.IntCmd {
+	w.write("HSET", key, values...)
+	return redis.NewIntCmd(ctx)
+}
+
+func (w *guardedWrite) ZAdd(ctx context.Context, key string, members ...redis.Z) *redis.IntCmd {
+	args := make([]any, 0, 2*len(members))
+	for _, member := range members {
+		args = append(args, formatScore(member.Score), member.Member)
+	}
+
+	w.write("ZADD", key, args...)
+	return redis.NewIntCmd(ctx)
+}
+
+func (w *guardedWrite) XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd {
+	id := a.ID
+	if id == "" {
+		id = "*"
+	}
+
+	args := []any{id}
+	switch values := a.Values.(type) {
+	case map[string]any:
+		fields := make([]string, 0, len(values))
+		for field := range values {
+			fields = append(fields, field)
+		}
+		slices.Sort(fields)
+
+		for _, field := range fields {
+			args = append(args, field, values[field])
+		}
+	case []any:
+		args = append(args, values...)
+	}
+
+	w.write("XADD", a.Stream, args...)
+	return redis.NewStringCmd(ctx)
+}
+
+func (w *guardedWrite) Set(ctx context.Context, key string, value any, expiration time.Duration) *redis.StatusCmd {
+	args := []any{value}
+	switch {
+	case expiration == redis.KeepTTL:
+		args = append(args, "KEEPTTL")
+	case expiration > 0:
+		args = append(args, "PX", expiration.Milliseconds())
+	}
+
+	w.write("SET", key, args...)
+	return redis.NewStatusCmd(ctx)
+}
+
+func (w *guardedWrite) Del(ctx context.Context, key string) *redis.IntCmd {
+	w.write("DEL", key)
+	return redis.NewIntCmd(ctx)
+}
+
+// ZTrim keeps the maxLen highest members of the sorted set at key and removes the fields of the removed ones
+// from the hash at dataKey, which holds the payloads of the members.
+func (w *guardedWrite) ZTrim(key, dataKey string, maxLen int64) {
+	w.write("ZTRIM", key, w.keyIndex(dataKey), maxLen)
+}
+
+func (w *guardedWrite) write(command, key string, args ...any) {
+	w.writes = append(w.writes, len(args)+2, command, w.keyIndex(key))
+	w.writes = append(w.writes, args...)
+}
+
+// keyIndex returns the index of key in KEYS of the script.
+func (w *guardedWrite) keyIndex(key string) int {
+	idx := slices.Index(w.keys, key)
+	if idx < 0 {
+		w.keys = append(w.keys, key)
+		idx = len(w.keys) - 1
+	}
+
+	return idx + 1
+}
+
+// exec applies the writes, it returns redis.TxFailedErr like a failed WATCH if a guarded value changed.
+func (w *guardedWrite) exec(ctx context.Context, client redis.Scripter) error {
+	args := make([]any, 0, 1+len(w.guards)+len(w.writes))
+	args = append(args, w.nguards)
+	args = append(args, w.guards...)
+	args = append(args, w.writes...)
+
+	applied, err := guardedWriteScript.Run(ctx, client, w.keys, args...).Int()
+	if err != nil {
+		return err
+	}
+
+	if applied == 0 {
+		return redis.TxFailedErr
+	}
+
+	return nil
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
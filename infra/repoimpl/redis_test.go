@@ -0,0 +1,769 @@
+package repoimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/enum"
+	"github.com/me0den/example-service/domain/repo"
+	"github.com/me0den/example-service/infra/config"
+	xredis "github.com/me0den/example-service/x/redis"
+)
+
+func newTestRedisRepo(t *testing.T) (*RedisRepo, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewRedisRepo(client, &config.Config{}), server
+}
+
+// addEloFunc returns a repo.BattleFunc adding delta to the elo of every user.
+func addEloFunc(battleID string, delta int) repo.BattleFunc {
+	return func(userElos []*entity.UserElo) (*entity.Battle, []*entity.UserElo, error) {
+		battle := &entity.Battle{ID: battleID}
+		var newUserElos []*entity.UserElo
+		for _, userElo := range userElos {
+			newUserElo := userElo.Clone()
+			newUserElo.Elo += delta
+			newUserElos = append(newUserElos, newUserElo)
+			battle.Rewards = append(battle.Rewards, &entity.Reward{
+				UserID: userElo.UserID,
+				OldElo: userElo.Elo,
+				NewElo: newUserElo.Elo,
+			})
+		}
+
+		return battle, newUserElos, nil
+	}
+}
+
+func TestRedisRepo_GetUserElo(t *testing.T) {
+	ctx := context.Background()
+	r, server := newTestRedisRepo(t)
+	server.HSet(userEloKey(""), "legacy_user", `{"userID":"legacy_user","elo":1200}`)
+
+	userElo, err := r.GetUserElo(ctx, "unknown_user")
+	assert.NoError(t, err)
+	assert.Equal(t, entity.NewUserDefaultElo("unknown_user"), userElo)
+
+	userElo, err = r.GetUserElo(ctx, "legacy_user")
+	assert.NoError(t, err)
+	want := &entity.UserElo{
+		UserID:      "legacy_user",
+		Elo:         1200,
+		Deviation:   entity.DefaultDeviation,
+		Volatility:  entity.DefaultVolatility,
+		GamesPlayed: entity.UnknownGamesPlayed,
+	}
+	assert.Equal(t, want, userElo)
+
+	// The legacy entry is migrated in place.
+	stored := &entity.UserElo{}
+	require.NoError(t, json.Unmarshal([]byte(server.HGet(userEloKey(""), "legacy_user")), stored))
+	assert.Equal(t, want, stored)
+}
+
+func TestRedisRepo_ApplyBattle(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRedisRepo(t)
+
+	battle, err := r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"}, addEloFunc("battle_1", 10))
+	require.NoError(t, err)
+	assert.Equal(t, "battle_1", battle.ID)
+	assert.Len(t, battle.Rewards, 2)
+
+	userElo, err := r.GetUserElo(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo+10, userElo.Elo)
+
+	// A replayed battle returns the stored battle without calculating it again.
+	replayed, err := r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"},
+		func([]*entity.UserElo) (*entity.Battle, []*entity.UserElo, error) {
+			t.Fatal("battle must not be calculated again")
+			return nil, nil, nil
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, battle, replayed)
+
+	// A failed calculation stores nothing.
+	errCalculate := errors.New("calculate failed")
+	_, err = r.ApplyBattle(ctx, "battle_2", []string{"user_1", "user_2"},
+		func([]*entity.UserElo) (*entity.Battle, []*entity.UserElo, error) {
+			return nil, nil, errCalculate
+		},
+	)
+	assert.ErrorIs(t, err, errCalculate)
+	_, err = r.GetBattle(ctx, "battle_2")
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+}
+
+func TestRedisRepo_HashTag(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	cfg := &config.Config{}
+	cfg.Redis.Mode = xredis.ModeCluster
+	r := NewRedisRepo(client, cfg)
+
+	_, err := r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"}, addEloFunc("battle_1", 10))
+	require.NoError(t, err)
+
+	// Every key is under the default cluster hash tag, so the keys of a transaction are on one slot.
+	for _, key := range server.Keys() {
+		assert.True(t, strings.HasPrefix(key, "{rating}:"), key)
+	}
+	assert.True(t, server.Exists("{rating}:"+userEloKey("")))
+	assert.True(t, server.Exists("{rating}:"+battleKey("battle_1")))
+
+	userElo, err := r.GetUserElo(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo+10, userElo.Elo)
+}
+
+func TestRedisRepo_ApplyBattle_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRedisRepo(t)
+
+	const battles = 50
+	var wg sync.WaitGroup
+	var failed atomic.Int32
+	for i := range battles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			battleID := fmt.Sprintf("battle_%d", i)
+			opponentID := fmt.Sprintf("opponent_%d", i)
+			if _, err := r.ApplyBattle(ctx, battleID, []string{"user_1", opponentID}, addEloFunc(battleID, 1)); err != nil {
+				failed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Zero(t, failed.Load())
+	userElo, err := r.GetUserElo(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo+battles, userElo.Elo, "every concurrent battle must be applied exactly once")
+}
+
+func TestRedisRepo_ApplyBattle_Interleaved(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRedisRepo(t)
+
+	// A battle of other users stored while a battle is calculated does not conflict with it.
+	var calls int
+	_, err := r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"},
+		func(userElos []*entity.UserElo) (*entity.Battle, []*entity.UserElo, error) {
+			calls++
+			if calls == 1 {
+				_, err := r.ApplyBattle(ctx, "battle_2", []string{"user_3", "user_4"}, addEloFunc("battle_2", 5))
+				require.NoError(t, err)
+			}
+
+			return addEloFunc("battle_1", 10)(userElos)
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	// A battle of the same user stored meanwhile makes the battle calculated again on the new elo.
+	calls = 0
+	_, err = r.ApplyBattle(ctx, "battle_3", []string{"user_1", "user_3"},
+		func(userElos []*entity.UserElo) (*entity.Battle, []*entity.UserElo, error) {
+			calls++
+			if calls == 1 {
+				_, err := r.ApplyBattle(ctx, "battle_4", []string{"user_1", "user_4"}, addEloFunc("battle_4", 1))
+				require.NoError(t, err)
+			}
+
+			return addEloFunc("battle_3", 10)(userElos)
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	userElos, err := r.ListUserElos(ctx, []string{"user_1", "user_2", "user_3", "user_4"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo+21, userElos[0].Elo)
+	assert.Equal(t, entity.DefaultElo+10, userElos[1].Elo)
+	assert.Equal(t, entity.DefaultElo+15, userElos[2].Elo)
+	assert.Equal(t, entity.DefaultElo+6, userElos[3].Elo)
+}
+
+func TestRedisRepo_VoidBattle(t *testing.T) {
+	ctx := context.Background()
+	r, server := newTestRedisRepo(t)
+
+	_, err := r.VoidBattle(ctx, "battle_x", 100)
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+
+	_, err = r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"}, addEloFunc("battle_1", 10))
+	require.NoError(t, err)
+	_, err = r.ApplyBattle(ctx, "battle_2", []string{"user_1", "user_3"}, addEloFunc("battle_2", 5))
+	require.NoError(t, err)
+	server.SetTTL(battleKey("battle_1"), time.Hour)
+
+	battle, err := r.VoidBattle(ctx, "battle_1", 100)
+	require.NoError(t, err)
+	assert.True(t, battle.IsVoided())
+	assert.Equal(t, []*entity.Reward{
+		{
+			UserID:     "user_1",
+			OldElo:     entity.DefaultElo + 15,
+			NewElo:     entity.DefaultElo + 5,
+			Deviation:  entity.DefaultDeviation,
+			Volatility: entity.DefaultVolatility,
+			UpdatedAt:  100,
+		},
+		{
+			UserID:     "user_2",
+			OldElo:     entity.DefaultElo + 10,
+			NewElo:     entity.DefaultElo,
+			Deviation:  entity.DefaultDeviation,
+			Volatility: entity.DefaultVolatility,
+			UpdatedAt:  100,
+		},
+	}, battle.Reversals)
+
+	// Only the change of the voided battle is reversed.
+	userElos, err := r.ListUserElos(ctx, []string{"user_1", "user_2", "user_3"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo+5, userElos[0].Elo)
+	assert.Equal(t, entity.DefaultElo, userElos[1].Elo)
+	assert.Equal(t, entity.DefaultElo+5, userElos[2].Elo)
+
+	history, err := r.ListRatingHistory(ctx, "user_1", 0, 0, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, entity.HistoryReasonVoid, history[0].Reason)
+	assert.Equal(t, "battle_1", history[0].BattleID)
+
+	// The voided battle is stored within its retention window and cannot be voided again.
+	stored, err := r.GetBattle(ctx, "battle_1")
+	require.NoError(t, err)
+	assert.Equal(t, battle, stored)
+	assert.Equal(t, time.Hour, server.TTL(battleKey("battle_1")))
+
+	_, err = r.VoidBattle(ctx, "battle_1", 200)
+	assert.ErrorIs(t, err, repo.ErrVoided)
+}
+
+func TestRedisRepo_VoidBattle_EndedSeason(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRedisRepo(t)
+	r.season = "2026-09"
+	_, err := r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"}, addEloFunc("battle_1", 10))
+	require.NoError(t, err)
+	_, err = r.RolloverSeason(ctx, "2026-10", 100, func(userElo *entity.UserElo) *entity.UserElo { return userElo })
+	require.NoError(t, err)
+
+	// The battle is neither reversed in its ended season nor in the current one.
+	_, err = r.VoidBattle(ctx, "battle_1", 200)
+	assert.ErrorIs(t, err, repo.ErrSeasonEnded)
+
+	battle, err := r.GetBattle(ctx, "battle_1")
+	require.NoError(t, err)
+	assert.False(t, battle.IsVoided())
+	userElo, err := r.GetUserElo(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo+10, userElo.Elo)
+	userRank, err := r.GetSeasonUserRank(ctx, "2026-09", "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo+10, userRank.Elo)
+}
+
+func TestRedisRepo_ListBattleLog(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRedisRepo(t)
+
+	for _, battleID := range []string{"battle_1", "battle_2", "battle_3"} {
+		_, err := r.ApplyBattle(ctx, battleID, []string{"user_1", "user_2"}, addEloFunc(battleID, 10))
+		require.NoError(t, err)
+	}
+	// A replayed battle is not logged again.
+	_, err := r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"}, addEloFunc("battle_1", 10))
+	require.NoError(t, err)
+	_, err = r.VoidBattle(ctx, "battle_2", 100)
+	require.NoError(t, err)
+
+	entries, err := r.ListBattleLog(ctx, "", 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "battle_1", entries[0].BattleID)
+	assert.Equal(t, entity.BattleLogKindBattle, entries[0].Kind)
+
+	entries, err = r.ListBattleLog(ctx, entries[1].ID, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "battle_3", entries[0].BattleID)
+	assert.Equal(t, &entity.BattleLog{
+		ID:        entries[1].ID,
+		Kind:      entity.BattleLogKindVoid,
+		BattleID:  "battle_2",
+		CreatedAt: 100,
+	}, entries[1])
+}
+
+func TestRedisRepo_SwapReplay(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRedisRepo(t)
+
+	_, err := r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"}, addEloFunc("battle_1", 10))
+	require.NoError(t, err)
+	entries, err := r.ListBattleLog(ctx, "", 10)
+	require.NoError(t, err)
+	lastLogID := entries[0].ID
+
+	err = r.SwapReplay(ctx, "replay_1", "", lastLogID)
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+
+	replayed := []*entity.UserElo{
+		{UserID: "user_1", Elo: 1020, Deviation: entity.DefaultDeviation, Volatility: entity.DefaultVolatility, GamesPlayed: 1},
+		{UserID: "user_2", Elo: 980, Deviation: entity.DefaultDeviation, Volatility: entity.DefaultVolatility, GamesPlayed: 1},
+	}
+	require.NoError(t, r.StoreReplay(ctx, "replay_1", "", replayed))
+
+	// The replayed ratings stay out of the live ones until swapped.
+	userElos, err := r.ListUserElos(ctx, []string{"user_1", "user_2"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo+10, userElos[0].Elo)
+
+	err = r.SwapReplay(ctx, "replay_1", "season_2", lastLogID)
+	assert.ErrorIs(t, err, repo.ErrConflict)
+
+	require.NoError(t, r.SwapReplay(ctx, "replay_1", "", lastLogID))
+	userElos, err = r.ListUserElos(ctx, []string{"user_1", "user_2"})
+	require.NoError(t, err)
+	assert.Equal(t, replayed, userElos)
+	ranks, err := r.ListUserRanks(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.UserRank{
+		{UserID: "user_1", Elo: 1020, Rank: 1},
+		{UserID: "user_2", Elo: 980, Rank: 2},
+	}, ranks)
+
+	// A battle logged after the replay makes it outdated.
+	require.NoError(t, r.StoreReplay(ctx, "replay_2", "", replayed))
+	_, err = r.ApplyBattle(ctx, "battle_2", []string{"user_1", "user_2"}, addEloFunc("battle_2", 10))
+	require.NoError(t, err)
+	err = r.SwapReplay(ctx, "replay_2", "", lastLogID)
+	assert.ErrorIs(t, err, repo.ErrConflict)
+}
+
+func TestRedisRepo_MatchmakingQueue(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRedisRepo(t)
+
+	_, err := r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"}, addEloFunc("battle_1", 10))
+	require.NoError(t, err)
+
+	queued, err := r.JoinQueue(ctx, "user_1", 100, 0)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.QueuedUser{UserID: "user_1", Elo: entity.DefaultElo + 10, JoinedAt: 100}, queued)
+
+	// A queued user keeps waiting since they joined.
+	queued, err = r.JoinQueue(ctx, "user_1", 150, 50)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), queued.JoinedAt)
+
+	_, err = r.JoinQueue(ctx, "user_3", 120, 50)
+	require.NoError(t, err)
+	_, err = r.JoinQueue(ctx, "user_2", 200, 50)
+	require.NoError(t, err)
+
+	all, err := r.ListQueuedUsers(ctx, 50, entity.DefaultElo-100, entity.DefaultElo+100)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.QueuedUser{
+		{UserID: "user_1", Elo: entity.DefaultElo + 10, JoinedAt: 100},
+		{UserID: "user_3", Elo: entity.DefaultElo, JoinedAt: 120},
+		{UserID: "user_2", Elo: entity.DefaultElo + 10, JoinedAt: 200},
+	}, all)
+
+	queued, err = r.GetQueuedUser(ctx, "user_3", 50)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.QueuedUser{UserID: "user_3", Elo: entity.DefaultElo, JoinedAt: 120}, queued)
+	_, err = r.GetQueuedUser(ctx, "user_3", 130)
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+	_, err = r.GetQueuedUser(ctx, "user_4", 50)
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+
+	// The band is read from the leaderboard when it is smaller than the queue, the unrated users are out of it.
+	all, err = r.ListQueuedUsers(ctx, 50, entity.DefaultElo+5, entity.DefaultElo+100)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.QueuedUser{
+		{UserID: "user_1", Elo: entity.DefaultElo + 10, JoinedAt: 100},
+		{UserID: "user_2", Elo: entity.DefaultElo + 10, JoinedAt: 200},
+	}, all)
+	all, err = r.ListQueuedUsers(ctx, 50, entity.DefaultElo-100, entity.DefaultElo+5)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.QueuedUser{{UserID: "user_3", Elo: entity.DefaultElo, JoinedAt: 120}}, all)
+	all, err = r.ListQueuedUsers(ctx, 50, entity.DefaultElo+11, entity.DefaultElo+100)
+	require.NoError(t, err)
+	assert.Empty(t, all)
+
+	// The users who timed out leave the queue and join again from scratch.
+	all, err = r.ListQueuedUsers(ctx, 110, entity.DefaultElo-100, entity.DefaultElo+100)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+	queued, err = r.JoinQueue(ctx, "user_1", 300, 110)
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), queued.JoinedAt)
+
+	require.NoError(t, r.LeaveQueue(ctx, "user_3"))
+	assert.ErrorIs(t, r.LeaveQueue(ctx, "user_3"), repo.ErrNotFound)
+	all, err = r.ListQueuedUsers(ctx, 110, entity.DefaultElo-100, entity.DefaultElo+100)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.QueuedUser{
+		{UserID: "user_2", Elo: entity.DefaultElo + 10, JoinedAt: 200},
+		{UserID: "user_1", Elo: entity.DefaultElo + 10, JoinedAt: 300},
+	}, all)
+}
+
+func TestRedisRepo_Leaderboard(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRedisRepo(t)
+	require.NoError(t, r.BatchUpdateElo(ctx, []*entity.UserElo{
+		{UserID: "user_1", Elo: 1100},
+		{UserID: "user_2", Elo: 1300},
+		{UserID: "user_3", Elo: 1200},
+	}))
+
+	userRanks, err := r.ListUserRanks(ctx, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.UserRank{
+		{UserID: "user_2", Elo: 1300, Rank: 1},
+		{UserID: "user_3", Elo: 1200, Rank: 2},
+	}, userRanks)
+
+	userRanks, err = r.ListUserRanks(ctx, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.UserRank{{UserID: "user_1", Elo: 1100, Rank: 3}}, userRanks)
+
+	// Applied battles keep the leaderboard index up to date.
+	_, err = r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"}, addEloFunc("battle_1", 150))
+	require.NoError(t, err)
+
+	userRank, err := r.GetUserRank(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, &entity.UserRank{UserID: "user_1", Elo: 1250, Rank: 2}, userRank)
+
+	_, err = r.GetUserRank(ctx, "unknown_user")
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+}
+
+func TestRedisRepo_Init(t *testing.T) {
+	ctx := context.Background()
+	r, server := newTestRedisRepo(t)
+	server.HSet(userEloKey(""), "legacy_user", `{"userID":"legacy_user","elo":1200}`)
+	require.NoError(t, r.BatchUpdateElo(ctx, []*entity.UserElo{{UserID: "user_1", Elo: 1100}}))
+
+	_, err := r.GetUserRank(ctx, "legacy_user")
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+
+	// The elos stored before the leaderboard are indexed, the indexed ones are kept.
+	require.NoError(t, r.Init(ctx))
+	userRanks, err := r.ListUserRanks(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.UserRank{
+		{UserID: "legacy_user", Elo: 1200, Rank: 1},
+		{UserID: "user_1", Elo: 1100, Rank: 2},
+	}, userRanks)
+
+	require.NoError(t, r.Init(ctx))
+}
+
+func TestRedisRepo_ListRatingHistory(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRedisRepo(t)
+	for idx, createdAt := range []int64{100, 200, 300} {
+		battleID := fmt.Sprintf("battle_%d", idx+1)
+		_, err := r.ApplyBattle(ctx, battleID, []string{"user_1", "user_2"},
+			func(userElos []*entity.UserElo) (*entity.Battle, []*entity.UserElo, error) {
+				battle, newUserElos, err := addEloFunc(battleID, 10)(userElos)
+				for _, reward := range battle.Rewards {
+					reward.UpdatedAt = createdAt
+				}
+
+				return battle, newUserElos, err
+			},
+		)
+		require.NoError(t, err)
+	}
+
+	history, err := r.ListRatingHistory(ctx, "user_1", 0, 0, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	result := enum.BattleResultTie
+	assert.Equal(t, &entity.RatingHistory{
+		BattleID:  "battle_3",
+		UserID:    "user_1",
+		Reason:    entity.HistoryReasonBattle,
+		Result:    &result,
+		OldElo:    entity.DefaultElo + 20,
+		NewElo:    entity.DefaultElo + 30,
+		CreatedAt: 300,
+	}, history[0])
+
+	history, err = r.ListRatingHistory(ctx, "user_1", 150, 300, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "battle_2", history[0].BattleID)
+
+	history, err = r.ListRatingHistory(ctx, "user_3", 0, 0, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestRedisRepo_ListRatingHistory_Entries(t *testing.T) {
+	ctx := context.Background()
+	r, server := newTestRedisRepo(t)
+	r.historyMaxLen = 3
+	_, err := server.ZAdd(userEloHistoryKey("user_1"), 50,
+		`{"battleID":"legacy_battle","userID":"user_1","reason":"battle","oldElo":1000,"newElo":1010,"createdAt":50}`)
+	require.NoError(t, err)
+
+	// Battles with the same time and delta keep an entry each.
+	for _, battleID := range []string{"battle_1", "battle_2", "battle_3"} {
+		_, err := r.ApplyBattle(ctx, battleID, []string{"user_1"},
+			func(userElos []*entity.UserElo) (*entity.Battle, []*entity.UserElo, error) {
+				battle, newUserElos, err := addEloFunc(battleID, 0)(userElos)
+				battle.Rewards[0].UpdatedAt = 100
+				return battle, newUserElos, err
+			},
+		)
+		require.NoError(t, err)
+	}
+
+	// The history is trimmed to the latest entries, the legacy one included.
+	history, err := r.ListRatingHistory(ctx, "user_1", 0, 0, 0, 10)
+	require.NoError(t, err)
+	battleIDs := make([]string, 0, len(history))
+	for _, entry := range history {
+		battleIDs = append(battleIDs, entry.BattleID)
+	}
+	assert.ElementsMatch(t, []string{"battle_1", "battle_2", "battle_3"}, battleIDs)
+	members, err := server.ZMembers(userEloHistoryKey("user_1"))
+	require.NoError(t, err)
+	assert.Len(t, members, 3)
+	fields, err := server.HKeys(userEloHistoryDataKey("user_1"))
+	require.NoError(t, err)
+	assert.Len(t, fields, 3)
+}
+
+func TestRedisRepo_ListUserElos(t *testing.T) {
+	ctx := context.Background()
+	r, server := newTestRedisRepo(t)
+	server.HSet(userEloKey(""), "legacy_user", `{"userID":"legacy_user","elo":1200}`)
+	require.NoError(t, r.BatchUpdateElo(ctx, []*entity.UserElo{
+		{UserID: "user_1", Elo: 1100, Deviation: 80, Volatility: 0.05},
+	}))
+
+	userElos, err := r.ListUserElos(ctx, []string{"unknown_user", "user_1", "legacy_user"})
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.UserElo{
+		entity.NewUserDefaultElo("unknown_user"),
+		{UserID: "user_1", Elo: 1100, Deviation: 80, Volatility: 0.05},
+		{
+			UserID:      "legacy_user",
+			Elo:         1200,
+			Deviation:   entity.DefaultDeviation,
+			Volatility:  entity.DefaultVolatility,
+			GamesPlayed: entity.UnknownGamesPlayed,
+		},
+	}, userElos)
+}
+
+func TestRedisRepo_RolloverSeason(t *testing.T) {
+	ctx := context.Background()
+	r, server := newTestRedisRepo(t)
+
+	// The season stored before seasons has no id to address it once ended.
+	_, err := r.RolloverSeason(ctx, "2026-10", 100, nil)
+	assert.ErrorIs(t, err, repo.ErrUnnamedSeason)
+
+	r.season = "2026-09"
+	require.NoError(t, r.BatchUpdateElo(ctx, []*entity.UserElo{
+		{UserID: "user_1", Elo: 1400, Deviation: 50, Volatility: 0.06},
+		{UserID: "user_2", Elo: 900, Deviation: 50, Volatility: 0.06},
+	}))
+	_, err = server.ZAdd(userEloInactivityKey("2026-09"), 50, "user_1")
+	require.NoError(t, err)
+
+	resetFn := func(userElo *entity.UserElo) *entity.UserElo {
+		newUserElo := userElo.Clone()
+		newUserElo.Elo = (userElo.Elo + entity.DefaultElo) / 2
+		return newUserElo
+	}
+	ended, err := r.RolloverSeason(ctx, "2026-10", 100, resetFn)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.Season{ID: "2026-09", EndedAt: 100}, ended)
+
+	current, err := r.GetCurrentSeason(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.Season{ID: "2026-10"}, current)
+
+	// Elos and the leaderboard of the new season are soft reset.
+	userElo, err := r.GetUserElo(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, 1200, userElo.Elo)
+	userRanks, err := r.ListUserRanks(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.UserRank{
+		{UserID: "user_1", Elo: 1200, Rank: 1},
+		{UserID: "user_2", Elo: 950, Rank: 2},
+	}, userRanks)
+
+	// The inactivity of the users is carried over.
+	userIDs, err := r.ListInactiveUserIDs(ctx, 60, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_1"}, userIDs)
+
+	// Battles are applied to the new season only.
+	_, err = r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"}, addEloFunc("battle_1", 10))
+	require.NoError(t, err)
+	history, err := r.ListRatingHistory(ctx, "user_1", 0, 0, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "2026-10", history[0].Season)
+
+	// The final standings of the ended season are kept.
+	season, err := r.GetSeason(ctx, "2026-09")
+	require.NoError(t, err)
+	assert.Equal(t, ended, season)
+	userRank, err := r.GetSeasonUserRank(ctx, "2026-09", "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, &entity.UserRank{UserID: "user_1", Elo: 1400, Rank: 1}, userRank)
+
+	seasons, err := r.ListSeasons(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.Season{ended}, seasons)
+
+	// A season cannot be started twice.
+	_, err = r.RolloverSeason(ctx, "2026-10", 200, resetFn)
+	assert.ErrorIs(t, err, repo.ErrAlreadyExists)
+	_, err = r.RolloverSeason(ctx, "2026-09", 200, resetFn)
+	assert.ErrorIs(t, err, repo.ErrAlreadyExists)
+
+	_, err = r.GetSeason(ctx, "2026-08")
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+}
+
+func TestRedisRepo_RolloverSeason_ConcurrentBattles(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRedisRepo(t)
+	r.season = "2026-09"
+	require.NoError(t, r.BatchUpdateElo(ctx, []*entity.UserElo{
+		{UserID: "user_1", Elo: 1400},
+		{UserID: "user_2", Elo: 900},
+	}))
+
+	// A battle during the rollover neither makes it start over nor fails it.
+	var calls int
+	ended, err := r.RolloverSeason(ctx, "2026-10", 100, func(userElo *entity.UserElo) *entity.UserElo {
+		calls++
+		battleID := fmt.Sprintf("battle_%d", calls)
+		_, err := r.ApplyBattle(ctx, battleID, []string{"user_3"}, addEloFunc(battleID, 10))
+		require.NoError(t, err)
+
+		return userElo.Clone()
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &entity.Season{ID: "2026-09", EndedAt: 100}, ended)
+	assert.Equal(t, 2, calls)
+
+	userElos, err := r.ListUserElos(ctx, []string{"user_1", "user_2"})
+	require.NoError(t, err)
+	assert.Equal(t, 1400, userElos[0].Elo)
+	assert.Equal(t, 900, userElos[1].Elo)
+}
+
+func TestRedisRepo_ApplyDecay(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRedisRepo(t)
+	for idx, updatedAt := range []int64{100, 200} {
+		battleID := fmt.Sprintf("battle_%d", idx+1)
+		userID := fmt.Sprintf("user_%d", idx+1)
+		_, err := r.ApplyBattle(ctx, battleID, []string{userID},
+			func(userElos []*entity.UserElo) (*entity.Battle, []*entity.UserElo, error) {
+				battle, newUserElos, err := addEloFunc(battleID, 300)(userElos)
+				battle.Rewards[0].UpdatedAt = updatedAt
+				return battle, newUserElos, err
+			},
+		)
+		require.NoError(t, err)
+	}
+
+	userIDs, err := r.ListInactiveUserIDs(ctx, 150, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_1"}, userIDs)
+
+	decayFn := func(userElo *entity.UserElo) *entity.UserElo {
+		newUserElo := userElo.Clone()
+		newUserElo.Elo -= 10
+		return newUserElo
+	}
+	entry, err := r.ApplyDecay(ctx, "user_1", 150, 50, 1000, decayFn)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.RatingHistory{
+		UserID:    "user_1",
+		Reason:    entity.HistoryReasonDecay,
+		OldElo:    entity.DefaultElo + 300,
+		NewElo:    entity.DefaultElo + 290,
+		CreatedAt: 1000,
+	}, entry)
+
+	userElo, err := r.GetUserElo(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo+290, userElo.Elo)
+	userRank, err := r.GetUserRank(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.DefaultElo+290, userRank.Elo)
+	history, err := r.ListRatingHistory(ctx, "user_1", 0, 0, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, entry, history[0])
+
+	// The inactivity is counted one period later, so the user is inactive since 150.
+	userIDs, err = r.ListInactiveUserIDs(ctx, 149, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, userIDs)
+	userIDs, err = r.ListInactiveUserIDs(ctx, 200, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_1", "user_2"}, userIDs)
+
+	userIDs, err = r.ListInactiveUserIDs(ctx, 200, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_2"}, userIDs)
+
+	// An elo not decaying writes nothing, the user stays inactive.
+	entry, err = r.ApplyDecay(ctx, "user_2", 200, 50, 1000, func(*entity.UserElo) *entity.UserElo { return nil })
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+	userIDs, err = r.ListInactiveUserIDs(ctx, 200, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_1", "user_2"}, userIDs)
+	history, err = r.ListRatingHistory(ctx, "user_2", 0, 0, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	// An active user does not decay.
+	_, err = r.ApplyDecay(ctx, "user_2", 199, 50, 1000, decayFn)
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+	_, err = r.ApplyDecay(ctx, "unknown_user", 200, 50, 1000, decayFn)
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+}
@@ -0,0 +1,260 @@
+package repoimpl
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/repo"
+)
+
+// tracerName is the instrumentation scope of the spans of the repos.
+const tracerName = "github.com/me0den/example-service/infra/repoimpl"
+
+// TracedRepo is a repo.RatingRepo tracing every call to another repo.RatingRepo in a span named after the method.
+type TracedRepo struct {
+	ratingRepo repo.RatingRepo
+	tracer     trace.Tracer
+}
+
+// NewTracedRepo creates and returns a new instance of TracedRepo creating its spans with tp.
+func NewTracedRepo(ratingRepo repo.RatingRepo, tp trace.TracerProvider) *TracedRepo {
+	return &TracedRepo{
+		ratingRepo: ratingRepo,
+		tracer:     tp.Tracer(tracerName),
+	}
+}
+
+func (r *TracedRepo) GetUserElo(ctx context.Context, userID string) (*entity.UserElo, error) {
+	ctx, span := r.start(ctx, "GetUserElo", attribute.String("user.id", userID))
+	userElo, err := r.ratingRepo.GetUserElo(ctx, userID)
+	endSpan(span, err)
+
+	return userElo, err
+}
+
+func (r *TracedRepo) ListUserElos(ctx context.Context, userIDs []string) ([]*entity.UserElo, error) {
+	ctx, span := r.start(ctx, "ListUserElos", attribute.Int("user.count", len(userIDs)))
+	userElos, err := r.ratingRepo.ListUserElos(ctx, userIDs)
+	endSpan(span, err)
+
+	return userElos, err
+}
+
+func (r *TracedRepo) BatchUpdateElo(ctx context.Context, elos []*entity.UserElo) error {
+	ctx, span := r.start(ctx, "BatchUpdateElo", attribute.Int("user.count", len(elos)))
+	err := r.ratingRepo.BatchUpdateElo(ctx, elos)
+	endSpan(span, err)
+
+	return err
+}
+
+func (r *TracedRepo) GetBattle(ctx context.Context, battleID string) (*entity.Battle, error) {
+	ctx, span := r.start(ctx, "GetBattle", attribute.String("battle.id", battleID))
+	battle, err := r.ratingRepo.GetBattle(ctx, battleID)
+	endSpan(span, err)
+
+	return battle, err
+}
+
+func (r *TracedRepo) ApplyBattle(
+	ctx context.Context,
+	battleID string,
+	userIDs []string,
+	fn repo.BattleFunc,
+) (*entity.Battle, error) {
+	ctx, span := r.start(ctx, "ApplyBattle", attribute.String("battle.id", battleID), attribute.Int("user.count", len(userIDs)))
+	battle, err := r.ratingRepo.ApplyBattle(ctx, battleID, userIDs, fn)
+	endSpan(span, err)
+
+	return battle, err
+}
+
+func (r *TracedRepo) VoidBattle(ctx context.Context, battleID string, voidedAt int64) (*entity.Battle, error) {
+	ctx, span := r.start(ctx, "VoidBattle", attribute.String("battle.id", battleID))
+	battle, err := r.ratingRepo.VoidBattle(ctx, battleID, voidedAt)
+	endSpan(span, err)
+
+	return battle, err
+}
+
+func (r *TracedRepo) ListBattleLog(ctx context.Context, after string, limit int64) ([]*entity.BattleLog, error) {
+	ctx, span := r.start(ctx, "ListBattleLog")
+	entries, err := r.ratingRepo.ListBattleLog(ctx, after, limit)
+	endSpan(span, err)
+
+	return entries, err
+}
+
+func (r *TracedRepo) StoreReplay(ctx context.Context, namespace, season string, elos []*entity.UserElo) error {
+	ctx, span := r.start(ctx, "StoreReplay",
+		attribute.String("replay.namespace", namespace),
+		attribute.String("season.id", season),
+		attribute.Int("user.count", len(elos)),
+	)
+	err := r.ratingRepo.StoreReplay(ctx, namespace, season, elos)
+	endSpan(span, err)
+
+	return err
+}
+
+func (r *TracedRepo) SwapReplay(ctx context.Context, namespace, season, lastLogID string) error {
+	ctx, span := r.start(ctx, "SwapReplay", attribute.String("replay.namespace", namespace), attribute.String("season.id", season))
+	err := r.ratingRepo.SwapReplay(ctx, namespace, season, lastLogID)
+	endSpan(span, err)
+
+	return err
+}
+
+func (r *TracedRepo) JoinQueue(ctx context.Context, userID string, joinedAt, since int64) (*entity.QueuedUser, error) {
+	ctx, span := r.start(ctx, "JoinQueue", attribute.String("user.id", userID))
+	queued, err := r.ratingRepo.JoinQueue(ctx, userID, joinedAt, since)
+	endSpan(span, err)
+
+	return queued, err
+}
+
+func (r *TracedRepo) LeaveQueue(ctx context.Context, userID string) error {
+	ctx, span := r.start(ctx, "LeaveQueue", attribute.String("user.id", userID))
+	err := r.ratingRepo.LeaveQueue(ctx, userID)
+	endSpan(span, err)
+
+	return err
+}
+
+func (r *TracedRepo) GetQueuedUser(ctx context.Context, userID string, since int64) (*entity.QueuedUser, error) {
+	ctx, span := r.start(ctx, "GetQueuedUser", attribute.String("user.id", userID))
+	queued, err := r.ratingRepo.GetQueuedUser(ctx, userID, since)
+	endSpan(span, err)
+
+	return queued, err
+}
+
+func (r *TracedRepo) ListQueuedUsers(ctx context.Context, since int64, minElo, maxElo int) ([]*entity.QueuedUser, error) {
+	ctx, span := r.start(ctx, "ListQueuedUsers")
+	users, err := r.ratingRepo.ListQueuedUsers(ctx, since, minElo, maxElo)
+	endSpan(span, err)
+
+	return users, err
+}
+
+func (r *TracedRepo) ListUserRanks(ctx context.Context, offset, limit int64) ([]*entity.UserRank, error) {
+	ctx, span := r.start(ctx, "ListUserRanks")
+	userRanks, err := r.ratingRepo.ListUserRanks(ctx, offset, limit)
+	endSpan(span, err)
+
+	return userRanks, err
+}
+
+func (r *TracedRepo) GetUserRank(ctx context.Context, userID string) (*entity.UserRank, error) {
+	ctx, span := r.start(ctx, "GetUserRank", attribute.String("user.id", userID))
+	userRank, err := r.ratingRepo.GetUserRank(ctx, userID)
+	endSpan(span, err)
+
+	return userRank, err
+}
+
+func (r *TracedRepo) ListRatingHistory(
+	ctx context.Context,
+	userID string,
+	from, to int64,
+	offset, limit int64,
+) ([]*entity.RatingHistory, error) {
+	ctx, span := r.start(ctx, "ListRatingHistory", attribute.String("user.id", userID))
+	history, err := r.ratingRepo.ListRatingHistory(ctx, userID, from, to, offset, limit)
+	endSpan(span, err)
+
+	return history, err
+}
+
+func (r *TracedRepo) ListInactiveUserIDs(ctx context.Context, before, offset, limit int64) ([]string, error) {
+	ctx, span := r.start(ctx, "ListInactiveUserIDs")
+	userIDs, err := r.ratingRepo.ListInactiveUserIDs(ctx, before, offset, limit)
+	endSpan(span, err)
+
+	return userIDs, err
+}
+
+func (r *TracedRepo) ApplyDecay(
+	ctx context.Context,
+	userID string,
+	before, period, decayedAt int64,
+	fn repo.DecayFunc,
+) (*entity.RatingHistory, error) {
+	ctx, span := r.start(ctx, "ApplyDecay", attribute.String("user.id", userID))
+	entry, err := r.ratingRepo.ApplyDecay(ctx, userID, before, period, decayedAt, fn)
+	endSpan(span, err)
+
+	return entry, err
+}
+
+func (r *TracedRepo) GetCurrentSeason(ctx context.Context) (*entity.Season, error) {
+	ctx, span := r.start(ctx, "GetCurrentSeason")
+	season, err := r.ratingRepo.GetCurrentSeason(ctx)
+	endSpan(span, err)
+
+	return season, err
+}
+
+func (r *TracedRepo) GetSeason(ctx context.Context, seasonID string) (*entity.Season, error) {
+	ctx, span := r.start(ctx, "GetSeason", attribute.String("season.id", seasonID))
+	season, err := r.ratingRepo.GetSeason(ctx, seasonID)
+	endSpan(span, err)
+
+	return season, err
+}
+
+func (r *TracedRepo) ListSeasons(ctx context.Context) ([]*entity.Season, error) {
+	ctx, span := r.start(ctx, "ListSeasons")
+	seasons, err := r.ratingRepo.ListSeasons(ctx)
+	endSpan(span, err)
+
+	return seasons, err
+}
+
+func (r *TracedRepo) RolloverSeason(
+	ctx context.Context,
+	seasonID string,
+	endedAt int64,
+	fn repo.SeasonResetFunc,
+) (*entity.Season, error) {
+	ctx, span := r.start(ctx, "RolloverSeason", attribute.String("season.id", seasonID))
+	season, err := r.ratingRepo.RolloverSeason(ctx, seasonID, endedAt, fn)
+	endSpan(span, err)
+
+	return season, err
+}
+
+func (r *TracedRepo) ListSeasonUserRanks(ctx context.Context, seasonID string, offset, limit int64) ([]*entity.UserRank, error) {
+	ctx, span := r.start(ctx, "ListSeasonUserRanks", attribute.String("season.id", seasonID))
+	userRanks, err := r.ratingRepo.ListSeasonUserRanks(ctx, seasonID, offset, limit)
+	endSpan(span, err)
+
+	return userRanks, err
+}
+
+func (r *TracedRepo) GetSeasonUserRank(ctx context.Context, seasonID, userID string) (*entity.UserRank, error) {
+	ctx, span := r.start(ctx, "GetSeasonUserRank", attribute.String("season.id", seasonID), attribute.String("user.id", userID))
+	userRank, err := r.ratingRepo.GetSeasonUserRank(ctx, seasonID, userID)
+	endSpan(span, err)
+
+	return userRank, err
+}
+
+// start starts the client span of a method with attrs.
+func (r *TracedRepo) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return r.tracer.Start(ctx, "RatingRepo."+method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan ends span, recording err when the call failed.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
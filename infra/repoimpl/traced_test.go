@@ -0,0 +1,50 @@
+package repoimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/me0den/example-service/app/api/v1/v1impl/mock"
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/repo"
+)
+
+func TestTracedRepo(t *testing.T) {
+	ctx := context.Background()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	mockRepo := mock.NewRatingRepo(t)
+	r := NewTracedRepo(mockRepo, tp)
+
+	// The call reaches the underlying repo within its span.
+	mockRepo.On("ApplyBattle", tmock.Anything, "battle_1", []string{"user_1", "user_2"}, tmock.Anything).
+		Run(func(args tmock.Arguments) {
+			spanCtx := recorder.Started()[0].SpanContext()
+			assert.Equal(t, spanCtx.SpanID(), trace.SpanContextFromContext(args.Get(0).(context.Context)).SpanID())
+		}).
+		Return(&entity.Battle{ID: "battle_1"}, nil).Once()
+	_, err := r.ApplyBattle(ctx, "battle_1", []string{"user_1", "user_2"}, addEloFunc("battle_1", 10))
+	require.NoError(t, err)
+
+	mockRepo.On("GetUserRank", tmock.Anything, "user_1").Return(nil, repo.ErrNotFound).Once()
+	_, err = r.GetUserRank(ctx, "user_1")
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "RatingRepo.ApplyBattle", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("battle.id", "battle_1"))
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("user.count", 2))
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, "RatingRepo.GetUserRank", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/fx"
+
+	"github.com/me0den/example-service/infra/config"
+	mTracing "github.com/me0den/example-service/x/tracing"
+)
+
+// FXModule represents a FX module for the tracing.
+var FXModule = fx.Provide(
+	NewTracerProvider,
+)
+
+// NewTracerProvider creates the tracer provider of the configured exporter and sets it as the global one,
+// the pending spans are flushed on stop. It returns a no-op provider when the tracing is disabled.
+//
+// The W3C trace context and baggage are propagated either way, so the traces of the callers are not broken.
+func NewTracerProvider(lc fx.Lifecycle, cfg *config.Config, logger *slog.Logger) (trace.TracerProvider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !cfg.Tracing.Enabled {
+		return noop.NewTracerProvider(), nil
+	}
+
+	tp, err := mTracing.New(context.Background(), &cfg.Tracing, logger)
+	if err != nil {
+		return nil, fmt.Errorf("error when init tracer provider: %v", err)
+	}
+
+	otel.SetTracerProvider(tp)
+	lc.Append(fx.Hook{
+		OnStop: tp.Shutdown,
+	})
+
+	return tp, nil
+}
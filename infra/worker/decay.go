@@ -0,0 +1,143 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/me0den/example-service/domain/rating"
+	"github.com/me0den/example-service/domain/repo"
+	"github.com/me0den/example-service/infra/config"
+)
+
+// DecayFXModule represents a FX module for the decay of inactive users.
+var DecayFXModule = fx.Options(
+	fx.Invoke(
+		RegisterDecayWorker,
+	),
+)
+
+// DecayWorker periodically decays the elos of users inactive beyond the threshold.
+type DecayWorker struct {
+	ratingRepo repo.RatingRepo
+	decay      *rating.Decay
+	interval   time.Duration
+	threshold  time.Duration
+	period     time.Duration
+	batchSize  int64
+	logger     *slog.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDecayWorker creates and returns new instance of DecayWorker, non-positive options fall back to their default.
+func NewDecayWorker(ratingRepo repo.RatingRepo, cfg *rating.DecayConfig, logger *slog.Logger) *DecayWorker {
+	w := &DecayWorker{
+		ratingRepo: ratingRepo,
+		decay:      rating.NewDecay(cfg.Amount, cfg.Floor),
+		interval:   cfg.Interval,
+		threshold:  cfg.Threshold,
+		period:     cfg.Period,
+		batchSize:  cfg.BatchSize,
+		logger:     logger,
+	}
+	if w.interval <= 0 {
+		w.interval = rating.DefaultDecayInterval
+	}
+	if w.threshold <= 0 {
+		w.threshold = rating.DefaultDecayThreshold
+	}
+	if w.period <= 0 {
+		w.period = rating.DefaultDecayPeriod
+	}
+	if w.batchSize <= 0 {
+		w.batchSize = rating.DefaultDecayBatchSize
+	}
+
+	return w
+}
+
+// RegisterDecayWorker runs the DecayWorker within the application lifecycle if the decay is enabled.
+func RegisterDecayWorker(lc fx.Lifecycle, cfg *config.Config, ratingRepo repo.RatingRepo, logger *slog.Logger) {
+	if !cfg.Decay.Enabled {
+		return
+	}
+
+	w := NewDecayWorker(ratingRepo, &cfg.Decay, logger)
+	lc.Append(fx.Hook{
+		OnStart: w.Start,
+		OnStop:  w.Stop,
+	})
+}
+
+// Start runs the decay every interval in background until Stop.
+func (w *DecayWorker) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			if err := w.Decay(ctx, time.Now()); err != nil && !errors.Is(err, context.Canceled) {
+				w.logger.Error("failed to decay inactive users", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops the decay and waits for the running one to return.
+func (w *DecayWorker) Stop(ctx context.Context) error {
+	w.cancel()
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Decay decays every user inactive beyond the threshold at now,
+// a user inactive for several periods decays once per period.
+//
+// A user whose elo does not decay stays listed as inactive, the users skipped so far are paged over.
+func (w *DecayWorker) Decay(ctx context.Context, now time.Time) error {
+	before := now.Add(-w.threshold).Unix()
+	var skipped int64
+	for {
+		userIDs, err := w.ratingRepo.ListInactiveUserIDs(ctx, before, skipped, w.batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, userID := range userIDs {
+			entry, err := w.ratingRepo.ApplyDecay(ctx, userID, before, int64(w.period.Seconds()), now.Unix(), w.decay.Apply)
+			if err != nil && !errors.Is(err, repo.ErrNotFound) {
+				return err
+			}
+
+			if err == nil && entry == nil {
+				skipped++
+			}
+		}
+
+		if int64(len(userIDs)) < w.batchSize {
+			return nil
+		}
+	}
+}
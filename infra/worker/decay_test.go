@@ -0,0 +1,106 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+
+	"github.com/me0den/example-service/app/api/v1/v1impl/mock"
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/rating"
+	"github.com/me0den/example-service/domain/repo"
+)
+
+func TestDecayWorker_Decay(t *testing.T) {
+	now := time.Unix(100000, 0)
+	cfg := &rating.DecayConfig{
+		Threshold: 1000 * time.Second,
+		Period:    100 * time.Second,
+		Amount:    10,
+		Floor:     1200,
+		BatchSize: 2,
+	}
+	before := int64(99000)
+
+	tests := []struct {
+		name       string
+		wantErr    bool
+		setupMocks func(repo *mock.RatingRepo)
+	}{
+		{
+			name: "Decay every inactive user batch by batch",
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("ListInactiveUserIDs", tmock.Anything, before, int64(0), int64(2)).
+					Return([]string{"user_1", "user_2"}, nil).Once()
+				mockRepo.On("ListInactiveUserIDs", tmock.Anything, before, int64(0), int64(2)).
+					Return([]string{"user_3"}, nil).Once()
+				for _, userID := range []string{"user_1", "user_2"} {
+					mockRepo.On("ApplyDecay", tmock.Anything, userID, before, int64(100), now.Unix(), tmock.Anything).
+						Return(func(_ context.Context, userID string, _, _, _ int64, fn repo.DecayFunc) (*entity.RatingHistory, error) {
+							newUserElo := fn(&entity.UserElo{UserID: userID, Elo: 1500})
+							return &entity.RatingHistory{UserID: userID, OldElo: 1500, NewElo: newUserElo.Elo}, nil
+						}).Once()
+				}
+				// A user active again since listed is skipped.
+				mockRepo.On("ApplyDecay", tmock.Anything, "user_3", before, int64(100), now.Unix(), tmock.Anything).
+					Return(nil, repo.ErrNotFound).Once()
+			},
+		},
+		{
+			name: "Page over the users not decaying",
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("ListInactiveUserIDs", tmock.Anything, before, int64(0), int64(2)).
+					Return([]string{"user_1", "user_2"}, nil).Once()
+				mockRepo.On("ListInactiveUserIDs", tmock.Anything, before, int64(1), int64(2)).
+					Return([]string{}, nil).Once()
+				// A user at the floor does not decay and writes nothing.
+				mockRepo.On("ApplyDecay", tmock.Anything, "user_1", before, int64(100), now.Unix(), tmock.Anything).
+					Return(func(_ context.Context, userID string, _, _, _ int64, fn repo.DecayFunc) (*entity.RatingHistory, error) {
+						assert.Nil(t, fn(&entity.UserElo{UserID: userID, Elo: 1200}))
+						return nil, nil
+					}).Once()
+				mockRepo.On("ApplyDecay", tmock.Anything, "user_2", before, int64(100), now.Unix(), tmock.Anything).
+					Return(&entity.RatingHistory{UserID: "user_2", OldElo: 1500, NewElo: 1490}, nil).Once()
+			},
+		},
+		{
+			name:    "Failed to list inactive users",
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("ListInactiveUserIDs", tmock.Anything, before, int64(0), int64(2)).
+					Return(nil, errors.New("redis connection failed"))
+			},
+		},
+		{
+			name:    "Failed to decay a user",
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("ListInactiveUserIDs", tmock.Anything, before, int64(0), int64(2)).
+					Return([]string{"user_1"}, nil)
+				mockRepo.On("ApplyDecay", tmock.Anything, "user_1", before, int64(100), now.Unix(), tmock.Anything).
+					Return(nil, repo.ErrConflict)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratingRepo := &mock.RatingRepo{}
+			tt.setupMocks(ratingRepo)
+
+			err := NewDecayWorker(ratingRepo, cfg, slog.New(slog.DiscardHandler)).Decay(context.Background(), now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			ratingRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package worker
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/rating"
+	"github.com/me0den/example-service/domain/repo"
+)
+
+// DefaultReplayBatchSize is the number of entries of the battle log or of the leaderboard read at once.
+const DefaultReplayBatchSize = 1000
+
+// ReplayReport summarizes a replay of the battle log compared to the live ratings of the current season.
+type ReplayReport struct {
+	Namespace string
+	Season    string
+	// LastLogID is the id of the last entry of the battle log taken into account by the replay.
+	LastLogID string
+	Battles   int
+	Voided    int
+	Users     int
+	// Missing is the number of users ranked in the current season but absent from the battle log,
+	// swapping the replay in drops their ratings.
+	Missing int
+	// Changes holds the users whose replayed elo differs from the live one, the largest change first.
+	Changes []*ReplayChange
+}
+
+// ReplayChange is the difference between the live and the replayed elo of a user.
+type ReplayChange struct {
+	UserID      string
+	LiveElo     int
+	ReplayedElo int
+}
+
+// Delta returns the replayed elo change of the user.
+func (c *ReplayChange) Delta() int {
+	return c.ReplayedElo - c.LiveElo
+}
+
+// Replayer recomputes the ratings of the current season from the battle log into a replay namespace.
+type Replayer struct {
+	ratingRepo     repo.RatingRepo
+	teamCalculator *rating.TeamCalculator
+	softReset      *rating.SoftReset
+	batchSize      int64
+}
+
+// NewReplayer creates and returns new instance of Replayer.
+func NewReplayer(
+	ratingRepo repo.RatingRepo,
+	teamCalculator *rating.TeamCalculator,
+	softReset *rating.SoftReset,
+) *Replayer {
+	return &Replayer{
+		ratingRepo:     ratingRepo,
+		teamCalculator: teamCalculator,
+		softReset:      softReset,
+		batchSize:      DefaultReplayBatchSize,
+	}
+}
+
+// Replay rates every battle of the log again, stores the ratings of the current season in namespace and
+// compares them to the live ones.
+func (r *Replayer) Replay(ctx context.Context, namespace string) (*ReplayReport, error) {
+	season, err := r.ratingRepo.GetCurrentSeason(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReplayReport{Namespace: namespace, Season: season.ID}
+	// The void of a battle follows it in the log, so the voided battles are collected first.
+	// Entries appended after the first pass are left out of the replay.
+	replay := rating.NewReplay(r.teamCalculator, r.softReset)
+	if err := r.scanLog(ctx, "", func(entry *entity.BattleLog) {
+		if entry.Kind == entity.BattleLogKindVoid {
+			replay.Void(entry.BattleID)
+			report.Voided++
+		}
+		report.LastLogID = entry.ID
+	}); err != nil {
+		return nil, err
+	}
+
+	if report.LastLogID != "" {
+		if err := r.scanLog(ctx, report.LastLogID, func(entry *entity.BattleLog) {
+			if replay.Apply(entry) {
+				report.Battles++
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	replay.Rollover(season.ID)
+	userElos := replay.UserElos()
+	report.Users = len(userElos)
+	if err := r.ratingRepo.StoreReplay(ctx, namespace, season.ID, userElos); err != nil {
+		return nil, err
+	}
+
+	if err := r.compare(ctx, report, userElos); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// Swap atomically replaces the live ratings of the current season with the replayed ones of report.
+func (r *Replayer) Swap(ctx context.Context, report *ReplayReport) error {
+	return r.ratingRepo.SwapReplay(ctx, report.Namespace, report.Season, report.LastLogID)
+}
+
+// scanLog calls fn with every entry of the battle log in order up to the entry id until inclusive,
+// an empty until scans the whole log.
+func (r *Replayer) scanLog(ctx context.Context, until string, fn func(entry *entity.BattleLog)) error {
+	after := ""
+	for {
+		entries, err := r.ratingRepo.ListBattleLog(ctx, after, r.batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			fn(entry)
+			if entry.ID == until {
+				return nil
+			}
+			after = entry.ID
+		}
+
+		if int64(len(entries)) < r.batchSize {
+			return nil
+		}
+	}
+}
+
+// compare fills the changes and the missing users of report from the leaderboard of its season.
+func (r *Replayer) compare(ctx context.Context, report *ReplayReport, userElos []*entity.UserElo) error {
+	replayed := make(map[string]int, len(userElos))
+	for _, userElo := range userElos {
+		replayed[userElo.UserID] = userElo.Elo
+	}
+
+	for offset := int64(0); ; offset += r.batchSize {
+		ranks, err := r.ratingRepo.ListSeasonUserRanks(ctx, report.Season, offset, r.batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, rank := range ranks {
+			elo, ok := replayed[rank.UserID]
+			if !ok {
+				report.Missing++
+				continue
+			}
+
+			if elo != rank.Elo {
+				report.Changes = append(report.Changes, &ReplayChange{
+					UserID:      rank.UserID,
+					LiveElo:     rank.Elo,
+					ReplayedElo: elo,
+				})
+			}
+		}
+
+		if int64(len(ranks)) < r.batchSize {
+			break
+		}
+	}
+
+	slices.SortStableFunc(report.Changes, func(a, b *ReplayChange) int {
+		return cmp.Compare(abs(b.Delta()), abs(a.Delta()))
+	})
+
+	return nil
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
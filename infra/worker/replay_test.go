@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/me0den/example-service/app/api/v1/v1impl/mock"
+	"github.com/me0den/example-service/domain/entity"
+	"github.com/me0den/example-service/domain/rating"
+)
+
+func TestReplayer_Replay(t *testing.T) {
+	battleLog := []*entity.BattleLog{
+		{
+			ID:         "1-0",
+			Kind:       entity.BattleLogKindBattle,
+			BattleID:   "battle_1",
+			Season:     "s1",
+			Teams:      [][]string{{"user_1"}, {"user_2"}},
+			Placements: []int{1, 2},
+		},
+		{
+			ID:         "2-0",
+			Kind:       entity.BattleLogKindBattle,
+			BattleID:   "battle_2",
+			Season:     "s1",
+			Teams:      [][]string{{"user_1"}, {"user_3"}},
+			Placements: []int{1, 2},
+		},
+		{ID: "3-0", Kind: entity.BattleLogKindVoid, BattleID: "battle_2", Season: "s1"},
+	}
+	replayed := []*entity.UserElo{
+		{UserID: "user_1", Elo: 1010, Deviation: entity.DefaultDeviation, Volatility: entity.DefaultVolatility, GamesPlayed: 1},
+		{UserID: "user_2", Elo: 990, Deviation: entity.DefaultDeviation, Volatility: entity.DefaultVolatility, GamesPlayed: 1},
+	}
+
+	tests := []struct {
+		name       string
+		want       *ReplayReport
+		wantErr    bool
+		setupMocks func(repo *mock.RatingRepo)
+	}{
+		{
+			name: "Replay the battle log batch by batch",
+			want: &ReplayReport{
+				Namespace: "replay_1",
+				Season:    "s1",
+				LastLogID: "3-0",
+				Battles:   1,
+				Voided:    1,
+				Users:     2,
+				Missing:   1,
+				Changes: []*ReplayChange{
+					{UserID: "user_2", LiveElo: 970, ReplayedElo: 990},
+					{UserID: "user_1", LiveElo: 1020, ReplayedElo: 1010},
+				},
+			},
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetCurrentSeason", tmock.Anything).Return(&entity.Season{ID: "s1"}, nil)
+				mockRepo.On("ListBattleLog", tmock.Anything, "", int64(2)).Return(battleLog[:2], nil)
+				mockRepo.On("ListBattleLog", tmock.Anything, "2-0", int64(2)).Return(battleLog[2:], nil)
+				mockRepo.On("StoreReplay", tmock.Anything, "replay_1", "s1", replayed).Return(nil)
+				mockRepo.On("ListSeasonUserRanks", tmock.Anything, "s1", int64(0), int64(2)).
+					Return([]*entity.UserRank{
+						{UserID: "user_1", Elo: 1020, Rank: 1},
+						{UserID: "legacy_user", Elo: 1000, Rank: 2},
+					}, nil)
+				mockRepo.On("ListSeasonUserRanks", tmock.Anything, "s1", int64(2), int64(2)).
+					Return([]*entity.UserRank{{UserID: "user_2", Elo: 970, Rank: 3}}, nil)
+			},
+		},
+		{
+			name:    "Failed to list battle log",
+			wantErr: true,
+			setupMocks: func(mockRepo *mock.RatingRepo) {
+				mockRepo.On("GetCurrentSeason", tmock.Anything).Return(&entity.Season{ID: "s1"}, nil)
+				mockRepo.On("ListBattleLog", tmock.Anything, "", int64(2)).Return(nil, errors.New("redis connection failed"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratingRepo := &mock.RatingRepo{}
+			tt.setupMocks(ratingRepo)
+			r := NewReplayer(ratingRepo, rating.NewTeamCalculator(rating.NewElo(20), rating.AggregateAverage), rating.NewSoftReset(0.5))
+			r.batchSize = 2
+
+			got, err := r.Replay(context.Background(), "replay_1")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			ratingRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Config struct {
+	DSN         string        `mapstructure:"dsn"`
+	MaxConns    int32         `mapstructure:"max_conns"`
+	MinConns    int32         `mapstructure:"min_conns"`
+	DialTimeOut time.Duration `mapstructure:"dial_timeout"`
+	// Migrate applies the pending migrations on startup.
+	Migrate bool `mapstructure:"migrate"`
+}
+
+// New connects a pool to the Postgres server of cfg and pings it, the connection is logged with logger.
+func New(cfg *Config, logger *slog.Logger) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxConns > 0 {
+		poolCfg.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolCfg.MinConns = cfg.MinConns
+	}
+	if cfg.DialTimeOut > 0 {
+		poolCfg.ConnConfig.ConnectTimeout = cfg.DialTimeOut
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("pinging postgres server",
+		"host", poolCfg.ConnConfig.Host, "port", poolCfg.ConnConfig.Port, "database", poolCfg.ConnConfig.Database)
+
+	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	logger.Info("connected to postgres server")
+
+	return pool, nil
+}
@@ -0,0 +1,115 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// pipelineCommand is the command label of the pipelines and transactions.
+const pipelineCommand = "pipeline"
+
+// InstrumentMetrics registers the latency and the errors of the commands of client and the stats of its
+// connection pool in reg.
+func InstrumentMetrics(client redis.UniversalClient, reg prometheus.Registerer) error {
+	hook := &metricsHook{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "redis_command_duration_seconds",
+			Help:    "Latency of the Redis commands, a pipeline or transaction is a single command.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"command"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "redis_command_errors_total",
+			Help: "Failed Redis commands, a missing key is not a failure.",
+		}, []string{"command"}),
+	}
+
+	for _, collector := range []prometheus.Collector{hook.duration, hook.errors, &poolStatsCollector{client: client}} {
+		if err := reg.Register(collector); err != nil {
+			return err
+		}
+	}
+
+	client.AddHook(hook)
+	return nil
+}
+
+// metricsHook observes the latency and the errors of the commands.
+type metricsHook struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+func (h *metricsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *metricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.observe(cmd.Name(), start, err)
+
+		return err
+	}
+}
+
+func (h *metricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.observe(pipelineCommand, start, err)
+
+		return err
+	}
+}
+
+// observe records a command started at start failed with err.
+func (h *metricsHook) observe(command string, start time.Time, err error) {
+	h.duration.WithLabelValues(command).Observe(time.Since(start).Seconds())
+	if err != nil && !errors.Is(err, redis.Nil) && !errors.Is(err, redis.TxFailedErr) {
+		h.errors.WithLabelValues(command).Inc()
+	}
+}
+
+var (
+	poolHitsDesc = prometheus.NewDesc("redis_pool_hits_total",
+		"Times a free connection was found in the pool.", nil, nil)
+	poolMissesDesc = prometheus.NewDesc("redis_pool_misses_total",
+		"Times a free connection was not found in the pool.", nil, nil)
+	poolTimeoutsDesc = prometheus.NewDesc("redis_pool_timeouts_total",
+		"Times a wait for a connection timed out.", nil, nil)
+	poolStaleConnsDesc = prometheus.NewDesc("redis_pool_stale_connections_total",
+		"Stale connections removed from the pool.", nil, nil)
+	poolConnsDesc = prometheus.NewDesc("redis_pool_connections",
+		"Connections of the pool by state.", []string{"state"}, nil)
+)
+
+// poolStatsCollector collects the stats of the connection pool of a client when scraped.
+type poolStatsCollector struct {
+	client redis.UniversalClient
+}
+
+func (c *poolStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolHitsDesc
+	ch <- poolMissesDesc
+	ch <- poolTimeoutsDesc
+	ch <- poolStaleConnsDesc
+	ch <- poolConnsDesc
+}
+
+func (c *poolStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(poolHitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(poolMissesDesc, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(poolTimeoutsDesc, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(poolConnsDesc, prometheus.GaugeValue, float64(stats.IdleConns), "idle")
+	ch <- prometheus.MustNewConstMetric(poolConnsDesc, prometheus.GaugeValue, float64(stats.TotalConns-stats.IdleConns), "used")
+	ch <- prometheus.MustNewConstMetric(poolStaleConnsDesc, prometheus.CounterValue, float64(stats.StaleConns))
+}
@@ -5,14 +5,32 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// Modes of the Redis deployment.
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
 type Config struct {
+	// Mode is ModeStandalone, ModeSentinel or ModeCluster, the empty mode is standalone.
+	Mode string `mapstructure:"mode"`
+	// Addrs are the sentinel addresses in sentinel mode and the seed node addresses in cluster mode,
+	// Host and Port address the server in standalone mode.
+	Addrs []string `mapstructure:"addrs"`
+	// MasterName is the name of the master monitored by the sentinels.
+	MasterName       string `mapstructure:"master_name"`
+	SentinelPassword string `mapstructure:"sentinel_pass"`
+	// HashTag prefixes every key of the repo in braces, so the keys changed together stay on one cluster slot.
+	// Every key shares the tag, so the repo does not shard, and changing it requires renaming the stored keys.
+	HashTag   string `mapstructure:"hash_tag"`
 	TLSConfig *struct {
 		CertFilePath       string `mapstructure:"cert_file_path"`
 		InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
@@ -22,28 +40,32 @@ type Config struct {
 	Port         int           `mapstructure:"port"`
 	Database     int           `mapstructure:"database"`
 	TTL          time.Duration `mapstructure:"ttl"`
-	PoolSize     int           `mapstructure:"pool_size"`
-	MinIdleConns int           `mapstructure:"min_idle_conns"`
-	WriteTimeOut time.Duration `mapstructure:"write_timeout"`
-	ReadTimeOut  time.Duration `mapstructure:"read_timeout"`
-	DialTimeOut  time.Duration `mapstructure:"dial_timeout"`
+	TxMaxRetries int           `mapstructure:"tx_max_retries"`
+	// HistoryMaxLen is the number of the latest rating history entries kept per user.
+	HistoryMaxLen int64         `mapstructure:"history_max_len"`
+	PoolSize      int           `mapstructure:"pool_size"`
+	MinIdleConns  int           `mapstructure:"min_idle_conns"`
+	WriteTimeOut  time.Duration `mapstructure:"write_timeout"`
+	ReadTimeOut   time.Duration `mapstructure:"read_timeout"`
+	DialTimeOut   time.Duration `mapstructure:"dial_timeout"`
 }
 
 type Redis interface {
-	Client() *redis.Client
+	Client() redis.UniversalClient
 }
 
 type mRedis struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
-func New(cfg *Config) (Redis, error) {
+// New connects a client of the configured mode to Redis and pings it, the connection is logged with logger.
+func New(cfg *Config, logger *slog.Logger) (Redis, error) {
 	if cfg.MinIdleConns == 0 {
 		cfg.MinIdleConns = 10
 	}
 
-	redisOpt := &redis.Options{
-		Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+	redisOpt := &redis.UniversalOptions{
+		Addrs:        []string{fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)},
 		Password:     cfg.Password,
 		DB:           cfg.Database,
 		PoolSize:     cfg.PoolSize,
@@ -53,6 +75,28 @@ func New(cfg *Config) (Redis, error) {
 		DialTimeout:  cfg.DialTimeOut,
 	}
 
+	switch cfg.Mode {
+	case "", ModeStandalone:
+	case ModeSentinel:
+		if cfg.MasterName == "" || len(cfg.Addrs) == 0 {
+			return nil, fmt.Errorf("redis sentinel mode requires master_name and addrs")
+		}
+
+		redisOpt.Addrs = cfg.Addrs
+		redisOpt.MasterName = cfg.MasterName
+		redisOpt.SentinelPassword = cfg.SentinelPassword
+	case ModeCluster:
+		if len(cfg.Addrs) == 0 {
+			return nil, fmt.Errorf("redis cluster mode requires addrs")
+		}
+
+		// A cluster only has the database 0.
+		redisOpt.Addrs = cfg.Addrs
+		redisOpt.DB = 0
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q", cfg.Mode)
+	}
+
 	if cfg.TLSConfig != nil {
 		tlsConfig := &tls.Config{
 			InsecureSkipVerify: cfg.TLSConfig.InsecureSkipVerify,
@@ -72,20 +116,32 @@ func New(cfg *Config) (Redis, error) {
 	}
 
 	// Connect to redis server
-	client := redis.NewClient(redisOpt)
-	log.Println("Pinging to Redis Server: ", cfg.Host, cfg.Port, cfg.Database)
+	client := newClient(cfg.Mode, redisOpt)
+	logger.Info("pinging redis server", "mode", cfg.Mode, "addrs", redisOpt.Addrs, "master_name", redisOpt.MasterName, "db", redisOpt.DB)
 
 	if _, err := client.Ping(context.Background()).Result(); err != nil {
 		return nil, err
 	}
 
-	log.Println("Connected to Redis Server")
+	logger.Info("connected to redis server")
 
 	return &mRedis{
 		client: client,
 	}, nil
 }
 
-func (r *mRedis) Client() *redis.Client {
+func (r *mRedis) Client() redis.UniversalClient {
 	return r.client
 }
+
+// newClient returns the client of the configured mode rather than the one redis.NewUniversalClient guesses from opt.
+func newClient(mode string, opt *redis.UniversalOptions) redis.UniversalClient {
+	switch mode {
+	case ModeSentinel:
+		return redis.NewFailoverClient(opt.Failover())
+	case ModeCluster:
+		return redis.NewClusterClient(opt.Cluster())
+	default:
+		return redis.NewClient(opt.Simple())
+	}
+}